@@ -9,11 +9,13 @@ import (
 	"syscall"
 	"time"
 
+	"charitylens/internal/background"
 	"charitylens/internal/config"
 	"charitylens/internal/database"
 	"charitylens/internal/handlers"
 	"charitylens/internal/logger"
 	custommiddleware "charitylens/internal/middleware"
+	"charitylens/internal/scoring"
 	"charitylens/internal/sync"
 	"charitylens/internal/version"
 	"charitylens/web/static"
@@ -26,7 +28,7 @@ func main() {
 	// Command line flags
 	port := flag.String("port", "", "Port to bind to (overrides PORT env var)")
 	ip := flag.String("ip", "", "IP address to bind to (overrides IP env var)")
-	apiKey := flag.String("api-key", "", "Charity Commission API key (overrides CHARITY_API_KEY env var)")
+	apiKey := flag.String("api-key", "", "Charity Commission API key, or comma-separated keys (overrides CHARITY_API_KEY/CHARITY_API_KEYS env vars)")
 	debug := flag.Bool("debug", false, "Enable debug logging")
 	offline := flag.Bool("offline", false, "Run in offline mode (no API calls, uses pre-seeded database)")
 	flag.Parse()
@@ -40,6 +42,7 @@ func main() {
 	}
 	if *apiKey != "" {
 		os.Setenv("CHARITY_API_KEY", *apiKey)
+		os.Setenv("CHARITY_API_KEYS", *apiKey)
 	}
 	if *debug {
 		os.Setenv("DEBUG", "true")
@@ -51,7 +54,18 @@ func main() {
 	cfg := config.Load()
 
 	// Initialize logger
-	logger.WithDebug(cfg.Debug)
+	logLevel := cfg.LogLevel
+	if cfg.Debug {
+		logLevel = "debug"
+	}
+	if _, err := logger.Configure(cfg.LogFormat, logLevel); err != nil {
+		logger.Warn("Invalid logging configuration, using defaults", "error", err)
+	}
+
+	scoring.SetNeutrals(cfg.ScoreNeutrals)
+	background.SetLimit(cfg.MaxBackgroundTasks)
+	database.SetHiddenStatuses(cfg.HiddenStatuses)
+	database.SetConnectTimeout(cfg.DatabaseConnectTimeout)
 
 	// Log version info
 	logger.Info("Starting CharityLens", "version", version.GetVersion(), "user_agent", version.UserAgent())
@@ -62,10 +76,26 @@ func main() {
 	}
 
 	// Require API key only if not in offline mode
-	if !cfg.OfflineMode && cfg.CharityAPIKey == "" {
-		logger.Error("Charity Commission API key is required. Set CHARITY_API_KEY environment variable, use -api-key flag, or run with -offline flag.")
+	if !cfg.OfflineMode && len(cfg.CharityAPIKeys) == 0 {
+		logger.Error("Charity Commission API key is required. Set CHARITY_API_KEYS or CHARITY_API_KEY environment variable, use -api-key flag, or run with -offline flag.")
 		os.Exit(1)
 	}
+	if len(cfg.CharityAPIKeys) > 1 {
+		logger.Info("Using multiple API keys for load balancing", "keys", len(cfg.CharityAPIKeys))
+	}
+
+	// A separate offline database must be a readable SQLite file
+	if cfg.OfflineMode && cfg.OfflineDBPath != "" {
+		if cfg.DatabaseType != "sqlite" {
+			logger.Error("OFFLINE_DB_PATH is only supported with DATABASE_TYPE=sqlite", "database_type", cfg.DatabaseType)
+			os.Exit(1)
+		}
+		if err := database.CheckReadable(cfg.OfflineDBPath); err != nil {
+			logger.Error("Offline database is not readable. Check OFFLINE_DB_PATH points to a pre-seeded database file.", "path", cfg.OfflineDBPath, "error", err)
+			os.Exit(1)
+		}
+		logger.Info("Using offline database", "path", cfg.OfflineDBPath)
+	}
 
 	// Create router early for health checks
 	r := chi.NewRouter()
@@ -121,6 +151,12 @@ func main() {
 				logger.Error("Failed to run migrations", "error", err)
 				os.Exit(1)
 			}
+
+			// Restore the API requests already made this hour and day
+			if err := sync.InitAPIBudget(db, cfg.APIBudgetHourly, cfg.APIBudgetDaily); err != nil {
+				logger.Error("Failed to load API budget", "error", err)
+				os.Exit(1)
+			}
 		} else {
 			logger.Info("Skipping migrations (offline mode - using pre-seeded database)")
 		}
@@ -135,13 +171,26 @@ func main() {
 
 		logger.Info("Database ready")
 
+		// Search, detail and compare reads can go to a replica
+		readDB, err := database.InitReadDB(cfg.DatabaseReadURL)
+		if err != nil {
+			logger.Error("Failed to connect to read replica", "error", err)
+			os.Exit(1)
+		}
+		if readDB != nil {
+			logger.Info("Using read replica for search, detail and compare queries")
+		}
+		dbs := database.NewDB(db, readDB)
+
 		// Initialize handlers
-		charityHandler := handlers.NewCharityHandler(db, cfg)
-		webHandler := handlers.NewWebHandler(db, cfg)
+		charityHandler := handlers.NewCharityHandler(dbs, cfg)
+		webHandler := handlers.NewWebHandler(dbs, cfg)
+
+		// Static files (embedded, with cache headers)
+		r.Handle("/static/*", http.StripPrefix("/static/", static.Handler()))
 
-		// Static files (embedded)
-		staticFS := http.FS(static.FS())
-		r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(staticFS)))
+		// Data freshness check for monitoring scheduled re-imports
+		r.Get("/health/data", charityHandler.DataHealth)
 
 		// Web Routes
 		r.Get("/", webHandler.SearchPage)
@@ -150,18 +199,45 @@ func main() {
 		r.Get("/license", webHandler.LicensePage)
 		r.Get("/methodology", webHandler.MethodologyPage)
 
+		// Link preview images for the Open Graph tags on charity and error pages
+		r.Get("/og/score/{score}.png", webHandler.ScoreImage)
+		r.Get("/og/default.png", webHandler.DefaultImage)
+
 		// API Routes with CORS
 		r.Route("/api", func(r chi.Router) {
 			// Add CORS for API routes
 			r.Use(custommiddleware.CORS([]string{"*"})) // Allow all origins for API
 			r.Use(custommiddleware.Timeout(30 * time.Second))
+			r.NotFound(handlers.APINotFound)
+			r.MethodNotAllowed(handlers.APIMethodNotAllowed)
 
 			r.Get("/charities/search", charityHandler.SearchCharities)
+			r.Get("/charities/recent", charityHandler.GetRecentCharities)
+			r.Get("/charities/by-company/{companyNumber}", charityHandler.GetCharitiesByCompany)
 			r.Get("/charities/{number}", charityHandler.GetCharity)
+			r.Get("/charities/{number}/trustees", charityHandler.GetTrustees)
+			r.Get("/charities/{number}/activities", charityHandler.GetActivities)
+			r.Get("/charities/{number}/similar", charityHandler.GetSimilarCharities)
+			r.Get("/charities/{number}/raw", charityHandler.GetCharityRaw)
+			r.Get("/charities/{number}/report.json", charityHandler.GetCharityReport)
 			r.Get("/charities/compare", charityHandler.CompareCharities)
+			r.Get("/charities/compare.csv", charityHandler.CompareCharities)
+			r.Get("/categories", charityHandler.ListCategories)
+			r.Get("/stats", charityHandler.GetStats)
+			r.Get("/methodology", charityHandler.GetMethodology)
 			r.Post("/admin/sync", charityHandler.SyncData)
+			r.Get("/admin/keys", charityHandler.GetKeyStats)
+			r.Get("/admin/api-budget", charityHandler.GetAPIBudget)
+			r.Get("/admin/scoring-status", charityHandler.GetScoringStatus)
+			r.Get("/admin/background", charityHandler.GetBackgroundStats)
+			r.Post("/admin/rescore", charityHandler.Rescore)
+			r.Post("/admin/retry-failed-syncs", charityHandler.RetryFailedSyncs)
 		})
 
+		// The full export streams for far longer than the API request timeout, which
+		// would also buffer it, so it's registered outside the /api group
+		r.With(custommiddleware.CORS([]string{"*"})).Get("/api/export/full.csv.gz", charityHandler.ExportFullCSV)
+
 		// Start sync worker if enabled
 		if cfg.EnableSyncWorker {
 			logger.Info("Starting background sync worker")
@@ -185,6 +261,9 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Background syncs and score calculations are abandoned rather than waited for
+	handlers.StopBackgroundWork()
+
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Error("Server forced to shutdown", "error", err)
 		os.Exit(1)
@@ -3,21 +3,34 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"charitylens/internal/api"
+	appconfig "charitylens/internal/config"
 	"charitylens/internal/database"
+	"charitylens/internal/dates"
 	"charitylens/internal/downloader"
+	"charitylens/internal/httpclient"
 	"charitylens/internal/importer"
+	"charitylens/internal/models"
+	"charitylens/internal/scoring"
+	charitysync "charitylens/internal/sync"
+	"charitylens/internal/validation"
+	"charitylens/internal/webhook"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/schollz/progressbar/v3"
 )
@@ -26,26 +39,107 @@ const (
 	defaultRateLimit   = 10  // requests per second
 	defaultConcurrency = 5   // concurrent workers
 	defaultMaxRetries  = 5   // max retry attempts
-	checkpointInterval = 100 // Save progress every N charities
+	defaultRetryBudget = 120 // max retries per minute across all workers
+	defaultIdleConns   = 20  // idle connections kept per host
+
+	// defaultCheckpointInterval is how many charities the API mode scrapes between
+	// saving its progress
+	defaultCheckpointInterval = 100
+
+	// progressInterval is how often progress is logged when not drawing a progress bar
+	progressInterval = 1000
 )
 
+// modes are the valid values of -mode
+var modes = []string{"api", "file", "download", "score", "reindex", "retry-syncs", "migrate-status", "migrate-up", "migrate-down"}
+
 type Config struct {
-	Mode                    string   // "api" or "file"
+	Mode                    string   // One of modes
 	APIKeys                 []string // Multiple API keys for load balancing
 	CharityFile             string   // Path to charity JSON file (for file mode)
 	TrusteeFile             string   // Path to trustee JSON file (for file mode)
 	FinancialFile           string   // Path to annual return partb JSON file (for file mode)
+	PartAFile               string   // Path to annual return parta JSON file (for file mode)
 	AnnualReturnHistoryFile string   // Path to annual return history JSON file (for file mode)
+	ClassificationFile      string   // Path to classification JSON file (for file mode)
+	EventHistoryFile        string   // Path to event history JSON file (for file mode)
 	DBPath                  string
 	MigrationsPath          string
-	RateLimit               int
+	RateLimit               float64 // Requests per second, may be fractional
 	Concurrency             int
 	MaxRetries              int
+	RetryBudget             int
+	IdleConnsPerHost        int
+	ResponseTimeout         time.Duration
+	DownloadTimeout         time.Duration
+	UserAgent               string // User-Agent for data downloads (empty = version.UserAgent())
 	StartCharity            int
 	EndCharity              int
 	ResumeFrom              int
-	BatchSize               int // For file imports
+	CheckpointInterval      int                   // Save progress every N charities (API mode)
+	BatchSize               int                   // For file imports
+	Limit                   int                   // Max records per file (0 = unlimited)
+	StatsJSON               string                // Write final stats as JSON to this path ("-" for stdout)
+	Files                   []downloader.FileType // Files to fetch (for download mode)
+	ScoreSince              time.Time             // Only rescore charities updated since (for score mode)
 	Verbose                 bool
+
+	// ScoreDuringImport scores charities as their financials are imported rather
+	// than after the whole import (file and download modes)
+	ScoreDuringImport bool
+
+	// ValidateOnly checks the files are available without downloading or
+	// importing them (download mode)
+	ValidateOnly bool
+
+	// NoProgress logs progress as plain text instead of drawing a progress bar
+	// (API mode). It's set automatically when stdout isn't a terminal.
+	NoProgress bool
+
+	// WarmScoresFile lists the charities to score instead of every charity needing
+	// a score, one number per line (score mode)
+	WarmScoresFile string
+
+	// PostcodePrefixes and MinIncome limit the import to matching charities
+	// (file and download modes)
+	PostcodePrefixes []string
+	MinIncome        float64
+
+	// MaxExpenditureRatio rejects a year's financials spending more than this many
+	// times income (file and download modes, negative = never)
+	MaxExpenditureRatio float64
+
+	// AllFinancialPeriods imports every year in the annual return Part B file
+	// rather than only each charity's latest (file and download modes)
+	AllFinancialPeriods bool
+
+	// DownloadConcurrency limits how many files are downloaded, and so held in
+	// memory, at once (download mode, 0 = all)
+	DownloadConcurrency int
+
+	// MigrateSteps is how many migrations to roll back (migrate-down mode)
+	MigrateSteps int
+
+	// WebhookURL is POSTed a summary of the run when it finishes, signed with
+	// WebhookSecret if set (see the webhook package)
+	WebhookURL    string
+	WebhookSecret string
+
+	// report is the run's final statistics, included in the webhook summary
+	report *StatsReport
+}
+
+// transport returns HTTP transport settings sized for the configured concurrency
+func (c *Config) transport() httpclient.TransportConfig {
+	idle := c.IdleConnsPerHost
+	if idle < c.Concurrency {
+		idle = c.Concurrency
+	}
+	return httpclient.TransportConfig{
+		MaxIdleConns:          idle * 2,
+		MaxIdleConnsPerHost:   idle,
+		ResponseHeaderTimeout: c.ResponseTimeout,
+	}
 }
 
 type Scraper struct {
@@ -55,7 +149,8 @@ type Scraper struct {
 	stats       *Stats
 	ctx         context.Context
 	cancel      context.CancelFunc
-	progressBar *progressbar.ProgressBar
+	progressBar *progressbar.ProgressBar // nil when progress is logged as text
+	total       int
 }
 
 type Stats struct {
@@ -72,87 +167,267 @@ type Stats struct {
 func main() {
 	config := parseFlags()
 
-	if err := run(config); err != nil {
+	// Score with the same neutral values as the server
+	scoring.SetNeutrals(appconfig.LoadScoreNeutrals())
+
+	startTime := time.Now()
+	err := run(config)
+	notifyWebhook(config, startTime, err)
+	if err != nil {
 		log.Fatalf("Fatal error: %v", err)
 	}
 }
 
+// notifyWebhook sends the run's summary to -webhook-url, if set. A webhook that
+// can't be reached is logged but doesn't fail the run.
+func notifyWebhook(config *Config, startTime time.Time, runErr error) {
+	notifier := webhook.New(config.WebhookURL, config.WebhookSecret)
+	if notifier == nil {
+		return
+	}
+
+	finished := time.Now()
+	event := webhook.Event{
+		Event:           "seed",
+		Mode:            config.Mode,
+		Success:         runErr == nil,
+		StartedAt:       startTime,
+		FinishedAt:      finished,
+		DurationSeconds: finished.Sub(startTime).Seconds(),
+	}
+	if runErr != nil {
+		event.Error = runErr.Error()
+	}
+	if config.report != nil {
+		event.Stats = config.report
+	}
+
+	if err := notifier.Notify(context.Background(), event); err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	log.Printf("Sent run summary to webhook")
+}
+
 func parseFlags() *Config {
 	config := &Config{}
 
 	var apiKeysStr string
-	flag.StringVar(&config.Mode, "mode", "api", "Import mode: 'api' (scrape from API), 'file' (import from JSON files), 'download' (download and import in-memory), or 'score' (calculate scores for existing charities)")
+	var filesStr string
+	var postcodesStr string
+	var sinceStr string
+	flag.StringVar(&config.Mode, "mode", "api", "Import mode: 'api' (scrape from API), 'file' (import from JSON files), 'download' (download and import in-memory), 'score' (calculate scores for existing charities), 'reindex' (rebuild derived data and indexes), 'retry-syncs' (retry the server's failed background syncs), 'migrate-status' (report the schema version and pending migrations), 'migrate-up' (apply pending migrations), or 'migrate-down N' (roll back the last N migrations)")
 	flag.StringVar(&apiKeysStr, "api-keys", os.Getenv("CHARITY_API_KEYS"), "Comma-separated list of API keys for load balancing (or set CHARITY_API_KEYS env var)")
-	flag.StringVar(&config.CharityFile, "charity-file", "publicextract.charity.json", "Path to charity JSON file (file mode only)")
-	flag.StringVar(&config.TrusteeFile, "trustee-file", "publicextract.charity_trustee.json", "Path to trustee JSON file (file mode only)")
-	flag.StringVar(&config.FinancialFile, "financial-file", "publicextract.charity_annual_return_partb.json", "Path to annual return partb JSON file (file mode only)")
-	flag.StringVar(&config.AnnualReturnHistoryFile, "history-file", "publicextract.charity_annual_return_history.json", "Path to annual return history JSON file (file mode only)")
+	flag.StringVar(&config.CharityFile, "charity-file", "publicextract.charity.json", "Path to charity JSON file, a directory or glob of chunk files, or an http(s) URL of one (optionally .zip or .gz) (file mode only)")
+	flag.StringVar(&config.TrusteeFile, "trustee-file", "publicextract.charity_trustee.json", "Path to trustee JSON file, a directory or glob of chunk files, or an http(s) URL of one (optionally .zip or .gz) (file mode only)")
+	flag.StringVar(&config.FinancialFile, "financial-file", "publicextract.charity_annual_return_partb.json", "Path to annual return partb JSON file, a directory or glob of chunk files, or an http(s) URL of one (optionally .zip or .gz) (file mode only)")
+	flag.StringVar(&config.PartAFile, "parta-file", "publicextract.charity_annual_return_parta.json", "Path to annual return parta JSON file, a directory or glob of chunk files, or an http(s) URL of one (optionally .zip or .gz), for trading subsidiaries in efficiency scoring (file mode only)")
+	flag.StringVar(&config.AnnualReturnHistoryFile, "history-file", "publicextract.charity_annual_return_history.json", "Path to annual return history JSON file, a directory or glob of chunk files, or an http(s) URL of one (optionally .zip or .gz) (file mode only)")
+	flag.StringVar(&config.ClassificationFile, "classification-file", "publicextract.charity_classification.json", "Path to charity classification JSON file, a directory or glob of chunk files, or an http(s) URL of one (optionally .zip or .gz) (file mode only)")
+	flag.StringVar(&config.EventHistoryFile, "event-history-file", "publicextract.charity_event_history.json", "Path to charity event history JSON file, a directory or glob of chunk files, or an http(s) URL of one (optionally .zip or .gz), for removal reasons (file mode only)")
+	flag.StringVar(&sinceStr, "since", "", "Only rescore charities updated within this duration (e.g. 72h, 7d) or since this date (e.g. 2025-01-31), including those whose score predates their data (score mode only)")
+	flag.StringVar(&filesStr, "files", "", "Comma-separated list of files to download, e.g. 'charity,charity_annual_return_partb' (download mode only, default: all)")
 	flag.StringVar(&config.DBPath, "db", "seed.db", "Path to SQLite database file")
 	flag.StringVar(&config.MigrationsPath, "migrations", "../../migrations", "Path to migrations directory")
-	flag.IntVar(&config.RateLimit, "rate-limit", defaultRateLimit, "Maximum requests per second (API mode only)")
+	flag.Float64Var(&config.RateLimit, "rate-limit", defaultRateLimit, "Maximum requests per second, may be fractional e.g. 0.5 (API and retry-syncs modes)")
 	flag.IntVar(&config.Concurrency, "concurrency", defaultConcurrency, "Number of concurrent workers (API mode only)")
 	flag.IntVar(&config.MaxRetries, "max-retries", defaultMaxRetries, "Maximum retry attempts for failed requests (API mode only)")
+	flag.IntVar(&config.RetryBudget, "retry-budget", defaultRetryBudget, "Maximum retries per minute across all workers, -1 for unlimited (API mode only)")
+	flag.IntVar(&config.IdleConnsPerHost, "idle-conns", defaultIdleConns, "Idle HTTP connections kept per host; raised to -concurrency if lower")
+	flag.DurationVar(&config.ResponseTimeout, "response-timeout", 30*time.Second, "Time to wait for response headers before treating a connection as stalled")
+	flag.DurationVar(&config.DownloadTimeout, "download-timeout", 15*time.Minute, "Overall timeout for each file download (download mode, and file mode URLs)")
+	flag.IntVar(&config.DownloadConcurrency, "download-concurrency", 0, "Download at most this many files at once; each is imported and released as soon as it's ready, so fewer lowers peak memory (download mode only, 0 = all at once)")
+	flag.StringVar(&config.UserAgent, "user-agent", os.Getenv("DOWNLOAD_USER_AGENT"), "User-Agent sent when downloading data files, defaults to the CharityLens crawler string with contact details (or set DOWNLOAD_USER_AGENT env var)")
 	flag.IntVar(&config.StartCharity, "start", 1, "Starting charity number (API mode only)")
 	flag.IntVar(&config.EndCharity, "end", 999999, "Ending charity number (API mode only)")
 	flag.IntVar(&config.ResumeFrom, "resume", 0, "Resume from specific charity number (API mode only, overrides checkpoint)")
+	flag.IntVar(&config.CheckpointInterval, "checkpoint-interval", defaultCheckpointInterval, "Save progress every N charities, lower to lose less on a crash or higher to write less (API mode only)")
 	flag.IntVar(&config.BatchSize, "batch-size", 1000, "Batch size for file imports (file mode only)")
+	flag.IntVar(&config.Limit, "limit", 0, "Maximum records to import from each file, for sampling (file and download modes), or failed syncs to retry (retry-syncs mode) (0 = unlimited)")
+	flag.StringVar(&config.StatsJSON, "stats-json", "", "Write final statistics as JSON to this file, or '-' for stdout")
+	flag.StringVar(&config.WebhookURL, "webhook-url", os.Getenv("WEBHOOK_URL"), "POST a JSON summary of the run to this URL when it finishes, signed with WEBHOOK_SECRET if set (or set WEBHOOK_URL env var)")
 	flag.BoolVar(&config.Verbose, "verbose", false, "Enable verbose logging")
+	flag.BoolVar(&config.ValidateOnly, "validate-only", false, "Check each file can be downloaded and report its size and last-modified date, without downloading or importing (download mode only)")
+	flag.BoolVar(&config.NoProgress, "no-progress", false, "Log progress as plain text instead of drawing a progress bar, e.g. in CI logs; automatic when stdout isn't a terminal (API mode only)")
+	flag.StringVar(&config.WarmScoresFile, "warm-scores", "", "Only score the charities listed in this file, one number per line, e.g. the most viewed after a fresh import (score mode only)")
+	flag.BoolVar(&config.ScoreDuringImport, "score-during-import", false, "Score each charity as soon as its financials are imported, overlapping scoring with the import (file and download modes)")
+	flag.StringVar(&postcodesStr, "postcode-prefixes", "", "Comma-separated postcode prefixes, e.g. 'BS,BA1'; only import charities whose postcode starts with one (file and download modes)")
+	flag.Float64Var(&config.MinIncome, "min-income", 0, "Only import charities whose latest income is at least this many pounds (file and download modes)")
+	flag.Float64Var(&config.MaxExpenditureRatio, "max-expenditure-ratio", 100, "Reject a year's financial data spending more than this many times its income, -1 to accept any (file and download modes)")
+	flag.BoolVar(&config.AllFinancialPeriods, "all-financial-periods", false, "Import every financial year in the annual return Part B file, not just each charity's latest (file and download modes)")
 
 	flag.Parse()
 
-	// Validate mode
-	if config.Mode != "api" && config.Mode != "file" && config.Mode != "download" && config.Mode != "score" {
-		log.Fatalf("Invalid mode: %s (must be 'api', 'file', 'download', or 'score')", config.Mode)
-	}
+	// The secret is only read from the environment, so it isn't visible in the
+	// process list
+	config.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
 
-	// Mode-specific validation
-	if config.Mode == "api" {
-		// Parse API keys (comma-separated)
-		if apiKeysStr != "" {
-			keys := strings.Split(apiKeysStr, ",")
-			for _, key := range keys {
-				trimmed := strings.TrimSpace(key)
-				if trimmed != "" {
-					config.APIKeys = append(config.APIKeys, trimmed)
-				}
-			}
+	for _, prefix := range strings.Split(postcodesStr, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			config.PostcodePrefixes = append(config.PostcodePrefixes, prefix)
 		}
+	}
 
-		// Also check for single key in CHARITY_API_KEY for backwards compatibility
-		if len(config.APIKeys) == 0 {
-			singleKey := os.Getenv("CHARITY_API_KEY")
-			if singleKey != "" {
-				config.APIKeys = []string{singleKey}
-			}
-		}
+	// A progress bar's control characters garble logs that aren't a terminal
+	if !isTerminal(os.Stdout) {
+		config.NoProgress = true
+	}
+
+	// Validate mode
+	if !slices.Contains(modes, config.Mode) {
+		log.Fatalf("Invalid mode: %s (must be 'api', 'file', 'download', 'score', 'reindex', 'retry-syncs', 'migrate-status', 'migrate-up', or 'migrate-down')", config.Mode)
+	}
 
+	// Both modes that call the API need keys and a rate limit
+	if config.Mode == "api" || config.Mode == "retry-syncs" {
+		config.APIKeys = parseAPIKeys(apiKeysStr)
 		if len(config.APIKeys) == 0 {
-			log.Fatal("At least one API key is required for API mode. Set via -api-keys flag, CHARITY_API_KEYS, or CHARITY_API_KEY environment variable")
+			log.Fatalf("At least one API key is required for %s mode. Set via -api-keys flag, CHARITY_API_KEYS, or CHARITY_API_KEY environment variable", config.Mode)
 		}
 
 		if len(config.APIKeys) > 1 {
 			log.Printf("Using %d API keys for load balancing", len(config.APIKeys))
 		}
+
+		if config.RateLimit <= 0 {
+			log.Fatalf("Invalid -rate-limit: %g (must be greater than 0)", config.RateLimit)
+		}
+	}
+
+	// Mode-specific validation
+	if config.Mode == "api" {
+		// Validate the charity number range
+		if err := validation.ValidateCharityNumber(config.StartCharity); err != nil {
+			log.Fatalf("Invalid -start: %v", err)
+		}
+		if err := validation.ValidateCharityNumber(config.EndCharity); err != nil {
+			log.Fatalf("Invalid -end: %v", err)
+		}
+		if config.StartCharity > config.EndCharity {
+			log.Fatalf("Invalid range: -start (%d) is greater than -end (%d)", config.StartCharity, config.EndCharity)
+		}
+		if config.ResumeFrom != 0 {
+			if err := validation.ValidateCharityNumber(config.ResumeFrom); err != nil {
+				log.Fatalf("Invalid -resume: %v", err)
+			}
+		}
+		if config.CheckpointInterval <= 0 {
+			log.Fatalf("Invalid -checkpoint-interval: %d (must be greater than 0)", config.CheckpointInterval)
+		}
 	} else if config.Mode == "file" {
 		// Validate file paths (all three required for complete data)
-		if _, err := os.Stat(config.CharityFile); os.IsNotExist(err) {
+		if err := checkInput(config.CharityFile); err != nil {
 			log.Fatalf("Charity file not found: %s", config.CharityFile)
 		}
-		if _, err := os.Stat(config.TrusteeFile); os.IsNotExist(err) {
+		if err := checkInput(config.TrusteeFile); err != nil {
 			log.Fatalf("Trustee file not found: %s", config.TrusteeFile)
 		}
 		// Financial file is optional but recommended
-		if _, err := os.Stat(config.FinancialFile); os.IsNotExist(err) {
+		if err := checkInput(config.FinancialFile); err != nil {
 			log.Printf("Warning: Financial file not found: %s (detailed financial data will not be available for scoring)", config.FinancialFile)
 			config.FinancialFile = "" // Clear it so importer knows to skip
 		}
+		// Part A file is optional (trading subsidiaries in efficiency scoring)
+		if err := checkInput(config.PartAFile); err != nil {
+			log.Printf("Warning: Annual return Part A file not found: %s (efficiency scoring won't allow for trading subsidiaries)", config.PartAFile)
+			config.PartAFile = ""
+		}
+		// Annual return history file is optional (filing timeliness for scoring)
+		if err := checkInput(config.AnnualReturnHistoryFile); err != nil {
+			log.Printf("Warning: Annual return history file not found: %s (scoring will have limited transparency metrics)", config.AnnualReturnHistoryFile)
+			config.AnnualReturnHistoryFile = ""
+		}
+		// Classification file is optional (enables browsing by category)
+		if err := checkInput(config.ClassificationFile); err != nil {
+			log.Printf("Warning: Classification file not found: %s (category browsing will not be available)", config.ClassificationFile)
+			config.ClassificationFile = ""
+		}
+		// Event history file is optional (records why charities were removed)
+		if err := checkInput(config.EventHistoryFile); err != nil {
+			log.Printf("Warning: Event history file not found: %s (removal reasons will not be available)", config.EventHistoryFile)
+			config.EventHistoryFile = ""
+		}
 		log.Printf("File mode: importing from charity, trustee, and financial files")
+	} else if config.Mode == "download" {
+		files, err := downloader.ParseFileTypes(filesStr)
+		if err != nil {
+			log.Fatalf("Invalid -files value: %v", err)
+		}
+		config.Files = files
+		if config.DownloadConcurrency < 0 {
+			log.Fatalf("Invalid -download-concurrency: %d (must be 0 or more)", config.DownloadConcurrency)
+		}
+	} else if config.Mode == "migrate-down" {
+		// The number of migrations is required, so a slip can't roll back more
+		// than intended
+		steps, err := strconv.Atoi(flag.Arg(0))
+		if flag.NArg() != 1 || err != nil || steps <= 0 {
+			log.Fatalf("migrate-down mode needs the number of migrations to roll back after the flags, e.g. -mode migrate-down 1")
+		}
+		config.MigrateSteps = steps
+	} else if config.Mode == "score" && sinceStr != "" {
+		since, err := parseSince(sinceStr, time.Now())
+		if err != nil {
+			log.Fatalf("Invalid -since: %v", err)
+		}
+		config.ScoreSince = since
 	}
 
 	return config
 }
 
+// parseAPIKeys parses the comma-separated -api-keys value, falling back to a single
+// key in CHARITY_API_KEY for backwards compatibility
+func parseAPIKeys(apiKeysStr string) []string {
+	var keys []string
+	for _, key := range strings.Split(apiKeysStr, ",") {
+		if trimmed := strings.TrimSpace(key); trimmed != "" {
+			keys = append(keys, trimmed)
+		}
+	}
+
+	if len(keys) == 0 {
+		if singleKey := os.Getenv("CHARITY_API_KEY"); singleKey != "" {
+			keys = []string{singleKey}
+		}
+	}
+	return keys
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// parseSince parses the -since flag: a duration before now (Go syntax, or whole
+// days such as "7d") or a date
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	if t := dates.Parse(value); !t.IsZero() {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration (e.g. 72h, 7d) or date (e.g. 2025-01-31)", value)
+}
+
 func run(config *Config) error {
+	// A pre-flight check doesn't touch the database
+	if config.Mode == "download" && config.ValidateOnly {
+		return runValidateDownloads(config)
+	}
+
+	// Migration modes manage the schema themselves
+	if strings.HasPrefix(config.Mode, "migrate-") {
+		return runMigrate(config)
+	}
+
 	// Initialize database
 	db, err := initDatabase(config.DBPath, config.MigrationsPath)
 	if err != nil {
@@ -167,26 +442,188 @@ func run(config *Config) error {
 		return runDownloadImport(config, db)
 	} else if config.Mode == "score" {
 		return runScoreCalculation(config, db)
+	} else if config.Mode == "reindex" {
+		return runReindex(config, db)
+	} else if config.Mode == "retry-syncs" {
+		return runRetrySyncs(config, db)
 	}
 	return runAPIScrape(config, db)
 }
 
+// runMigrate reports the schema version and pending migrations, or applies or
+// rolls back migrations, leaving the data alone. The server migrates on start,
+// so this is for checking or changing the schema explicitly, e.g. before a
+// deploy.
+func runMigrate(config *Config) error {
+	if _, err := os.Stat(config.MigrationsPath); os.IsNotExist(err) {
+		return fmt.Errorf("migrations directory not found: %s", config.MigrationsPath)
+	}
+
+	// migrate-up may create the database, as the import modes do
+	if config.Mode == "migrate-up" {
+		log.Println("=== Migrate Up Mode ===")
+		db, err := initDatabase(config.DBPath, config.MigrationsPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return reportMigrationStatus(db, config.MigrationsPath)
+	}
+
+	db, err := openDatabase(config.DBPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if config.Mode == "migrate-down" {
+		log.Println("=== Migrate Down Mode ===")
+		log.Printf("Rolling back %d migration(s)...", config.MigrateSteps)
+		if err := database.MigrateDownWithPath(db, config.MigrationsPath, config.MigrateSteps); err != nil {
+			return err
+		}
+	} else {
+		log.Println("=== Migrate Status Mode ===")
+	}
+	return reportMigrationStatus(db, config.MigrationsPath)
+}
+
+// reportMigrationStatus logs the database's schema version, whether it's dirty,
+// and the migrations still to apply
+func reportMigrationStatus(db *sql.DB, migrationsPath string) error {
+	status, err := database.MigrationStatusWithPath(db, migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Schema version: %d (latest %d)", status.Version, status.Latest)
+	if status.Dirty {
+		log.Printf("Dirty: yes - migration %d failed part way through and needs fixing by hand", status.Version)
+	} else {
+		log.Printf("Dirty: no")
+	}
+	if len(status.Pending) == 0 {
+		log.Printf("Pending migrations: none")
+		return nil
+	}
+	log.Printf("Pending migrations: %d", len(status.Pending))
+	for _, migration := range status.Pending {
+		log.Printf("  %03d %s", migration.Version, migration.Name)
+	}
+	return nil
+}
+
 func runScoreCalculation(config *Config, db *sql.DB) error {
 	log.Println("=== Score Calculation Mode ===")
-	log.Println("Calculating scores for charities without scores...")
 
 	// Create importer just to use its CalculateAllScores method
 	imp := importer.NewImporter(db, importer.ImportConfig{
 		BatchSize:        config.BatchSize,
 		ProgressInterval: 5000,
 		Verbose:          config.Verbose,
+		ScoreSince:       config.ScoreSince,
 	})
 
-	if err := imp.CalculateAllScores(); err != nil {
-		return fmt.Errorf("failed to calculate scores: %w", err)
+	if config.WarmScoresFile != "" {
+		numbers, err := readCharityNumbers(config.WarmScoresFile)
+		if err != nil {
+			return fmt.Errorf("failed to read -warm-scores file: %w", err)
+		}
+		if err := imp.WarmScores(numbers); err != nil {
+			return fmt.Errorf("failed to warm scores: %w", err)
+		}
+	} else {
+		log.Println("Calculating scores for charities without scores...")
+		if err := imp.CalculateAllScores(); err != nil {
+			return fmt.Errorf("failed to calculate scores: %w", err)
+		}
 	}
 
 	log.Println("\n=== Score Calculation Complete ===")
+	return writeImportStats(config, imp)
+}
+
+// readCharityNumbers reads a list of charity numbers, one per line. Blank lines
+// and lines starting with # are ignored.
+func readCharityNumbers(path string) ([]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var numbers []int
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		number, err := strconv.Atoi(line)
+		if err == nil {
+			err = validation.ValidateCharityNumber(number)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid charity number %q", n+1, line)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+func runReindex(config *Config, db *sql.DB) error {
+	log.Println("=== Reindex Mode ===")
+	log.Println("Rebuilding derived data and indexes from the charities table...")
+
+	imp := importer.NewImporter(db, importer.ImportConfig{
+		BatchSize:        config.BatchSize,
+		ProgressInterval: 5000,
+		Verbose:          config.Verbose,
+	})
+
+	if err := imp.Reindex(); err != nil {
+		return fmt.Errorf("failed to reindex: %w", err)
+	}
+
+	log.Println("\n=== Reindex Complete ===")
+	return writeImportStats(config, imp)
+}
+
+// runRetrySyncs retries the background syncs the server recorded as failed, like
+// the server's retry-failed-syncs endpoint but without its request timeout
+func runRetrySyncs(config *Config, db *sql.DB) error {
+	log.Println("=== Retry Failed Syncs Mode ===")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	cfg := &appconfig.Config{
+		CharityAPIKey:  config.APIKeys[0],
+		CharityAPIKeys: config.APIKeys,
+		SyncRateLimit:  config.RateLimit,
+		Debug:          config.Verbose,
+	}
+	report, err := charitysync.RetryFailedSyncs(ctx, cfg, db, config.Limit)
+	if err != nil {
+		return fmt.Errorf("failed to retry syncs: %w", err)
+	}
+
+	for _, outcome := range report.Outcomes {
+		if outcome.Succeeded {
+			log.Printf("Charity %d: synced", outcome.CharityNumber)
+		} else {
+			log.Printf("Charity %d: failed: %s", outcome.CharityNumber, outcome.Error)
+		}
+	}
+
+	log.Println("\n=== Retry Complete ===")
+	log.Printf("Retried: %d, succeeded: %d, failed: %d, still to retry: %d",
+		report.Retried, report.Succeeded, report.Failed, report.Remaining)
+
+	config.report = &StatsReport{
+		Mode:           config.Mode,
+		TotalProcessed: report.Retried,
+		Successful:     report.Succeeded,
+		Failed:         report.Failed,
+	}
 	return nil
 }
 
@@ -197,54 +634,122 @@ func runFileImport(config *Config, db *sql.DB) error {
 	if config.FinancialFile != "" {
 		log.Printf("Financial file: %s", config.FinancialFile)
 	}
+	if config.PartAFile != "" {
+		log.Printf("Annual return Part A file: %s", config.PartAFile)
+	}
 	if config.AnnualReturnHistoryFile != "" {
 		log.Printf("Annual return history file: %s", config.AnnualReturnHistoryFile)
 	}
+	if config.ClassificationFile != "" {
+		log.Printf("Classification file: %s", config.ClassificationFile)
+	}
+	if config.EventHistoryFile != "" {
+		log.Printf("Event history file: %s", config.EventHistoryFile)
+	}
 	log.Printf("Batch size: %d\n", config.BatchSize)
+	if config.Limit > 0 {
+		log.Printf("Record limit: %d per file", config.Limit)
+	}
 
 	// Create importer
 	imp := importer.NewImporter(db, importer.ImportConfig{
 		CharityFile:             config.CharityFile,
 		TrusteeFile:             config.TrusteeFile,
 		FinancialFile:           config.FinancialFile,
+		PartAFile:               config.PartAFile,
 		AnnualReturnHistoryFile: config.AnnualReturnHistoryFile,
+		ClassificationFile:      config.ClassificationFile,
+		EventHistoryFile:        config.EventHistoryFile,
 		BatchSize:               config.BatchSize,
 		ProgressInterval:        5000,
+		MaxRecords:              config.Limit,
 		Verbose:                 config.Verbose,
+		ScoreDuringImport:       config.ScoreDuringImport,
+
+		// Only import matching charities
+		FilterPostcodePrefixes: config.PostcodePrefixes,
+		MinLatestIncome:        config.MinIncome,
+
+		// Reject implausible financial data
+		MaxExpenditureRatio: config.MaxExpenditureRatio,
+
+		ImportAllPeriods: config.AllFinancialPeriods,
 	})
 
+	// A file that fails to import doesn't stop the others
+	var outcomes importOutcomes
+
+	// Inputs given as URLs are downloaded and imported from memory
+	ctx := context.Background()
+	dl := newDownloader(config)
+
 	// Import charities first
-	log.Println("\n[1/5] Importing charities...")
-	if err := imp.ImportCharities(); err != nil {
-		return fmt.Errorf("failed to import charities: %w", err)
-	}
+	log.Println("\n[1/8] Importing charities...")
+	outcomes.add("charity", importInput(ctx, dl, config.CharityFile, imp.ImportCharities, imp.ImportCharitiesFromReader))
 
 	// Then import trustees
-	log.Println("\n[2/5] Importing trustees...")
-	if err := imp.ImportTrustees(); err != nil {
-		return fmt.Errorf("failed to import trustees: %w", err)
+	log.Println("\n[2/8] Importing trustees...")
+	outcomes.add("trustee", importInput(ctx, dl, config.TrusteeFile, imp.ImportTrustees, imp.ImportTrusteesFromReader))
+
+	// Import annual return history for scoring, ahead of financials so it's in
+	// place for charities scored during the financial import
+	log.Println("\n[3/8] Importing annual return history...")
+	if config.AnnualReturnHistoryFile != "" {
+		outcomes.add("annual return history", importInput(ctx, dl, config.AnnualReturnHistoryFile,
+			imp.ImportAnnualReturnHistory, imp.ImportAnnualReturnHistoryFromReader))
+	} else {
+		outcomes.skip("annual return history")
+	}
+
+	// Import annual return Part A, also ahead of financials for the same reason
+	log.Println("\n[4/8] Importing annual return Part A...")
+	if config.PartAFile != "" {
+		outcomes.add("annual return Part A", importInput(ctx, dl, config.PartAFile,
+			imp.ImportAnnualReturnPartA, imp.ImportAnnualReturnPartAFromReader))
+	} else {
+		outcomes.skip("annual return Part A")
 	}
 
 	// Import detailed financials
-	log.Println("\n[3/5] Importing detailed financial data...")
-	if err := imp.ImportFinancials(); err != nil {
-		return fmt.Errorf("failed to import financial data: %w", err)
+	log.Println("\n[5/8] Importing detailed financial data...")
+	if config.FinancialFile != "" {
+		outcomes.add("financial", importInput(ctx, dl, config.FinancialFile, imp.ImportFinancials, imp.ImportFinancialsFromReader))
+	} else {
+		outcomes.skip("financial")
+	}
+
+	// Import classifications for category browsing
+	log.Println("\n[6/8] Importing classifications...")
+	if config.ClassificationFile != "" {
+		outcomes.add("classification", importInput(ctx, dl, config.ClassificationFile,
+			imp.ImportClassifications, imp.ImportClassificationsFromReader))
+	} else {
+		outcomes.skip("classification")
 	}
 
-	// Import annual return history for scoring
-	log.Println("\n[4/5] Importing annual return history...")
-	if err := imp.ImportAnnualReturnHistory(); err != nil {
-		log.Printf("Warning: Failed to import annual return history: %v", err)
+	// Import removal reasons for removed charities
+	log.Println("\n[7/8] Importing removal reasons...")
+	if config.EventHistoryFile != "" {
+		outcomes.add("event history", importInput(ctx, dl, config.EventHistoryFile,
+			imp.ImportRemovalReasons, imp.ImportRemovalReasonsFromReader))
+	} else {
+		outcomes.skip("event history")
 	}
 
 	// Calculate scores for all imported charities
-	log.Println("\n[5/5] Calculating scores for all charities...")
+	log.Println("\n[8/8] Calculating scores for all charities...")
 	if err := imp.CalculateAllScores(); err != nil {
 		log.Printf("Warning: Failed to calculate all scores: %v (import was successful)", err)
 	}
 
+	imp.LogAnomalyReport()
+	outcomes.log()
+
 	log.Println("\n=== File Import Complete ===")
-	return nil
+	if err := writeImportStats(config, imp); err != nil {
+		return err
+	}
+	return outcomes.err()
 }
 
 func runDownloadImport(config *Config, db *sql.DB) error {
@@ -253,11 +758,137 @@ func runDownloadImport(config *Config, db *sql.DB) error {
 	log.Println("=== Download Import Mode ===")
 	log.Println("Downloading Charity Commission data files...")
 
-	// Create downloader with progress tracking
-	dl := downloader.NewDownloader(downloader.Config{
-		Timeout:    15 * time.Minute,
+	dl := newDownloader(config)
+
+	// Create importer
+	imp := importer.NewImporter(db, importer.ImportConfig{
+		BatchSize:         config.BatchSize,
+		ProgressInterval:  5000,
+		MaxRecords:        config.Limit,
+		Verbose:           config.Verbose,
+		ScoreDuringImport: config.ScoreDuringImport,
+
+		// Only import matching charities
+		FilterPostcodePrefixes: config.PostcodePrefixes,
+		MinLatestIncome:        config.MinIncome,
+
+		// Reject implausible financial data
+		MaxExpenditureRatio: config.MaxExpenditureRatio,
+
+		ImportAllPeriods: config.AllFinancialPeriods,
+	})
+
+	// The files are imported in this order. Annual return history and Part A go
+	// ahead of financials so they're in place for charities scored during the
+	// financial import.
+	steps := []downloadStep{
+		{downloader.FileCharity, "charity", "charities", imp.ImportCharitiesFromReader, ""},
+		{downloader.FileCharityTrustee, "trustee", "trustees", imp.ImportTrusteesFromReader, ""},
+		{downloader.FileCharityAnnualReturnHist, "annual return history", "annual return history", imp.ImportAnnualReturnHistoryFromReader,
+			"Annual return history file not downloaded, scoring will have limited transparency metrics"},
+		{downloader.FileCharityAnnualReturnA, "annual return Part A", "annual return Part A", imp.ImportAnnualReturnPartAFromReader,
+			"Annual return Part A file not downloaded, efficiency scoring won't allow for trading subsidiaries"},
+		{downloader.FileCharityAnnualReturnB, "financial", "financial data", imp.ImportFinancialsFromReader,
+			"Financial file not downloaded, skipping detailed financial data"},
+		{downloader.FileCharityClassification, "classification", "classifications", imp.ImportClassificationsFromReader,
+			"Classification file not downloaded, category browsing will not be available"},
+		{downloader.FileCharityEventHistory, "event history", "removal reasons", imp.ImportRemovalReasonsFromReader,
+			"Event history file not downloaded, removal reasons will not be available"},
+	}
+
+	// A file that fails to download or import doesn't stop the others
+	var outcomes importOutcomes
+
+	// Download in import order, then any requested files that aren't imported.
+	// Files not in the requested set are skipped rather than treated as failures.
+	requested := make(map[downloader.FileType]bool)
+	for _, ft := range config.Files {
+		requested[ft] = true
+	}
+	stepNumbers := make(map[downloader.FileType]int)
+	var order []downloader.FileType
+	for n, step := range steps {
+		stepNumbers[step.fileType] = n + 1
+		if requested[step.fileType] {
+			order = append(order, step.fileType)
+		} else {
+			log.Printf("Skipping %s (not requested)", step.label)
+			outcomes.skip(step.name)
+		}
+	}
+	for _, ft := range config.Files {
+		if stepNumbers[ft] == 0 {
+			order = append(order, ft)
+		}
+	}
+
+	if config.DownloadConcurrency > 0 {
+		log.Printf("Downloading at most %d files at once, importing each as soon as it's ready", config.DownloadConcurrency)
+	}
+
+	// Each file is imported as soon as it and the files before it are downloaded,
+	// and its data released once imported
+	files := make(map[downloader.FileType]*downloader.DownloadedFile)
+	var totalSize int64
+	dl.DownloadEach(ctx, order, config.DownloadConcurrency, func(ft downloader.FileType, file *downloader.DownloadedFile, err error) {
+		if err == nil {
+			files[ft] = file
+			totalSize += file.Size
+		}
+
+		n := stepNumbers[ft]
+		if n == 0 {
+			if err != nil {
+				log.Printf("Warning: Failed to download %s: %v", ft, err)
+			}
+			return
+		}
+		step := steps[n-1]
+
+		log.Printf("\n[%d/%d] Importing %s from downloaded data...", n, len(steps)+1, step.label)
+		switch {
+		case err != nil && step.missing == "":
+			outcomes.add(step.name, err)
+		case err != nil:
+			log.Printf("Warning: %s: %v", step.missing, err)
+			outcomes.skip(step.name)
+		default:
+			outcomes.add(step.name, step.importFrom(file.GetReader()))
+		}
+
+		// Release the extract; only its sizes are kept for the summary
+		if file != nil {
+			file.Data = nil
+		}
+	})
+
+	printDownloadSummary(order, files)
+	log.Printf("Total data size: %.2f MB\n", float64(totalSize)/1024.0/1024.0)
+
+	// Calculate scores
+	log.Printf("\n[%d/%d] Calculating scores for all charities...", len(steps)+1, len(steps)+1)
+	if err := imp.CalculateAllScores(); err != nil {
+		log.Printf("Warning: Failed to calculate all scores: %v (import was successful)", err)
+	}
+
+	imp.LogAnomalyReport()
+	outcomes.log()
+
+	log.Println("\n=== Download Import Complete ===")
+	if err := writeImportStats(config, imp); err != nil {
+		return err
+	}
+	return outcomes.err()
+}
+
+// newDownloader creates the downloader for data files, logging progress every 10%
+func newDownloader(config *Config) *downloader.Downloader {
+	return downloader.NewDownloader(downloader.Config{
+		Timeout:    config.DownloadTimeout,
 		MaxRetries: 3,
 		RetryDelay: 10 * time.Second,
+		UserAgent:  config.UserAgent,
+		Transport:  config.transport(),
 		ProgressHandler: func(fileType downloader.FileType, bytesDownloaded, totalBytes int64) {
 			if totalBytes > 0 {
 				pct := float64(bytesDownloaded) / float64(totalBytes) * 100
@@ -268,79 +899,176 @@ func runDownloadImport(config *Config, db *sql.DB) error {
 			}
 		},
 	})
+}
 
-	// Download all required files in parallel
-	files, err := dl.DownloadFiles(ctx, downloader.DefaultFileSet())
+// importInput imports one file mode input: a local path with fromFile, or an
+// http(s) URL downloaded, decompressed if need be, and passed to fromReader
+func importInput(ctx context.Context, dl *downloader.Downloader, path string, fromFile func() error, fromReader func(io.Reader) error) error {
+	if !downloader.IsRemote(path) {
+		return fromFile()
+	}
+	file, err := dl.DownloadURL(ctx, path)
 	if err != nil {
-		return fmt.Errorf("failed to download files: %w", err)
+		return err
+	}
+	return fromReader(file.GetReader())
+}
+
+// checkInput confirms a file mode input exists. URLs are only checked when
+// they're downloaded, at import.
+func checkInput(path string) error {
+	if downloader.IsRemote(path) {
+		return nil
 	}
+	_, err := importer.InputPaths(path)
+	return err
+}
 
-	log.Printf("\nAll files downloaded successfully!")
-	log.Printf("Total data size: %.2f MB\n", float64(calculateTotalSize(files))/1024.0/1024.0)
+// downloadStep imports one downloaded file in download mode
+type downloadStep struct {
+	fileType   downloader.FileType
+	name       string // In the file outcomes
+	label      string // In the progress log
+	importFrom func(io.Reader) error
 
-	// Create importer
-	imp := importer.NewImporter(db, importer.ImportConfig{
-		BatchSize:        config.BatchSize,
-		ProgressInterval: 5000,
-		Verbose:          config.Verbose,
-	})
+	// missing is the warning when an optional file fails to download; a failed
+	// download of a file without one fails the import
+	missing string
+}
 
-	// Import charities from in-memory data
-	log.Println("[1/4] Importing charities from downloaded data...")
-	if charityFile, ok := files[downloader.FileCharity]; ok {
-		if err := imp.ImportCharitiesFromReader(charityFile.GetReader()); err != nil {
-			return fmt.Errorf("failed to import charities: %w", err)
-		}
-	} else {
-		return fmt.Errorf("charity file not downloaded")
+// importOutcomes records how each file of an import went, so a bad file can be
+// reported without abandoning the rest
+type importOutcomes struct {
+	files   []string
+	failed  map[string]error
+	skipped map[string]bool
+}
+
+// add records the result of importing a file, logging a failure straight away
+func (o *importOutcomes) add(file string, err error) {
+	o.files = append(o.files, file)
+	if err == nil {
+		return
 	}
+	log.Printf("Warning: Failed to import %s file: %v", file, err)
+	if o.failed == nil {
+		o.failed = make(map[string]error)
+	}
+	o.failed[file] = err
+}
+
+// skip records a file that wasn't imported because it wasn't given or requested
+func (o *importOutcomes) skip(file string) {
+	if o.skipped == nil {
+		o.skipped = make(map[string]bool)
+	}
+	o.files = append(o.files, file)
+	o.skipped[file] = true
+}
 
-	// Import trustees from in-memory data
-	log.Println("\n[2/4] Importing trustees from downloaded data...")
-	if trusteeFile, ok := files[downloader.FileCharityTrustee]; ok {
-		if err := imp.ImportTrusteesFromReader(trusteeFile.GetReader()); err != nil {
-			return fmt.Errorf("failed to import trustees: %w", err)
+// log summarises the outcome of each file
+func (o *importOutcomes) log() {
+	log.Println("\n=== File Outcomes ===")
+	for _, file := range o.files {
+		switch {
+		case o.failed[file] != nil:
+			log.Printf("  %s: failed: %v", file, o.failed[file])
+		case o.skipped[file]:
+			log.Printf("  %s: skipped", file)
+		default:
+			log.Printf("  %s: imported", file)
 		}
-	} else {
-		return fmt.Errorf("trustee file not downloaded")
 	}
+}
 
-	// Import financial data from in-memory data
-	log.Println("\n[3/5] Importing financial data from downloaded data...")
-	if financialFile, ok := files[downloader.FileCharityAnnualReturnB]; ok {
-		if err := imp.ImportFinancialsFromReader(financialFile.GetReader()); err != nil {
-			return fmt.Errorf("failed to import financials: %w", err)
+// err returns an error listing the files that failed, or nil if none did
+func (o *importOutcomes) err() error {
+	if len(o.failed) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, file := range o.files {
+		if err := o.failed[file]; err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
 		}
-	} else {
-		log.Println("Warning: Financial file not downloaded, skipping detailed financial data")
 	}
+	return fmt.Errorf("%d of %d files failed to import:\n%w", len(errs), len(o.files)-len(o.skipped), errors.Join(errs...))
+}
+
+// runValidateDownloads checks each requested file is available for download with a
+// HEAD request, reporting its size and last-modified date, and fails if any isn't
+func runValidateDownloads(config *Config) error {
+	ctx := context.Background()
+
+	log.Println("=== Download Validation Mode ===")
+	log.Println("Checking Charity Commission data files are available...")
+
+	dl := downloader.NewDownloader(downloader.Config{
+		Timeout:    config.ResponseTimeout,
+		MaxRetries: 3,
+		RetryDelay: 10 * time.Second,
+		UserAgent:  config.UserAgent,
+		Transport:  config.transport(),
+	})
 
-	// Import annual return history from in-memory data
-	log.Println("\n[4/5] Importing annual return history from downloaded data...")
-	if historyFile, ok := files[downloader.FileCharityAnnualReturnHist]; ok {
-		if err := imp.ImportAnnualReturnHistoryFromReader(historyFile.GetReader()); err != nil {
-			log.Printf("Warning: Failed to import annual return history: %v", err)
+	// Check in parallel, then report in the requested order
+	infos := make([]*downloader.FileInfo, len(config.Files))
+	errs := make([]error, len(config.Files))
+	var wg sync.WaitGroup
+	for i, fileType := range config.Files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			infos[i], errs[i] = dl.CheckFile(ctx, fileType)
+		}()
+	}
+	wg.Wait()
+
+	var unavailable []string
+	for i, fileType := range config.Files {
+		info, err := infos[i], errs[i]
+		if err != nil {
+			log.Printf("  %-30s UNAVAILABLE: %v", fileType, err)
+			unavailable = append(unavailable, string(fileType))
+			continue
 		}
-	} else {
-		log.Println("Warning: Annual return history file not downloaded, scoring will have limited transparency metrics")
+
+		size := "size unknown"
+		if info.Size >= 0 {
+			size = fmt.Sprintf("%.2f MB", float64(info.Size)/1024/1024)
+		}
+		modified := "last modified unknown"
+		if !info.LastModified.IsZero() {
+			modified = "last modified " + info.LastModified.UTC().Format("2006-01-02 15:04 MST")
+		}
+		log.Printf("  %-30s OK, %s, %s", fileType, size, modified)
 	}
 
-	// Calculate scores
-	log.Println("\n[5/5] Calculating scores for all charities...")
-	if err := imp.CalculateAllScores(); err != nil {
-		log.Printf("Warning: Failed to calculate all scores: %v (import was successful)", err)
+	if len(unavailable) > 0 {
+		return fmt.Errorf("%d of %d files unavailable: %s", len(unavailable), len(config.Files), strings.Join(unavailable, ", "))
 	}
 
-	log.Println("\n=== Download Import Complete ===")
+	log.Printf("\nAll %d files are available", len(config.Files))
 	return nil
 }
 
-func calculateTotalSize(files map[downloader.FileType]*downloader.DownloadedFile) int64 {
-	var total int64
-	for _, file := range files {
-		total += file.Size
+// printDownloadSummary logs per-file download size, time and throughput
+func printDownloadSummary(order []downloader.FileType, files map[downloader.FileType]*downloader.DownloadedFile) {
+	log.Println("\n=== Download Summary ===")
+	log.Printf("%-32s %12s %12s %10s %10s", "File", "ZIP (MB)", "JSON (MB)", "Time", "MB/s")
+	for _, ft := range order {
+		file, ok := files[ft]
+		if !ok {
+			continue
+		}
+		log.Printf("%-32s %12.2f %12.2f %10v %10.2f",
+			ft,
+			float64(file.CompressedSize)/1024.0/1024.0,
+			float64(file.Size)/1024.0/1024.0,
+			file.Duration.Round(time.Second),
+			file.Throughput()/1024.0/1024.0,
+		)
 	}
-	return total
 }
 
 func runAPIScrape(config *Config, db *sql.DB) error {
@@ -358,13 +1086,17 @@ func runAPIScrape(config *Config, db *sql.DB) error {
 	}()
 
 	// Create API client with multiple keys
-	rateLimiter := api.NewRateLimiter(config.RateLimit)
+	rateLimiter := api.NewRateLimiterFloat(config.RateLimit)
 	apiClient := api.NewClient(api.ClientConfig{
 		APIKeys:     config.APIKeys,
 		UserAgent:   "CharityLens-Seeder/1.0 (Charity Transparency Tool)",
 		RateLimiter: rateLimiter,
 		MaxRetries:  config.MaxRetries,
+		RetryBudget: config.RetryBudget,
 		Verbose:     config.Verbose,
+		Transport:   config.transport(),
+		// Every charity gets its own attempt; an open circuit would fail whole ranges
+		BreakerThreshold: -1,
 	})
 
 	// Determine starting point
@@ -383,8 +1115,35 @@ func runAPIScrape(config *Config, db *sql.DB) error {
 	// Calculate total work for progress bar
 	totalCharities := config.EndCharity - startCharity + 1
 
-	// Create progress bar
-	bar := progressbar.NewOptions(totalCharities,
+	// Create progress bar, unless progress is logged as text instead
+	var bar *progressbar.ProgressBar
+	if !config.NoProgress {
+		bar = newProgressBar(totalCharities)
+	}
+
+	// Create scraper
+	scraper := &Scraper{
+		config:      config,
+		db:          db,
+		apiClient:   apiClient,
+		progressBar: bar,
+		total:       totalCharities,
+		stats: &Stats{
+			StartTime:      time.Now(),
+			LastCheckpoint: time.Now(),
+			CurrentCharity: startCharity,
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	// Run the scraper (progress bar will show real-time updates)
+	return scraper.scrape()
+}
+
+// newProgressBar creates the progress bar shown while scraping total charities
+func newProgressBar(total int) *progressbar.ProgressBar {
+	return progressbar.NewOptions(total,
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionShowBytes(false),
 		progressbar.OptionSetWidth(40),
@@ -404,24 +1163,6 @@ func runAPIScrape(config *Config, db *sql.DB) error {
 			fmt.Println()
 		}),
 	)
-
-	// Create scraper
-	scraper := &Scraper{
-		config:      config,
-		db:          db,
-		apiClient:   apiClient,
-		progressBar: bar,
-		stats: &Stats{
-			StartTime:      time.Now(),
-			LastCheckpoint: time.Now(),
-			CurrentCharity: startCharity,
-		},
-		ctx:    ctx,
-		cancel: cancel,
-	}
-
-	// Run the scraper (progress bar will show real-time updates)
-	return scraper.scrape()
 }
 
 func initDatabase(dbPath, migrationsPath string) (*sql.DB, error) {
@@ -458,12 +1199,38 @@ func initDatabase(dbPath, migrationsPath string) (*sql.DB, error) {
 
 	// Run migrations with specified path
 	if err := database.MigrateWithPath(db, migrationsPath); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
 	return db, nil
 }
 
+// openDatabase opens the SQLite database at dbPath without running migrations,
+// for the migration modes. Unlike initDatabase it won't create a database that
+// doesn't exist.
+func openDatabase(dbPath string) (*sql.DB, error) {
+	if err := database.CheckReadable(dbPath); err != nil {
+		return nil, fmt.Errorf("database not found: %w", err)
+	}
+
+	origDBType := os.Getenv("DATABASE_TYPE")
+	origDBURL := os.Getenv("DATABASE_URL")
+	defer func() {
+		os.Setenv("DATABASE_TYPE", origDBType)
+		os.Setenv("DATABASE_URL", origDBURL)
+	}()
+
+	os.Setenv("DATABASE_TYPE", "sqlite")
+	os.Setenv("DATABASE_URL", dbPath)
+
+	db, err := database.InitDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	return db, nil
+}
+
 func loadCheckpoint(db *sql.DB) (int, error) {
 	var checkpoint int
 	err := db.QueryRow("SELECT last_charity_number FROM scraper_checkpoints WHERE id = 1").Scan(&checkpoint)
@@ -474,7 +1241,7 @@ func loadCheckpoint(db *sql.DB) (int, error) {
 }
 
 func saveCheckpoint(db *sql.DB, charityNumber int) error {
-	_, err := db.Exec(`
+	_, err := database.ExecRetry(db, `
 		INSERT INTO scraper_checkpoints (id, last_charity_number, updated_at)
 		VALUES (1, ?, CURRENT_TIMESTAMP)
 		ON CONFLICT(id) DO UPDATE SET 
@@ -488,7 +1255,7 @@ func (s *Scraper) scrape() error {
 	fmt.Printf("\n")
 	fmt.Printf("🔍 Starting scraper\n")
 	fmt.Printf("   Range: %d to %d\n", s.stats.CurrentCharity, s.config.EndCharity)
-	fmt.Printf("   Rate limit: %d req/s\n", s.config.RateLimit)
+	fmt.Printf("   Rate limit: %g req/s\n", s.config.RateLimit)
 	fmt.Printf("   Workers: %d\n", s.config.Concurrency)
 	fmt.Printf("   API keys: %d\n\n", len(s.config.APIKeys))
 
@@ -518,7 +1285,7 @@ func (s *Scraper) scrape() error {
 				s.stats.mu.Unlock()
 
 				// Save checkpoint periodically
-				if charityNum%checkpointInterval == 0 {
+				if charityNum%s.config.CheckpointInterval == 0 {
 					if err := saveCheckpoint(s.db, charityNum); err != nil {
 						log.Printf("Failed to save checkpoint: %v", err)
 					}
@@ -531,7 +1298,9 @@ func (s *Scraper) scrape() error {
 	wg.Wait()
 
 	// Ensure progress bar is finished
-	s.progressBar.Finish()
+	if s.progressBar != nil {
+		s.progressBar.Finish()
+	}
 
 	// Final checkpoint
 	if err := saveCheckpoint(s.db, s.stats.CurrentCharity); err != nil {
@@ -539,7 +1308,7 @@ func (s *Scraper) scrape() error {
 	}
 
 	s.printFinalStats()
-	return nil
+	return s.writeFinalStats()
 }
 
 func (s *Scraper) worker(workerID int, workQueue <-chan int) {
@@ -568,10 +1337,28 @@ func (s *Scraper) worker(workerID int, workQueue <-chan int) {
 		s.stats.mu.Unlock()
 
 		// Update progress bar
-		s.progressBar.Add(1)
+		if s.progressBar != nil {
+			s.progressBar.Add(1)
+		} else {
+			s.logProgress()
+		}
 	}
 }
 
+// logProgress logs a line of progress every progressInterval charities, in place
+// of the progress bar
+func (s *Scraper) logProgress() {
+	s.stats.mu.Lock()
+	defer s.stats.mu.Unlock()
+
+	if s.stats.TotalProcessed%progressInterval != 0 && s.stats.TotalProcessed != s.total {
+		return
+	}
+	rate := float64(s.stats.TotalProcessed) / time.Since(s.stats.StartTime).Seconds()
+	log.Printf("Progress: %d/%d processed (%d success, %d failed, %d skipped) | Rate: %.2f/sec",
+		s.stats.TotalProcessed, s.total, s.stats.Successful, s.stats.Failed, s.stats.Skipped, rate)
+}
+
 func (s *Scraper) processCharity(charityNum int) error {
 	// Check if charity already exists
 	var exists bool
@@ -587,7 +1374,7 @@ func (s *Scraper) processCharity(charityNum int) error {
 	data, err := s.apiClient.FetchCharityDetails(s.ctx, charityNum)
 	if err != nil {
 		// 404 is expected for non-existent charity numbers
-		if err.Error() == "not found (404)" {
+		if errors.Is(err, api.ErrNotFound) {
 			s.stats.mu.Lock()
 			s.stats.Skipped++
 			s.stats.mu.Unlock()
@@ -596,8 +1383,8 @@ func (s *Scraper) processCharity(charityNum int) error {
 		return err
 	}
 
-	// Store data in database
-	return s.storeCharity(data, charityNum)
+	// Store data in database, retrying the transaction if another worker holds the write lock
+	return database.RetryBusy(func() error { return s.storeCharity(data, charityNum) })
 }
 
 func (s *Scraper) storeCharity(data map[string]any, charityNum int) error {
@@ -613,45 +1400,40 @@ func (s *Scraper) storeCharity(data map[string]any, charityNum int) error {
 		return fmt.Errorf("failed to parse charity: %w", err)
 	}
 
-	// Insert charity
-	_, err = tx.Exec(`
-		INSERT OR REPLACE INTO charities
-		(registered_number, company_number, name, status, date_registered, address, website, email, phone, what_the_charity_does, last_updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, charity.RegisteredNumber, charity.CompanyNumber, charity.Name, charity.Status,
-		charity.DateRegistered, charity.Address, charity.Website, charity.Email, charity.Phone,
-		charity.WhatTheCharityDoes, charity.LastUpdated)
-	if err != nil {
+	// Insert or update charity
+	if err := database.UpsertCharity(tx, charity); err != nil {
 		return fmt.Errorf("failed to insert charity: %w", err)
 	}
 
 	// Parse and store financials using shared parser
 	financial, err := api.ParseFinancialData(data, charityNum)
 	if err == nil && (financial.TotalIncome > 0 || financial.TotalSpending > 0) {
-		_, err = tx.Exec(`
-			INSERT OR REPLACE INTO financials
-			(charity_number, financial_year_end, total_income, total_spending, charitable_activities_spend,
-			 raising_funds_spend, other_spend, reserves, assets, trustees, last_updated)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`, financial.CharityNumber, financial.FinancialYearEnd, financial.TotalIncome, financial.TotalSpending,
+		_, err = tx.Exec(database.UpsertFinancialSQL,
+			financial.CharityNumber, financial.FinancialYearEnd, financial.TotalIncome, financial.TotalSpending,
 			financial.CharitableActivitiesSpend, financial.RaisingFundsSpend, financial.OtherSpend,
-			financial.Reserves, financial.Assets, financial.Trustees, financial.LastUpdated)
+			financial.Reserves, financial.Assets, financial.Employees, financial.Trustees, financial.LastUpdated)
 		if err != nil {
 			return fmt.Errorf("failed to insert financial: %w", err)
 		}
+		if err := database.SetDataSource(tx, database.FinancialsSection, charityNum, models.SourceAPI); err != nil {
+			return fmt.Errorf("failed to record financials source: %w", err)
+		}
 	}
 
 	// Parse and store trustees using shared parser
 	trustees := api.ParseTrusteesData(data, charityNum)
 	for _, trustee := range trustees {
-		_, err = tx.Exec(`
-			INSERT OR REPLACE INTO trustees (charity_number, name, last_updated)
-			VALUES (?, ?, ?)
-		`, trustee.CharityNumber, trustee.Name, trustee.LastUpdated)
+		_, err = tx.Exec(database.InsertTrusteeSQL,
+			trustee.CharityNumber, trustee.Name, database.TrusteeNameKey(trustee.Name), trustee.LastUpdated)
 		if err != nil {
 			return fmt.Errorf("failed to insert trustee: %w", err)
 		}
 	}
+	if len(trustees) > 0 {
+		if err := database.SetDataSource(tx, database.TrusteesSection, charityNum, models.SourceAPI); err != nil {
+			return fmt.Errorf("failed to record trustees source: %w", err)
+		}
+	}
 
 	return tx.Commit()
 }
@@ -670,6 +1452,10 @@ func (s *Scraper) printFinalStats() {
 	log.Printf("Average Rate: %.2f charities/second", float64(s.stats.TotalProcessed)/elapsed.Seconds())
 	log.Printf("Last Charity: %d", s.stats.CurrentCharity)
 
+	if _, refused := s.apiClient.GetRetryBudgetStats(); refused > 0 {
+		log.Printf("Retries refused (budget exhausted): %d", refused)
+	}
+
 	// Print API key stats if multiple keys were used
 	keyStats := s.apiClient.GetKeyStats()
 	if len(keyStats) > 1 {
@@ -680,3 +1466,119 @@ func (s *Scraper) printFinalStats() {
 		}
 	}
 }
+
+// StatsReport is the machine-readable summary written by -stats-json
+type StatsReport struct {
+	Mode            string                `json:"mode"`
+	TotalProcessed  int                   `json:"total_processed"`
+	Successful      int                   `json:"successful"`
+	Failed          int                   `json:"failed"`
+	Skipped         int                   `json:"skipped"`
+	DurationSeconds float64               `json:"duration_seconds"`
+	Rate            float64               `json:"rate"`
+	LastCharity     int                   `json:"last_charity,omitempty"`
+	Keys            map[string]KeyReport  `json:"keys,omitempty"`
+	Phases          []importer.PhaseStats `json:"phases,omitempty"`
+
+	// Anomalies is the financial data rejected as implausible, if any
+	Anomalies *importer.AnomalyReport `json:"anomalies,omitempty"`
+
+	// DuplicateCharities counts the main charity entries skipped as duplicates,
+	// which are recorded in data_anomalies
+	DuplicateCharities int `json:"duplicate_charities,omitempty"`
+
+	// Trustees splits the trustees imported into new and already stored ones
+	Trustees *importer.TrusteeCounts `json:"trustees,omitempty"`
+}
+
+// KeyReport holds per-API-key usage in a StatsReport
+type KeyReport struct {
+	TotalRequests  uint64    `json:"total_requests"`
+	FailedRequests uint64    `json:"failed_requests"`
+	LastUsed       time.Time `json:"last_used"`
+}
+
+// writeFinalStats records the scraper's final statistics for the webhook, and
+// writes them as JSON if -stats-json is set
+func (s *Scraper) writeFinalStats() error {
+	s.stats.mu.Lock()
+	elapsed := time.Since(s.stats.StartTime)
+	report := StatsReport{
+		Mode:            s.config.Mode,
+		TotalProcessed:  s.stats.TotalProcessed,
+		Successful:      s.stats.Successful,
+		Failed:          s.stats.Failed,
+		Skipped:         s.stats.Skipped,
+		DurationSeconds: elapsed.Seconds(),
+		Rate:            float64(s.stats.TotalProcessed) / elapsed.Seconds(),
+		LastCharity:     s.stats.CurrentCharity,
+		Keys:            make(map[string]KeyReport),
+	}
+	s.stats.mu.Unlock()
+
+	keyStats := s.apiClient.GetKeyStats()
+	for key := range keyStats {
+		report.Keys[key] = KeyReport{
+			TotalRequests:  keyStats[key].TotalRequests,
+			FailedRequests: keyStats[key].FailedRequests,
+			LastUsed:       keyStats[key].LastUsed,
+		}
+	}
+
+	s.config.report = &report
+	if s.config.StatsJSON == "" {
+		return nil
+	}
+	return writeStatsReport(s.config.StatsJSON, report)
+}
+
+// writeImportStats records the importer's per-phase statistics for the webhook,
+// and writes them as JSON if -stats-json is set
+func writeImportStats(config *Config, imp *importer.Importer) error {
+	report := StatsReport{
+		Mode:   config.Mode,
+		Phases: imp.GetPhaseStats(),
+	}
+	for _, phase := range report.Phases {
+		report.TotalProcessed += phase.Processed
+		report.Successful += phase.Successful
+		report.Failed += phase.Failed
+		report.Skipped += phase.Skipped
+		report.DurationSeconds += phase.DurationSeconds
+	}
+	if report.DurationSeconds > 0 {
+		report.Rate = float64(report.TotalProcessed) / report.DurationSeconds
+	}
+	if anomalies := imp.GetAnomalyReport(); anomalies.Total > 0 {
+		report.Anomalies = &anomalies
+	}
+	report.DuplicateCharities = imp.DuplicateCharities()
+	if trustees := imp.TrusteeCounts(); trustees.New+trustees.Unchanged > 0 {
+		report.Trustees = &trustees
+	}
+
+	config.report = &report
+	if config.StatsJSON == "" {
+		return nil
+	}
+	return writeStatsReport(config.StatsJSON, report)
+}
+
+// writeStatsReport encodes a report to the given path, or stdout for "-"
+func writeStatsReport(path string, report StatsReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	log.Printf("Wrote statistics to %s", path)
+	return nil
+}
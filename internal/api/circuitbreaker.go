@@ -0,0 +1,78 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker stops requests to the API after repeated connection or server
+// failures, so callers fail immediately during an outage instead of each waiting
+// out its own retries. Once the cooldown has passed, requests are let through
+// again; the first success closes the circuit and a failure reopens it.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	rejected  uint64
+	mu        sync.Mutex
+}
+
+// NewCircuitBreaker creates a circuit breaker that opens after threshold
+// consecutive failures and stays open for cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow reports whether a request may be attempted.
+// Returns false while the circuit is open.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if time.Now().Before(cb.openUntil) {
+		cb.rejected++
+		return false
+	}
+	return true
+}
+
+// IsOpen reports whether the circuit is currently open, without counting a rejection.
+func (cb *CircuitBreaker) IsOpen() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return time.Now().Before(cb.openUntil)
+}
+
+// RecordSuccess closes the circuit and resets the failure count.
+func (cb *CircuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.openUntil = time.Time{}
+}
+
+// RecordFailure counts a failure, opening the circuit once the threshold is reached.
+func (cb *CircuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	if cb.failures >= cb.threshold {
+		cb.openUntil = time.Now().Add(cb.cooldown)
+	}
+}
+
+// GetStats returns the current consecutive failure count and how many requests
+// have been rejected while the circuit was open.
+func (cb *CircuitBreaker) GetStats() (failures int, rejected uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.failures, cb.rejected
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -13,14 +14,55 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"charitylens/internal/httpclient"
 )
 
 const (
-	baseURL           = "https://api.charitycommission.gov.uk/register/api"
-	defaultTimeout    = 30 * time.Second
-	defaultMaxRetries = 3
+	baseURL            = "https://api.charitycommission.gov.uk/register/api"
+	defaultTimeout     = 30 * time.Second
+	defaultMaxRetries  = 3
+	defaultRetryBudget = 60 // retries per minute across all requests
+
+	defaultBreakerThreshold = 5 // consecutive failures before the circuit opens
+	defaultBreakerCooldown  = 30 * time.Second
+
+	defaultMaxResponseBytes = 50 * 1024 * 1024 // 50MB
 )
 
+// ErrRetryBudgetExhausted is returned when the client-wide retry budget has been
+// used up, indicating widespread failures rather than a single bad request.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// ErrCircuitOpen is returned without making a request while the circuit breaker is
+// open after repeated connection or server failures.
+var ErrCircuitOpen = errors.New("API unavailable (circuit open)")
+
+// ErrNotFound is returned when the API responds 404 for the requested resource.
+var ErrNotFound = errors.New("not found (404)")
+
+// ErrRateLimited is wrapped by the error returned when the API kept answering 429
+// until the retries ran out.
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrServerError is wrapped by the error returned when the API kept answering with
+// a 5xx status until the retries ran out.
+var ErrServerError = errors.New("server error")
+
+// ErrResponseTooLarge is returned when a response body exceeds MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// ErrBudgetExhausted is returned without making a request when the client's
+// CallBudget refuses it.
+var ErrBudgetExhausted = errors.New("API call budget exhausted")
+
+// CallBudget caps the requests a client makes, such as to stay within a key's
+// quota. Spend is called before every request, retries included, and returns
+// false to refuse it.
+type CallBudget interface {
+	Spend() bool
+}
+
 // Client is a client for the Charity Commission API with multi-key support.
 type Client struct {
 	apiKeys     []string
@@ -28,7 +70,11 @@ type Client struct {
 	userAgent   string
 	httpClient  *http.Client
 	rateLimiter *RateLimiter
+	retryBudget *RetryBudget
+	breaker     *CircuitBreaker
+	callBudget  CallBudget
 	maxRetries  int
+	maxBytes    int64
 	verbose     bool
 	keyStats    map[string]*KeyStats
 	mu          sync.RWMutex
@@ -49,8 +95,25 @@ type ClientConfig struct {
 	UserAgent   string
 	RateLimiter *RateLimiter
 	MaxRetries  int
+	RetryBudget int // Max retries per minute across all requests (0 = default, negative = unlimited)
 	Timeout     time.Duration
 	Verbose     bool
+
+	// Transport tunes connection pooling and the response header timeout
+	Transport httpclient.TransportConfig
+
+	// MaxResponseBytes caps the size of a response body (0 = default of 50MB)
+	MaxResponseBytes int64
+
+	// BreakerThreshold is the number of consecutive connection or server failures
+	// that open the circuit (0 = default of 5, negative = never open)
+	BreakerThreshold int
+	// BreakerCooldown is how long the circuit stays open (0 = default of 30s)
+	BreakerCooldown time.Duration
+
+	// CallBudget, if set, is spent on every request; once it refuses, requests
+	// fail with ErrBudgetExhausted
+	CallBudget CallBudget
 }
 
 // NewClient creates a new Charity Commission API client.
@@ -65,6 +128,29 @@ func NewClient(config ClientConfig) *Client {
 	if config.UserAgent == "" {
 		config.UserAgent = "CharityLens/1.0"
 	}
+	if config.RetryBudget == 0 {
+		config.RetryBudget = defaultRetryBudget
+	}
+	if config.MaxResponseBytes == 0 {
+		config.MaxResponseBytes = defaultMaxResponseBytes
+	}
+
+	if config.BreakerThreshold == 0 {
+		config.BreakerThreshold = defaultBreakerThreshold
+	}
+	if config.BreakerCooldown == 0 {
+		config.BreakerCooldown = defaultBreakerCooldown
+	}
+
+	var retryBudget *RetryBudget
+	if config.RetryBudget > 0 {
+		retryBudget = NewRetryBudget(config.RetryBudget)
+	}
+
+	var breaker *CircuitBreaker
+	if config.BreakerThreshold > 0 {
+		breaker = NewCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown)
+	}
 
 	// Support both single key and multiple keys
 	apiKeys := config.APIKeys
@@ -78,12 +164,23 @@ func NewClient(config ClientConfig) *Client {
 		keyStats[key] = &KeyStats{}
 	}
 
+	// Timeout covers the whole request; the transport's response header timeout
+	// catches stalled connections sooner
+	httpClient := &http.Client{
+		Timeout:   config.Timeout,
+		Transport: httpclient.NewTransport(config.Transport),
+	}
+
 	return &Client{
 		apiKeys:     apiKeys,
 		userAgent:   config.UserAgent,
-		httpClient:  &http.Client{Timeout: config.Timeout},
+		httpClient:  httpClient,
 		rateLimiter: config.RateLimiter,
+		retryBudget: retryBudget,
+		breaker:     breaker,
+		callBudget:  config.CallBudget,
 		maxRetries:  config.MaxRetries,
+		maxBytes:    config.MaxResponseBytes,
 		verbose:     config.Verbose,
 		keyStats:    keyStats,
 	}
@@ -120,12 +217,7 @@ func (c *Client) GetKeyStats() map[string]KeyStats {
 	result := make(map[string]KeyStats)
 	for key, stats := range c.keyStats {
 		stats.mu.Lock()
-		// Mask the key for security (show first 8 chars)
-		maskedKey := key
-		if len(key) > 8 {
-			maskedKey = key[:8] + "..." + key[len(key)-4:]
-		}
-		result[maskedKey] = KeyStats{
+		result[maskKey(key)] = KeyStats{
 			TotalRequests:  stats.TotalRequests,
 			FailedRequests: stats.FailedRequests,
 			LastUsed:       stats.LastUsed,
@@ -135,6 +227,47 @@ func (c *Client) GetKeyStats() map[string]KeyStats {
 	return result
 }
 
+// KeyUsage is a point-in-time copy of one API key's statistics, safe to share
+// between goroutines and to encode as JSON.
+type KeyUsage struct {
+	Key            string    `json:"key"` // Masked
+	TotalRequests  uint64    `json:"total_requests"`
+	FailedRequests uint64    `json:"failed_requests"`
+	LastUsed       time.Time `json:"last_used"`
+}
+
+// GetKeyUsage returns a snapshot of per-key statistics with masked keys, in a
+// stable order.
+func (c *Client) GetKeyUsage() []KeyUsage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	usage := make([]KeyUsage, 0, len(c.apiKeys))
+	for _, key := range c.apiKeys {
+		stats := c.keyStats[key]
+		if stats == nil {
+			continue
+		}
+		stats.mu.Lock()
+		usage = append(usage, KeyUsage{
+			Key:            maskKey(key),
+			TotalRequests:  stats.TotalRequests,
+			FailedRequests: stats.FailedRequests,
+			LastUsed:       stats.LastUsed,
+		})
+		stats.mu.Unlock()
+	}
+	return usage
+}
+
+// maskKey hides most of an API key for display (first 8 and last 4 chars)
+func maskKey(key string) string {
+	if len(key) > 8 {
+		return key[:8] + "..." + key[len(key)-4:]
+	}
+	return key
+}
+
 // FetchCharityDetails fetches complete charity details by charity number.
 func (c *Client) FetchCharityDetails(ctx context.Context, charityNum int) (map[string]any, error) {
 	url := fmt.Sprintf("%s/allcharitydetailsV2/%d/0", baseURL, charityNum)
@@ -190,11 +323,32 @@ func (c *Client) FetchFinancialHistory(ctx context.Context, charityNum int) ([]m
 }
 
 // doRequest executes an HTTP request with retry logic and rate limiting.
+// Each attempt is bounded by the smaller of ctx's deadline and the client timeout,
+// and a retry that could not start before the deadline fails immediately rather
+// than sleeping until the context expires. Errors wrap ErrNotFound, ErrRateLimited
+// or ErrServerError according to the API's last response, for errors.Is, including
+// when the retries are cut short by the circuit breaker, retry budget or ctx.
 func (c *Client) doRequest(ctx context.Context, url string, result any) error {
 	var lastErr error
 	var currentKey string
 
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		// Don't touch the network while the API is known to be down
+		if c.breaker != nil && !c.breaker.Allow() {
+			if lastErr == nil {
+				return ErrCircuitOpen
+			}
+			return fmt.Errorf("%w: %w", ErrCircuitOpen, lastErr)
+		}
+
+		// Retries draw from the client-wide budget so an outage fails fast
+		if attempt > 0 && c.retryBudget != nil && !c.retryBudget.Allow() {
+			if lastErr == nil {
+				return ErrRetryBudgetExhausted
+			}
+			return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, lastErr)
+		}
+
 		// Get API key for this attempt (might rotate on retry)
 		currentKey = c.getNextAPIKey()
 
@@ -212,11 +366,17 @@ func (c *Client) doRequest(ctx context.Context, url string, result any) error {
 				log.Printf("Retry %d/%d after %v (using key ...%s)",
 					attempt, c.maxRetries, backoffDuration, currentKey[len(currentKey)-4:])
 			}
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(backoffDuration):
+			if err := sleepCtx(ctx, backoffDuration); err != nil {
+				return fmt.Errorf("%w (last error: %w)", err, lastErr)
+			}
+		}
+
+		// Every request sent, retries included, counts against the call budget
+		if c.callBudget != nil && !c.callBudget.Spend() {
+			if lastErr == nil {
+				return ErrBudgetExhausted
 			}
+			return fmt.Errorf("%w: %w", ErrBudgetExhausted, lastErr)
 		}
 
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -229,15 +389,39 @@ func (c *Client) doRequest(ctx context.Context, url string, result any) error {
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
+			// Retrying can't help once ctx is done
+			if ctx.Err() != nil {
+				if lastErr == nil {
+					return err
+				}
+				return fmt.Errorf("%w (last error: %w)", err, lastErr)
+			}
 			lastErr = err
-			c.recordFailure(currentKey)
+			// A cancelled or expired context says nothing about the API or the key
+			if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+				c.recordFailure(currentKey)
+				c.recordBreaker(false)
+			}
 			continue
 		}
 
+		// Any response below 500 shows the API is reachable
+		c.recordBreaker(resp.StatusCode < 500)
+
 		// Handle response
 		if resp.StatusCode == 200 {
 			defer resp.Body.Close()
-			if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+
+			// Read one byte past the limit so an oversized body can be detected
+			body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxBytes+1))
+			if err != nil {
+				return fmt.Errorf("failed to read response: %w", err)
+			}
+			if int64(len(body)) > c.maxBytes {
+				return fmt.Errorf("%w (limit %d bytes)", ErrResponseTooLarge, c.maxBytes)
+			}
+
+			if err := json.Unmarshal(body, result); err != nil {
 				return fmt.Errorf("failed to decode response: %w", err)
 			}
 			return nil
@@ -246,7 +430,7 @@ func (c *Client) doRequest(ctx context.Context, url string, result any) error {
 		// Handle 404 - resource not found
 		if resp.StatusCode == 404 {
 			resp.Body.Close()
-			return fmt.Errorf("not found (404)")
+			return ErrNotFound
 		}
 
 		// Handle 429 - rate limited (try next key if available)
@@ -269,6 +453,7 @@ func (c *Client) doRequest(ctx context.Context, url string, result any) error {
 			}
 
 			c.recordFailure(currentKey)
+			lastErr = fmt.Errorf("%w: %s", ErrRateLimited, string(body))
 
 			// If we have multiple keys, try the next one immediately
 			if len(c.apiKeys) > 1 && attempt < c.maxRetries {
@@ -278,13 +463,9 @@ func (c *Client) doRequest(ctx context.Context, url string, result any) error {
 				continue
 			}
 
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(waitTime):
+			if err := sleepCtx(ctx, waitTime); err != nil {
+				return fmt.Errorf("%w (last error: %w)", err, lastErr)
 			}
-
-			lastErr = fmt.Errorf("rate limited: %s", string(body))
 			continue
 		}
 
@@ -300,13 +481,10 @@ func (c *Client) doRequest(ctx context.Context, url string, result any) error {
 
 			c.recordFailure(currentKey)
 
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(waitTime):
+			lastErr = fmt.Errorf("%w %d: %s", ErrServerError, resp.StatusCode, string(body))
+			if err := sleepCtx(ctx, waitTime); err != nil {
+				return fmt.Errorf("%w (last error: %w)", err, lastErr)
 			}
-
-			lastErr = fmt.Errorf("server error %d: %s", resp.StatusCode, string(body))
 			continue
 		}
 
@@ -320,6 +498,53 @@ func (c *Client) doRequest(ctx context.Context, url string, result any) error {
 	return fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
+// sleepCtx waits for d, returning early with the context's error if it is
+// cancelled. If ctx's deadline falls before d has elapsed it returns
+// context.DeadlineExceeded straight away, since the caller could not use the
+// result of waiting anyway.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return context.DeadlineExceeded
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// recordBreaker reports a request outcome to the circuit breaker, if enabled.
+func (c *Client) recordBreaker(success bool) {
+	if c.breaker == nil {
+		return
+	}
+	if success {
+		c.breaker.RecordSuccess()
+	} else {
+		c.breaker.RecordFailure()
+	}
+}
+
+// Available reports whether the API is believed reachable, i.e. the circuit
+// breaker is not open. Always true when the breaker is disabled.
+func (c *Client) Available() bool {
+	return c.breaker == nil || !c.breaker.IsOpen()
+}
+
+// GetRetryBudgetStats returns the retries currently available and the number of
+// retries refused because the budget was exhausted. Returns -1 available if the
+// budget is disabled.
+func (c *Client) GetRetryBudgetStats() (available int, refused uint64) {
+	if c.retryBudget == nil {
+		return -1, 0
+	}
+	return c.retryBudget.GetStats()
+}
+
 // recordFailure increments the failure count for a key.
 func (c *Client) recordFailure(apiKey string) {
 	c.mu.RLock()
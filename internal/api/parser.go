@@ -1,12 +1,44 @@
 package api
 
 import (
+	"charitylens/internal/dates"
 	"charitylens/internal/models"
+	"charitylens/internal/validation"
+	"encoding/json"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// ParseFlexibleInt converts a number from an API response to an int. The API
+// returns numbers such as registration numbers as JSON numbers or strings,
+// depending on the endpoint, so this accepts float64 (only whole values), int,
+// int64, json.Number and strings of digits with surrounding whitespace. ok is
+// false for anything else, including nil.
+func ParseFlexibleInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		if n != math.Trunc(n) || math.IsInf(n, 0) {
+			return 0, false
+		}
+		return int(n), true
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case json.Number:
+		return ParseFlexibleInt(n.String())
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			return 0, false
+		}
+		return parsed, true
+	}
+	return 0, false
+}
+
 // ParseCharityData parses charity information from Charity Commission API response.
 func ParseCharityData(data map[string]any, charityNum string) (models.Charity, error) {
 	charity := models.Charity{
@@ -16,17 +48,8 @@ func ParseCharityData(data map[string]any, charityNum string) (models.Charity, e
 	// Parse registered number - prioritize charity number over company number
 	possibleRegNumFields := []string{"reg_charity_number", "organisation_number", "registered_charity_number"}
 	for _, field := range possibleRegNumFields {
-		if rn, ok := data[field]; ok && rn != nil {
-			switch v := rn.(type) {
-			case float64:
-				charity.RegisteredNumber = int(v)
-			case int:
-				charity.RegisteredNumber = v
-			case string:
-				if parsed, err := strconv.Atoi(v); err == nil {
-					charity.RegisteredNumber = parsed
-				}
-			}
+		if rn, ok := ParseFlexibleInt(data[field]); ok && rn != 0 {
+			charity.RegisteredNumber = rn
 			break // Use the first field that works
 		}
 	}
@@ -92,9 +115,9 @@ func ParseCharityData(data map[string]any, charityNum string) (models.Charity, e
 		charity.Email = email
 	}
 
-	// Parse phone
+	// Parse phone, dropping anything that isn't a phone number
 	if phone, ok := data["phone"].(string); ok {
-		charity.Phone = phone
+		charity.Phone, _ = validation.NormalizePhone(phone)
 	}
 
 	// Parse what the charity does (who_what_where might contain this info)
@@ -104,8 +127,13 @@ func ParseCharityData(data map[string]any, charityNum string) (models.Charity, e
 
 	// Parse registration date
 	if regDate, ok := data["date_of_registration"].(string); ok && regDate != "" {
-		if parsed, err := time.Parse("2006-01-02T15:04:05", regDate); err == nil {
-			charity.DateRegistered = parsed
+		charity.DateRegistered = dates.Parse(regDate)
+	}
+
+	// Parse removal date; only set for charities removed from the register
+	if removalDate, ok := data["date_of_removal"].(string); ok && removalDate != "" {
+		if removed := dates.Parse(removalDate); !removed.IsZero() {
+			charity.DateRemoved = &removed
 		}
 	}
 
@@ -129,14 +157,7 @@ func ParseFinancialData(data map[string]any, charityNum int) (models.Financial,
 
 	// Parse financial year end date
 	if yearEndStr, ok := data["latest_acc_fin_year_end_date"].(string); ok && yearEndStr != "" {
-		if parsed, err := time.Parse("2006-01-02T15:04:05", yearEndStr); err == nil {
-			fin.FinancialYearEnd = parsed
-		} else {
-			// Try different date format
-			if parsed, err := time.Parse("2006-01-02", yearEndStr); err == nil {
-				fin.FinancialYearEnd = parsed
-			}
-		}
+		fin.FinancialYearEnd = dates.Parse(yearEndStr)
 	}
 
 	// Note: The basic API doesn't provide spending breakdown (charitable vs fundraising vs admin)
@@ -179,6 +200,19 @@ func ParseTrusteesData(data map[string]any, charityNum int) []models.Trustee {
 		// Handle different possible formats
 		switch v := trusteeNames.(type) {
 		case string:
+			// The V2 API sometimes returns the trustee array as a JSON-encoded string
+			if trimmed := strings.TrimSpace(v); strings.HasPrefix(trimmed, "[") {
+				var nested []map[string]any
+				if err := json.Unmarshal([]byte(trimmed), &nested); err == nil {
+					for _, trusteeMap := range nested {
+						if trustee, ok := parseTrusteeMap(trusteeMap, charityNum); ok {
+							trustees = append(trustees, trustee)
+						}
+					}
+					return trustees
+				}
+			}
+
 			if v != "" {
 				// Trustee names might be comma-separated, semicolon-separated, or pipe-separated
 				separators := []string{",", ";", "|", "\n"}
@@ -211,12 +245,8 @@ func ParseTrusteesData(data map[string]any, charityNum int) []models.Trustee {
 			// If it's an array of objects (V2 API format)
 			for _, item := range v {
 				if trusteeMap, ok := item.(map[string]any); ok {
-					if name, ok := trusteeMap["trustee_name"].(string); ok && name != "" {
-						trustees = append(trustees, models.Trustee{
-							CharityNumber: charityNum,
-							Name:          name,
-							LastUpdated:   time.Now(),
-						})
+					if trustee, ok := parseTrusteeMap(trusteeMap, charityNum); ok {
+						trustees = append(trustees, trustee)
 					}
 				}
 			}
@@ -225,3 +255,17 @@ func ParseTrusteesData(data map[string]any, charityNum int) []models.Trustee {
 
 	return trustees
 }
+
+// parseTrusteeMap extracts a trustee from a V2 API trustee object.
+// Returns false if the object has no trustee name.
+func parseTrusteeMap(trusteeMap map[string]any, charityNum int) (models.Trustee, bool) {
+	name, ok := trusteeMap["trustee_name"].(string)
+	if !ok || strings.TrimSpace(name) == "" {
+		return models.Trustee{}, false
+	}
+	return models.Trustee{
+		CharityNumber: charityNum,
+		Name:          strings.TrimSpace(name),
+		LastUpdated:   time.Now(),
+	}, true
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// loadFixture decodes a saved API response from testdata
+func loadFixture(t *testing.T, name string) map[string]any {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	var data map[string]any
+	if err := json.Unmarshal(body, &data); err != nil {
+		t.Fatalf("decoding fixture: %v", err)
+	}
+	return data
+}
+
+func TestParseTrusteesData(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []string
+	}{
+		// An array of trustee objects; names are trimmed and empty ones skipped
+		{"trustees_array.json", []string{"Jane Smith", "John Doe"}},
+		// The same array, JSON-encoded into a string
+		{"trustees_json_string.json", []string{"Jane Smith", "John Doe"}},
+		// A separated list of names; generic "trustee" entries are dropped
+		{"trustees_separated.json", []string{"Jane Smith", "John Doe"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			trustees := ParseTrusteesData(loadFixture(t, tt.fixture), 1000)
+
+			var names []string
+			for _, trustee := range trustees {
+				if trustee.CharityNumber != 1000 {
+					t.Errorf("trustee %q has charity number %d, want 1000", trustee.Name, trustee.CharityNumber)
+				}
+				names = append(names, trustee.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("names = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrusteesDataMissing(t *testing.T) {
+	for _, data := range []map[string]any{
+		{},
+		{"trustee_names": nil},
+		{"trustee_names": ""},
+	} {
+		if trustees := ParseTrusteesData(data, 1000); len(trustees) != 0 {
+			t.Errorf("ParseTrusteesData(%v) = %v, want none", data, trustees)
+		}
+	}
+}
+
+func TestParseFlexibleInt(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  any
+		want   int
+		wantOK bool
+	}{
+		{"string", "1000", 1000, true},
+		{"padded string", "  1000\n", 1000, true},
+		{"negative string", "-5", -5, true},
+		{"empty string", "", 0, false},
+		{"decimal string", "10.5", 0, false},
+		{"word", "abc", 0, false},
+		{"float64", 1000.0, 1000, true},
+		{"fractional float64", 10.5, 0, false},
+		{"infinite float64", math.Inf(1), 0, false},
+		{"int", 1000, 1000, true},
+		{"int64", int64(1000), 1000, true},
+		{"json.Number", json.Number("1000"), 1000, true},
+		{"decimal json.Number", json.Number("10.5"), 0, false},
+		{"nil", nil, 0, false},
+		{"bool", true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseFlexibleInt(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParseFlexibleInt(%#v) = %d, %v; want %d, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"math"
 	"sync"
 	"time"
 )
@@ -18,10 +19,23 @@ type RateLimiter struct {
 
 // NewRateLimiter creates a new rate limiter with the specified requests per second.
 func NewRateLimiter(requestsPerSecond int) *RateLimiter {
+	return NewRateLimiterFloat(float64(requestsPerSecond))
+}
+
+// NewRateLimiterFloat creates a rate limiter allowing a fractional number of
+// requests per second, e.g. 0.5 for one request every two seconds. The bucket
+// holds at least one token so rates below 1 still allow a request as soon as
+// one is due. Non-positive rates fall back to 1 request per second.
+func NewRateLimiterFloat(requestsPerSecond float64) *RateLimiter {
+	if requestsPerSecond <= 0 || math.IsNaN(requestsPerSecond) || math.IsInf(requestsPerSecond, 0) {
+		requestsPerSecond = 1
+	}
+
+	maxTokens := max(1, int(requestsPerSecond))
 	return &RateLimiter{
-		tokens:         requestsPerSecond,
-		maxTokens:      requestsPerSecond,
-		refillInterval: time.Second / time.Duration(requestsPerSecond),
+		tokens:         maxTokens,
+		maxTokens:      maxTokens,
+		refillInterval: max(time.Nanosecond, time.Duration(float64(time.Second)/requestsPerSecond)),
 		lastRefill:     time.Now(),
 		requestHistory: make([]time.Time, 0, 100),
 	}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiterFloat(t *testing.T) {
+	tests := []struct {
+		rate           float64
+		maxTokens      int
+		refillInterval time.Duration
+	}{
+		{0.5, 1, 2 * time.Second},
+		{0.1, 1, 10 * time.Second},
+		{1, 1, time.Second},
+		{2.5, 2, 400 * time.Millisecond},
+		{10, 10, 100 * time.Millisecond},
+		{0, 1, time.Second},
+		{-1, 1, time.Second},
+		{math.NaN(), 1, time.Second},
+		{math.Inf(1), 1, time.Second},
+	}
+
+	for _, tt := range tests {
+		rl := NewRateLimiterFloat(tt.rate)
+		if rl.maxTokens != tt.maxTokens || rl.tokens != tt.maxTokens {
+			t.Errorf("NewRateLimiterFloat(%v): bucket holds %d of %d tokens, want %d", tt.rate, rl.tokens, rl.maxTokens, tt.maxTokens)
+		}
+		if rl.refillInterval != tt.refillInterval {
+			t.Errorf("NewRateLimiterFloat(%v): refill interval %v, want %v", tt.rate, rl.refillInterval, tt.refillInterval)
+		}
+	}
+}
+
+func TestRateLimiterBelowOnePerSecond(t *testing.T) {
+	for _, rate := range []float64{0.5, 0.1} {
+		rl := NewRateLimiterFloat(rate)
+
+		// The first request is allowed straight away
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		if err := rl.Wait(ctx); err != nil {
+			t.Errorf("rate %v: first Wait = %v, want nil", rate, err)
+		}
+		cancel()
+
+		// The next has to wait a whole refill interval, far longer than the context
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		if err := rl.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("rate %v: second Wait = %v, want %v", rate, err, context.DeadlineExceeded)
+		}
+		cancel()
+	}
+}
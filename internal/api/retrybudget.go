@@ -0,0 +1,64 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryBudget implements a token bucket that caps the number of retries a client
+// may perform across all requests, so widespread failures fail fast instead of
+// every request exhausting its own retry allowance.
+type RetryBudget struct {
+	tokens     float64
+	maxTokens  float64
+	refillRate float64 // tokens per second
+	lastRefill time.Time
+	exhausted  uint64
+	mu         sync.Mutex
+}
+
+// NewRetryBudget creates a retry budget allowing the given number of retries per minute.
+func NewRetryBudget(retriesPerMinute int) *RetryBudget {
+	return &RetryBudget{
+		tokens:     float64(retriesPerMinute),
+		maxTokens:  float64(retriesPerMinute),
+		refillRate: float64(retriesPerMinute) / 60,
+		lastRefill: time.Now(),
+	}
+}
+
+// Allow consumes a retry token if one is available.
+// Returns false if the budget is exhausted.
+func (rb *RetryBudget) Allow() bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	// Refill tokens based on time elapsed
+	now := time.Now()
+	rb.tokens = minFloat(rb.maxTokens, rb.tokens+now.Sub(rb.lastRefill).Seconds()*rb.refillRate)
+	rb.lastRefill = now
+
+	if rb.tokens < 1 {
+		rb.exhausted++
+		return false
+	}
+
+	rb.tokens--
+	return true
+}
+
+// GetStats returns the number of retries currently available and how many
+// retries have been refused because the budget was exhausted.
+func (rb *RetryBudget) GetStats() (available int, refused uint64) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	return int(rb.tokens), rb.exhausted
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
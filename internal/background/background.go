@@ -0,0 +1,68 @@
+// Package background bounds the work the server runs outside of requests, such as
+// the syncs and score calculations searches trigger, so a burst of traffic can't
+// start an unbounded number of goroutines.
+package background
+
+import (
+	"sync"
+
+	"charitylens/internal/logger"
+)
+
+// DefaultLimit is how many tasks may run at once until SetLimit is called
+const DefaultLimit = 64
+
+var (
+	mu       sync.Mutex
+	limit    = DefaultLimit
+	inFlight int
+	rejected int64
+)
+
+// Stats is a snapshot of the background work
+type Stats struct {
+	InFlight int   `json:"in_flight"` // Tasks running now
+	Limit    int   `json:"limit"`     // Most tasks allowed to run at once
+	Rejected int64 `json:"rejected"`  // Tasks not started because the limit was reached
+}
+
+// SetLimit sets how many tasks may run at once, at least 1. Tasks already running
+// are unaffected, so it's meant to be called once at startup.
+func SetLimit(n int) {
+	mu.Lock()
+	defer mu.Unlock()
+	limit = max(n, 1)
+}
+
+// Go runs task in a new goroutine if fewer than the limit are running, and
+// reports whether it did. Otherwise the task is dropped and logged under name:
+// background work is best effort, and is triggered again by the next request
+// that needs it.
+func Go(name string, task func()) bool {
+	mu.Lock()
+	if inFlight >= limit {
+		rejected++
+		mu.Unlock()
+		logger.Warn("Background work limit reached, task dropped", "operation", "background", "task", name, "limit", limit)
+		return false
+	}
+	inFlight++
+	mu.Unlock()
+
+	go func() {
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		task()
+	}()
+	return true
+}
+
+// CurrentStats returns how much background work is running
+func CurrentStats() Stats {
+	mu.Lock()
+	defer mu.Unlock()
+	return Stats{InFlight: inFlight, Limit: limit, Rejected: rejected}
+}
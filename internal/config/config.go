@@ -3,33 +3,163 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"charitylens/internal/background"
+	"charitylens/internal/scoring"
 )
 
 type Config struct {
 	DatabaseType      string
 	DatabaseURL       string
+	DatabaseReadURL   string // Optional read replica, same DATABASE_TYPE as DatabaseURL
 	Port              string
 	BindIP            string
 	CharityAPIKey     string
+	CharityAPIKeys    []string // All API keys for load balancing (includes CharityAPIKey)
 	AdminAPIKey       string
 	SyncIntervalHours int
+	SyncRateLimit     float64 // Requests per second to the Charity Commission API, may be fractional
 	EnableSyncWorker  bool
 	OfflineMode       bool
 	Debug             bool
+
+	// DatabaseConnectTimeout is how long startup keeps retrying a MySQL or
+	// Postgres database that isn't accepting connections yet (0 = try once)
+	DatabaseConnectTimeout time.Duration
+
+	// EnableSearchSideEffects lets searches query the live API and queue background
+	// syncs and score calculations; when false, search only reads the database
+	EnableSearchSideEffects bool
+
+	// SearchAPITimeout is how long a search waits for the live API before serving
+	// database results, letting the API search finish in the background (0 = no limit)
+	SearchAPITimeout time.Duration
+
+	// SearchMinQueryLength is the fewest characters a name search may have, as
+	// shorter ones match most of the register (at least 1)
+	SearchMinQueryLength int
+
+	// Rendered charity page cache (disabled by default)
+	PageCacheEnabled    bool
+	PageCacheTTL        time.Duration
+	PageCacheOfflineTTL time.Duration // Used instead of PageCacheTTL in offline mode, where data only changes on redeploy
+	PageCacheSize       int           // Maximum number of pages held
+
+	// DataMaxAge is how old the bulk data extract can get before /health/data reports it stale
+	DataMaxAge time.Duration
+
+	// Logging: LogFormat is "text" or "json", LogLevel is debug, info, warn or error.
+	// DEBUG=true (or -debug) forces the debug level.
+	LogFormat string
+	LogLevel  string
+
+	// ScoreNeutrals are the scores used for components whose data is missing
+	ScoreNeutrals scoring.Neutrals
+
+	// MaxCompareCharities is the most charities one comparison can include (at least 2)
+	MaxCompareCharities int
+
+	// OfflineDBPath is a pre-seeded SQLite database used instead of DatabaseURL in
+	// offline mode, so it can be shipped as a read-only artifact
+	OfflineDBPath string
+
+	// MaxBackgroundTasks is the most background syncs, searches and score
+	// calculations the server runs at once; more are dropped (at least 1)
+	MaxBackgroundTasks int
+
+	// APIBudgetHourly and APIBudgetDaily are the most Charity Commission API
+	// requests the server makes per clock hour and UTC day (0 = unlimited). Once
+	// either is used up, searches serve database results and charities aren't
+	// synced until it resets.
+	APIBudgetHourly int
+	APIBudgetDaily  int
+
+	// WebhookURL is POSTed a JSON summary after each sync worker cycle, signed
+	// with WebhookSecret if set (see the webhook package)
+	WebhookURL    string
+	WebhookSecret string
+
+	// HiddenStatuses are register statuses left out of search, browsing, stats,
+	// exports and peer comparisons, as well as removed charities (see
+	// database.SetHiddenStatuses)
+	HiddenStatuses []string
+
+	// PublicURL is the site's public base URL, e.g. https://charitylens.org, used
+	// for the absolute links in Open Graph tags. If empty, the tags that need an
+	// absolute link (og:url and the preview image) are left out.
+	PublicURL string
 }
 
 func Load() *Config {
 	cfg := &Config{
 		DatabaseType:      getEnv("DATABASE_TYPE", "sqlite"),
 		DatabaseURL:       getEnv("DATABASE_URL", ""),
+		DatabaseReadURL:   getEnv("DATABASE_READ_URL", ""),
 		Port:              getEnv("PORT", "8080"),
 		BindIP:            getEnv("IP", "0.0.0.0"),
 		CharityAPIKey:     getEnv("CHARITY_API_KEY", ""),
+		CharityAPIKeys:    getEnvList("CHARITY_API_KEYS"),
 		AdminAPIKey:       getEnv("ADMIN_API_KEY", ""),
 		SyncIntervalHours: getEnvInt("SYNC_INTERVAL_HOURS", 24),
+		SyncRateLimit:     getEnvFloat("SYNC_RATE_LIMIT", 10),
 		EnableSyncWorker:  getEnvBool("ENABLE_SYNC_WORKER", false),
 		OfflineMode:       getEnvBool("OFFLINE_MODE", false),
 		Debug:             getEnvBool("DEBUG", false),
+
+		DatabaseConnectTimeout: getEnvDuration("DATABASE_CONNECT_TIMEOUT", 30*time.Second),
+
+		EnableSearchSideEffects: getEnvBool("ENABLE_SEARCH_SIDE_EFFECTS", true),
+
+		SearchAPITimeout: getEnvDuration("SEARCH_API_TIMEOUT", 3*time.Second),
+
+		SearchMinQueryLength: getEnvInt("SEARCH_MIN_QUERY_LENGTH", 3),
+
+		PageCacheEnabled:    getEnvBool("PAGE_CACHE_ENABLED", false),
+		PageCacheTTL:        getEnvDuration("PAGE_CACHE_TTL", time.Minute),
+		PageCacheOfflineTTL: getEnvDuration("PAGE_CACHE_OFFLINE_TTL", 10*time.Minute),
+		PageCacheSize:       getEnvInt("PAGE_CACHE_SIZE", 1000),
+
+		DataMaxAge: getEnvDuration("DATA_MAX_AGE", 30*24*time.Hour),
+
+		LogFormat: getEnv("LOG_FORMAT", "text"),
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+
+		ScoreNeutrals: LoadScoreNeutrals(),
+
+		MaxCompareCharities: getEnvInt("MAX_COMPARE_CHARITIES", 5),
+
+		OfflineDBPath: getEnv("OFFLINE_DB_PATH", ""),
+
+		MaxBackgroundTasks: getEnvInt("MAX_BACKGROUND_TASKS", background.DefaultLimit),
+
+		APIBudgetHourly: getEnvInt("API_BUDGET_HOURLY", 0),
+		APIBudgetDaily:  getEnvInt("API_BUDGET_DAILY", 0),
+
+		WebhookURL:    getEnv("WEBHOOK_URL", ""),
+		WebhookSecret: getEnv("WEBHOOK_SECRET", ""),
+
+		HiddenStatuses: getEnvList("HIDDEN_STATUSES"),
+
+		PublicURL: strings.TrimSuffix(getEnv("PUBLIC_URL", ""), "/"),
+	}
+
+	// Fall back to the single key for backwards compatibility
+	if len(cfg.CharityAPIKeys) == 0 && cfg.CharityAPIKey != "" {
+		cfg.CharityAPIKeys = []string{cfg.CharityAPIKey}
+	}
+	if len(cfg.CharityAPIKeys) > 0 {
+		cfg.CharityAPIKey = cfg.CharityAPIKeys[0]
+	}
+
+	// A comparison always needs at least two charities
+	if cfg.MaxCompareCharities < 2 {
+		cfg.MaxCompareCharities = 2
+	}
+
+	if cfg.SearchMinQueryLength < 1 {
+		cfg.SearchMinQueryLength = 1
 	}
 
 	// Set defaults for database
@@ -66,3 +196,49 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// LoadScoreNeutrals reads the SCORE_NEUTRAL_* overrides of the neutral scores,
+// defaulting to the scoring package's Neutral* constants. The seeder uses it too, so
+// seeded and live scores treat missing data the same way.
+func LoadScoreNeutrals() scoring.Neutrals {
+	return scoring.Neutrals{
+		Efficiency:      getEnvFloat("SCORE_NEUTRAL_EFFICIENCY", scoring.NeutralEfficiency),
+		FinancialHealth: getEnvFloat("SCORE_NEUTRAL_FINANCIAL_HEALTH", scoring.NeutralFinancialHealth),
+		Filing:          getEnvFloat("SCORE_NEUTRAL_FILING", scoring.NeutralFiling),
+		AccountsQuality: getEnvFloat("SCORE_NEUTRAL_ACCOUNTS_QUALITY", scoring.NeutralAccountsQuality),
+
+		Website:            getEnvFloat("SCORE_NEUTRAL_WEBSITE", scoring.NeutralWebsite),
+		WebsiteIncomeLimit: getEnvFloat("SCORE_WEBSITE_INCOME_LIMIT", 0),
+	}
+}
+
+// getEnvFloat parses a number that may be fractional, such as "0.5"
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration parses a duration such as "90s" or "5m"
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList parses a comma-separated environment variable, ignoring empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			values = append(values, trimmed)
+		}
+	}
+	return values
+}
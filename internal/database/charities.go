@@ -0,0 +1,48 @@
+package database
+
+import (
+	"database/sql"
+
+	"charitylens/internal/models"
+)
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// UpsertCharity writes a main charity record fetched from the live API.
+//
+// Charities are keyed on organisation_number, which the API doesn't return, so an
+// existing main record is updated in place by registered number. This keeps a
+// single row per charity across re-syncs, so status changes such as removal from
+// the register (and reinstatement, which clears date_removed) replace what was
+// stored rather than sitting alongside it. A new row is inserted only when the
+// charity isn't stored yet. Either way its details are marked as from the API.
+func UpsertCharity(db execer, charity models.Charity) error {
+	result, err := db.Exec(`
+		UPDATE charities SET
+			company_number = ?, name = ?, name_folded = ?, status = ?, date_registered = ?, date_removed = ?,
+			address = ?, website = ?, email = ?, phone = ?, what_the_charity_does = ?, last_updated = ?,
+			details_source = ?
+		WHERE registered_number = ? AND linked_charity_number = 0
+	`, charity.CompanyNumber, charity.Name, FoldName(charity.Name), charity.Status, charity.DateRegistered, charity.DateRemoved,
+		charity.Address, charity.Website, charity.Email, charity.Phone, charity.WhatTheCharityDoes,
+		charity.LastUpdated, models.SourceAPI, charity.RegisteredNumber)
+	if err != nil {
+		return err
+	}
+	if updated, err := result.RowsAffected(); err != nil || updated > 0 {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO charities
+		(registered_number, company_number, name, name_folded, status, date_registered, date_removed,
+		 address, website, email, phone, what_the_charity_does, last_updated, details_source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, charity.RegisteredNumber, charity.CompanyNumber, charity.Name, FoldName(charity.Name), charity.Status,
+		charity.DateRegistered, charity.DateRemoved, charity.Address, charity.Website,
+		charity.Email, charity.Phone, charity.WhatTheCharityDoes, charity.LastUpdated, models.SourceAPI)
+	return err
+}
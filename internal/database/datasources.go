@@ -0,0 +1,20 @@
+package database
+
+// DataSection is a section of a charity record whose source is tracked, named by
+// its column in charities
+type DataSection string
+
+// Tracked sections of a charity record
+const (
+	DetailsSection    DataSection = "details_source"
+	FinancialsSection DataSection = "financials_source"
+	TrusteesSection   DataSection = "trustees_source"
+)
+
+// SetDataSource records the source (one of the models.Source constants) that last
+// populated a section of a main charity record
+func SetDataSource(db execer, section DataSection, charityNumber int, source string) error {
+	_, err := db.Exec(`UPDATE charities SET `+string(section)+` = ?
+		WHERE registered_number = ? AND linked_charity_number = 0`, source, charityNumber)
+	return err
+}
@@ -4,6 +4,10 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
+
+	"charitylens/internal/logger"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database"
@@ -17,42 +21,103 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+const (
+	// connectBackoff is the delay before retrying a database that refused the
+	// first connection; it doubles each attempt up to maxConnectBackoff
+	connectBackoff    = 500 * time.Millisecond
+	maxConnectBackoff = 5 * time.Second
+)
+
+// connectTimeout is how long to keep retrying a MySQL or Postgres database that
+// isn't accepting connections yet (see SetConnectTimeout)
+var connectTimeout = 30 * time.Second
+
+// SetConnectTimeout sets how long opening a MySQL or Postgres database keeps
+// retrying while it refuses connections, e.g. when it's still starting alongside
+// the app; 0 gives up after the first attempt. SQLite is a local file, so it's
+// never retried. It's meant to be called once at startup, before InitDB.
+func SetConnectTimeout(d time.Duration) {
+	connectTimeout = max(d, 0)
+}
+
 func InitDB() (*sql.DB, error) {
 	dbType := os.Getenv("DATABASE_TYPE")
 	if dbType == "" {
 		dbType = "sqlite"
 	}
 
-	offlineMode := os.Getenv("OFFLINE_MODE") == "true"
+	// In offline mode a separately shipped, pre-seeded database can stand in for
+	// DATABASE_URL
+	url := os.Getenv("DATABASE_URL")
+	if offlinePath := os.Getenv("OFFLINE_DB_PATH"); offlinePath != "" && offlineMode() {
+		url = offlinePath
+	}
+
+	driverName, dataSourceName, err := dataSource(dbType, url)
+	if err != nil {
+		return nil, err
+	}
+	return open(driverName, dataSourceName)
+}
 
-	var driverName, dataSourceName string
+// CheckReadable reports why the database file at path can't be opened for
+// reading, or nil if it can. It's used to fail fast at startup rather than on the
+// first query.
+func CheckReadable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a database file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
 
+// dataSource returns the driver name and DSN for a database of type dbType at url
+func dataSource(dbType, url string) (driverName, dataSourceName string, err error) {
 	switch dbType {
 	case "sqlite":
 		driverName = "sqlite3"
-		dataSourceName = os.Getenv("DATABASE_URL")
+		dataSourceName = url
 		if dataSourceName == "" {
 			dataSourceName = "charitylens.db"
 		}
 		// Add SQLite performance pragmas for read-heavy workload
 		// cache=shared allows multiple connections to share cache
 		// In offline mode, use read-only mode for maximum performance and safety
-		// In online mode, use WAL for write-ahead logging (better concurrency)
-		if offlineMode {
+		// In online mode, use WAL for write-ahead logging (better concurrency), and
+		// wait up to 5s for a competing writer rather than failing with "database is locked"
+		if offlineMode() {
 			dataSourceName += "?cache=shared&mode=ro"
 		} else {
-			dataSourceName += "?cache=shared&_journal_mode=WAL"
+			dataSourceName += "?cache=shared&_journal_mode=WAL&_busy_timeout=5000"
 		}
 	case "mysql":
 		driverName = "mysql"
-		dataSourceName = os.Getenv("DATABASE_URL")
+		dataSourceName = url
 	case "postgres":
 		driverName = "postgres"
-		dataSourceName = os.Getenv("DATABASE_URL")
+		dataSourceName = url
 	default:
-		return nil, fmt.Errorf("unsupported database type: %s", dbType)
+		return "", "", fmt.Errorf("unsupported database type: %s", dbType)
 	}
+	return driverName, dataSourceName, nil
+}
 
+// offlineMode reports whether OFFLINE_MODE is set, parsed the same way as the
+// server's configuration
+func offlineMode() bool {
+	offline, _ := strconv.ParseBool(os.Getenv("OFFLINE_MODE"))
+	return offline
+}
+
+// open opens and pings a connection pool
+func open(driverName, dataSourceName string) (*sql.DB, error) {
 	db, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
 		return nil, err
@@ -65,18 +130,77 @@ func InitDB() (*sql.DB, error) {
 	db.SetConnMaxLifetime(0) // No max lifetime (reuse connections)
 	db.SetConnMaxIdleTime(0) // No max idle time for SQLite file access
 
-	if err := db.Ping(); err != nil {
+	if err := ping(db, driverName); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
 
+// ping checks db is reachable. A network database is retried with backoff for up
+// to connectTimeout, logging each failed attempt, so one that comes up a moment
+// after the app doesn't stop it starting.
+func ping(db *sql.DB, driverName string) error {
+	err := db.Ping()
+	if err == nil || driverName == "sqlite3" || connectTimeout == 0 {
+		return err
+	}
+
+	deadline := time.Now().Add(connectTimeout)
+	delay := connectBackoff
+	attempt := 1
+	for ; err != nil; attempt++ {
+		wait := min(delay, time.Until(deadline))
+		if wait <= 0 {
+			return fmt.Errorf("database unreachable after %d attempts over %v: %w", attempt, connectTimeout, err)
+		}
+		logger.Warn("Database unreachable, retrying", "operation", "database", "attempt", attempt, "retry_in", wait.Round(time.Millisecond), "error", err)
+		time.Sleep(wait)
+		delay = min(delay*2, maxConnectBackoff)
+		err = db.Ping()
+	}
+	logger.Info("Connected to database", "operation", "database", "attempts", attempt)
+	return nil
+}
+
 func Migrate(db *sql.DB) error {
 	return MigrateWithPath(db, "migrations")
 }
 
 func MigrateWithPath(db *sql.DB, migrationsPath string) error {
+	m, err := newMigrate(db, migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	// Get current version to check if migrations are needed
+	version, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return fmt.Errorf("failed to get migration version: %v", err)
+	}
+
+	// Only run migrations if needed (optimizes startup on fly.io)
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("failed to run migrations: %v", err)
+	}
+
+	// Log migration status for debugging
+	newVersion, _, _ := m.Version()
+	if dirty {
+		return fmt.Errorf("database is in dirty state, manual intervention required")
+	}
+	if version != newVersion && newVersion > 0 {
+		// Migrations were applied
+		_ = version // Used for logging in production
+	}
+
+	return nil
+}
+
+// newMigrate returns a migration instance for db, of type DATABASE_TYPE, using
+// the migrations in migrationsPath
+func newMigrate(db *sql.DB, migrationsPath string) (*migrate.Migrate, error) {
 	dbType := os.Getenv("DATABASE_TYPE")
 	if dbType == "" {
 		dbType = "sqlite"
@@ -93,11 +217,11 @@ func MigrateWithPath(db *sql.DB, migrationsPath string) error {
 	case "postgres":
 		driver, err = postgres.WithInstance(db, &postgres.Config{})
 	default:
-		return fmt.Errorf("unsupported database type: %s", dbType)
+		return nil, fmt.Errorf("unsupported database type: %s", dbType)
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to create migration driver: %v", err)
+		return nil, fmt.Errorf("failed to create migration driver: %v", err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
@@ -106,29 +230,7 @@ func MigrateWithPath(db *sql.DB, migrationsPath string) error {
 		driver,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create migration instance: %v", err)
-	}
-
-	// Get current version to check if migrations are needed
-	version, dirty, err := m.Version()
-	if err != nil && err != migrate.ErrNilVersion {
-		return fmt.Errorf("failed to get migration version: %v", err)
-	}
-
-	// Only run migrations if needed (optimizes startup on fly.io)
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to run migrations: %v", err)
-	}
-
-	// Log migration status for debugging
-	newVersion, _, _ := m.Version()
-	if dirty {
-		return fmt.Errorf("database is in dirty state, manual intervention required")
-	}
-	if version != newVersion && newVersion > 0 {
-		// Migrations were applied
-		_ = version // Used for logging in production
+		return nil, fmt.Errorf("failed to create migration instance: %v", err)
 	}
-
-	return nil
+	return m, nil
 }
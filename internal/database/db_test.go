@@ -0,0 +1,30 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// openTestDB returns a migrated SQLite database in a temporary directory, opened
+// the same way InitDB opens one
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	t.Setenv("DATABASE_TYPE", "sqlite")
+	t.Setenv("OFFLINE_MODE", "")
+
+	driverName, dataSourceName, err := dataSource("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := open(driverName, dataSourceName)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := MigrateWithPath(db, "../../migrations"); err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+	return db
+}
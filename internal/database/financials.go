@@ -0,0 +1,32 @@
+package database
+
+// UpsertFinancialSQL writes one financials row, merging with any existing row for
+// the same charity and financial year end.
+//
+// The same year can arrive from several sources (the charity extract, annual return
+// Part B and the live API), each with a different subset of figures. Precedence is
+// per column: a non-zero incoming value replaces the stored one, while a zero or NULL
+// incoming value keeps what is already stored. This stops a sparse source written
+// last from wiping out a richer breakdown written earlier.
+//
+// Arguments: charity_number, financial_year_end, total_income, total_spending,
+// charitable_activities_spend, raising_funds_spend, other_spend, reserves, assets,
+// employees, trustees, last_updated.
+const UpsertFinancialSQL = `
+	INSERT INTO financials
+	(charity_number, financial_year_end, total_income, total_spending,
+	 charitable_activities_spend, raising_funds_spend, other_spend,
+	 reserves, assets, employees, trustees, last_updated)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (charity_number, financial_year_end) DO UPDATE SET
+		total_income = COALESCE(NULLIF(excluded.total_income, 0), financials.total_income),
+		total_spending = COALESCE(NULLIF(excluded.total_spending, 0), financials.total_spending),
+		charitable_activities_spend = COALESCE(NULLIF(excluded.charitable_activities_spend, 0), financials.charitable_activities_spend),
+		raising_funds_spend = COALESCE(NULLIF(excluded.raising_funds_spend, 0), financials.raising_funds_spend),
+		other_spend = COALESCE(NULLIF(excluded.other_spend, 0), financials.other_spend),
+		reserves = COALESCE(NULLIF(excluded.reserves, 0), financials.reserves),
+		assets = COALESCE(NULLIF(excluded.assets, 0), financials.assets),
+		employees = COALESCE(NULLIF(excluded.employees, 0), financials.employees),
+		trustees = COALESCE(NULLIF(excluded.trustees, 0), financials.trustees),
+		last_updated = excluded.last_updated
+`
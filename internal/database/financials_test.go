@@ -0,0 +1,89 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestUpsertFinancialSQL(t *testing.T) {
+	db := openTestDB(t)
+	yearEnd := time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	// upsert writes the year's figures, in UpsertFinancialSQL's order from
+	// total_income to trustees
+	upsert := func(figures ...any) {
+		t.Helper()
+		args := append([]any{1000, yearEnd}, figures...)
+		if _, err := db.Exec(UpsertFinancialSQL, append(args, time.Now())...); err != nil {
+			t.Fatalf("upserting financials: %v", err)
+		}
+	}
+
+	type row struct {
+		income, spending, charitable, raising, other, reserves, assets sql.NullFloat64
+		employees, trustees                                            sql.NullInt64
+	}
+	load := func() row {
+		t.Helper()
+		var r row
+		if err := db.QueryRow(`
+			SELECT total_income, total_spending, charitable_activities_spend, raising_funds_spend,
+			       other_spend, reserves, assets, employees, trustees
+			FROM financials WHERE charity_number = 1000
+		`).Scan(&r.income, &r.spending, &r.charitable, &r.raising, &r.other,
+			&r.reserves, &r.assets, &r.employees, &r.trustees); err != nil {
+			t.Fatalf("loading financials: %v", err)
+		}
+		return r
+	}
+
+	// A full breakdown, e.g. from annual return Part B
+	upsert(1000.0, 800.0, 600.0, 150.0, 50.0, 300.0, 2000.0, 4, 7)
+
+	// A sparser source for the same year with zeros and NULLs where it has no
+	// figure, and a new total income; the zeros and NULLs mustn't overwrite
+	upsert(1200.0, 0.0, 0.0, nil, 0.0, nil, 0.0, 0, nil)
+
+	got := load()
+	want := row{
+		income:     sql.NullFloat64{Float64: 1200, Valid: true},
+		spending:   sql.NullFloat64{Float64: 800, Valid: true},
+		charitable: sql.NullFloat64{Float64: 600, Valid: true},
+		raising:    sql.NullFloat64{Float64: 150, Valid: true},
+		other:      sql.NullFloat64{Float64: 50, Valid: true},
+		reserves:   sql.NullFloat64{Float64: 300, Valid: true},
+		assets:     sql.NullFloat64{Float64: 2000, Valid: true},
+		employees:  sql.NullInt64{Int64: 4, Valid: true},
+		trustees:   sql.NullInt64{Int64: 7, Valid: true},
+	}
+	if got != want {
+		t.Errorf("after sparse upsert got %+v, want %+v", got, want)
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM financials WHERE charity_number = 1000`).Scan(&count)
+	if count != 1 {
+		t.Errorf("got %d rows for the year, want 1", count)
+	}
+}
+
+func TestUpsertFinancialSQLZeroOnInsert(t *testing.T) {
+	db := openTestDB(t)
+
+	// With nothing stored, a zero is written as is rather than dropped
+	if _, err := db.Exec(UpsertFinancialSQL,
+		1000, time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC),
+		0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0, 0, time.Now(),
+	); err != nil {
+		t.Fatalf("upserting financials: %v", err)
+	}
+
+	var income sql.NullFloat64
+	if err := db.QueryRow(`SELECT total_income FROM financials WHERE charity_number = 1000`).Scan(&income); err != nil {
+		t.Fatal(err)
+	}
+	if !income.Valid || income.Float64 != 0 {
+		t.Errorf("total_income = %+v, want 0", income)
+	}
+}
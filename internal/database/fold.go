@@ -0,0 +1,53 @@
+package database
+
+import "strings"
+
+// foldedLetters maps the accented Latin letters found in charity names to the
+// ASCII letters they're folded to, in lower and upper case
+var foldedLetters = map[string]string{
+	"a": "àáâãäåāăą", "A": "ÀÁÂÃÄÅĀĂĄ",
+	"c": "çćĉċč", "C": "ÇĆĈĊČ",
+	"d": "ďđð", "D": "ĎĐÐ",
+	"e": "èéêëēĕėęě", "E": "ÈÉÊËĒĔĖĘĚ",
+	"g": "ĝğġģ", "G": "ĜĞĠĢ",
+	"h": "ĥħ", "H": "ĤĦ",
+	"i": "ìíîïĩīĭįı", "I": "ÌÍÎÏĨĪĬĮİ",
+	"j": "ĵ", "J": "Ĵ",
+	"k": "ķ", "K": "Ķ",
+	"l": "ĺļľŀł", "L": "ĹĻĽĿŁ",
+	"n": "ñńņň", "N": "ÑŃŅŇ",
+	"o": "òóôõöøōŏő", "O": "ÒÓÔÕÖØŌŎŐ",
+	"r": "ŕŗř", "R": "ŔŖŘ",
+	"s": "śŝşš", "S": "ŚŜŞŠ",
+	"t": "ţťŧ", "T": "ŢŤŦ",
+	"u": "ùúûüũūŭůűų", "U": "ÙÚÛÜŨŪŬŮŰŲ",
+	"w": "ŵ", "W": "Ŵ",
+	"y": "ýÿŷ", "Y": "ÝŸŶ",
+	"z": "źżž", "Z": "ŹŻŽ",
+	"ss": "ß", "ae": "æÆ", "oe": "œŒ", "th": "þÞ",
+}
+
+// nameFolder replaces each letter in foldedLetters with its ASCII folding
+var nameFolder = func() *strings.Replacer {
+	var pairs []string
+	for ascii, letters := range foldedLetters {
+		for _, letter := range letters {
+			pairs = append(pairs, string(letter), ascii)
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// FoldName normalises a charity name, or a search for one, so that they match
+// regardless of case and accents: accented Latin letters are folded to ASCII
+// ("Société" to "societe", "Straße" to "strasse") and ASCII letters lowercased,
+// as the migration that added charities.name_folded does. Other characters are
+// kept as they are.
+func FoldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, nameFolder.Replace(name))
+}
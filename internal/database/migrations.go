@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Migration is one migration in the migrations directory
+type Migration struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"` // e.g. "add_charity_name_folded"
+}
+
+// MigrationStatus describes a database's schema relative to the migrations
+// directory
+type MigrationStatus struct {
+	Version uint        `json:"version"` // 0 if no migrations have been applied
+	Dirty   bool        `json:"dirty"`   // A migration failed part way through
+	Latest  uint        `json:"latest"`  // The newest migration's version
+	Pending []Migration `json:"pending"` // Migrations newer than Version, oldest first
+}
+
+// MigrationStatusWithPath reports db's schema version and the migrations in
+// migrationsPath not yet applied to it, without changing anything
+func MigrationStatusWithPath(db *sql.DB, migrationsPath string) (MigrationStatus, error) {
+	m, err := newMigrate(db, migrationsPath)
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+
+	var status MigrationStatus
+	status.Version, status.Dirty, err = m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return status, fmt.Errorf("failed to get migration version: %v", err)
+	}
+
+	migrations, err := listMigrations(migrationsPath)
+	if err != nil {
+		return status, err
+	}
+	status.Pending = []Migration{}
+	for _, migration := range migrations {
+		status.Latest = migration.Version
+		if migration.Version > status.Version {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// MigrateDownWithPath rolls back the last steps migrations applied to db. A
+// dirty database is refused: the failed migration needs fixing by hand first.
+func MigrateDownWithPath(db *sql.DB, migrationsPath string, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("invalid number of migrations to roll back: %d", steps)
+	}
+
+	m, err := newMigrate(db, migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if err == migrate.ErrNilVersion {
+		return fmt.Errorf("no migrations have been applied")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get migration version: %v", err)
+	}
+	if dirty {
+		return fmt.Errorf("database is in dirty state at version %d, manual intervention required", version)
+	}
+
+	if err := m.Steps(-steps); err != nil {
+		return fmt.Errorf("failed to roll back migrations: %v", err)
+	}
+	return nil
+}
+
+// listMigrations returns the migrations in migrationsPath, oldest first
+func listMigrations(migrationsPath string) ([]Migration, error) {
+	src, err := source.Open("file://" + migrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %v", err)
+	}
+	defer src.Close()
+
+	var migrations []Migration
+	version, err := src.First()
+	for err == nil {
+		migration := Migration{Version: version}
+		if r, name, readErr := src.ReadUp(version); readErr == nil {
+			r.Close()
+			migration.Name = name
+		}
+		migrations = append(migrations, migration)
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read migrations: %v", err)
+	}
+	return migrations, nil
+}
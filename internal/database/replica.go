@@ -0,0 +1,71 @@
+package database
+
+import (
+	"database/sql"
+	"os"
+)
+
+// DB pairs the primary database with an optional read replica. Queries that
+// only read and can tolerate replication lag, such as search and charity
+// details, use Reader; writes and anything that must see its own writes use
+// Writer. Without a replica both return the primary.
+type DB struct {
+	writer *sql.DB
+	reader *sql.DB
+}
+
+// NewDB creates a DB that writes to writer and reads from reader, or from writer
+// too if reader is nil
+func NewDB(writer, reader *sql.DB) *DB {
+	if reader == nil {
+		reader = writer
+	}
+	return &DB{writer: writer, reader: reader}
+}
+
+// Reader returns the connection pool for read-only queries
+func (d *DB) Reader() *sql.DB {
+	return d.reader
+}
+
+// Writer returns the primary connection pool
+func (d *DB) Writer() *sql.DB {
+	return d.writer
+}
+
+// HasReplica reports whether reads go to a separate replica
+func (d *DB) HasReplica() bool {
+	return d.reader != d.writer
+}
+
+// Close closes the replica, if any, and the primary
+func (d *DB) Close() error {
+	if d.HasReplica() {
+		d.reader.Close()
+	}
+	return d.writer.Close()
+}
+
+// InitReadDB opens the read replica at readURL, a DSN for the same DATABASE_TYPE
+// as the primary. It returns nil without error if readURL is empty. Migrations
+// are never run against it; it's expected to follow the primary's schema.
+func InitReadDB(readURL string) (*sql.DB, error) {
+	if readURL == "" {
+		return nil, nil
+	}
+
+	dbType := os.Getenv("DATABASE_TYPE")
+	if dbType == "" {
+		dbType = "sqlite"
+	}
+
+	driverName, dataSourceName, err := dataSource(dbType, readURL)
+	if err != nil {
+		return nil, err
+	}
+	// A SQLite replica (e.g. a LiteFS or Litestream copy) is only ever read
+	if dbType == "sqlite" {
+		dataSourceName = readURL + "?cache=shared&mode=ro"
+	}
+	return open(driverName, dataSourceName)
+}
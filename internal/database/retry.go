@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	sqlitedriver "github.com/mattn/go-sqlite3"
+)
+
+const (
+	// busyRetries is how many times a write is retried while SQLite reports the
+	// database as busy, on top of the wait set by _busy_timeout
+	busyRetries = 5
+
+	// busyBackoff is the delay before the first retry; it doubles each attempt
+	busyBackoff = 50 * time.Millisecond
+)
+
+// IsBusy reports whether err is SQLite's SQLITE_BUSY or SQLITE_LOCKED ("database
+// is locked"), which clears once the competing writer finishes
+func IsBusy(err error) bool {
+	var sqliteErr sqlitedriver.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlitedriver.ErrBusy || sqliteErr.Code == sqlitedriver.ErrLocked
+	}
+	return false
+}
+
+// RetryBusy runs fn, retrying with exponential backoff while it fails because the
+// database is busy. fn must be safe to repeat: a single statement, or a whole
+// transaction that is rolled back on error.
+func RetryBusy(fn func() error) error {
+	delay := busyBackoff
+	err := fn()
+	for attempt := 1; attempt <= busyRetries && IsBusy(err); attempt++ {
+		time.Sleep(delay)
+		delay *= 2
+		err = fn()
+	}
+	return err
+}
+
+// RetryBusyContext is RetryBusy, but stops retrying once ctx is done, so the
+// backoff between attempts can't outlast the caller's deadline
+func RetryBusyContext(ctx context.Context, fn func() error) error {
+	delay := busyBackoff
+	err := fn()
+	for attempt := 1; attempt <= busyRetries && IsBusy(err); attempt++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		err = fn()
+	}
+	return err
+}
+
+// ExecRetry is db.Exec, retried while the database is busy
+func ExecRetry(db *sql.DB, query string, args ...any) (sql.Result, error) {
+	return ExecRetryContext(context.Background(), db, query, args...)
+}
+
+// ExecRetryContext is db.ExecContext, retried while the database is busy and ctx
+// isn't done
+func ExecRetryContext(ctx context.Context, db *sql.DB, query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := RetryBusyContext(ctx, func() error {
+		var err error
+		result, err = db.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// sqliteWriteMu serializes the writes made through WithWriteLock on SQLite
+var sqliteWriteMu sync.Mutex
+
+// IsSQLite reports whether db uses the SQLite driver
+func IsSQLite(db *sql.DB) bool {
+	_, ok := db.Driver().(*sqlitedriver.SQLiteDriver)
+	return ok
+}
+
+// WithWriteLock runs fn while holding a process-wide write lock if db is SQLite,
+// which only allows one writer at a time, so concurrent writers queue in Go rather
+// than thrashing on SQLite's lock. Postgres and MySQL handle concurrent writes
+// themselves, so fn runs straight away.
+func WithWriteLock(db *sql.DB, fn func() error) error {
+	if IsSQLite(db) {
+		sqliteWriteMu.Lock()
+		defer sqliteWriteMu.Unlock()
+	}
+	return fn()
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	stdsync "sync"
+)
+
+// The register's statuses, as the bulk extract and the API spell them. A charity
+// is either registered or removed; other conditions, such as being in default on
+// its filings or in administration, are recorded separately and don't change its
+// status. Statuses are compared case-sensitively.
+//
+//	Registered, R   on the register: listed, scored and shown
+//	Removed, RM     removed from the register: never listed or scored, and the
+//	                charity page explains the removal
+//
+// Statuses outside this set, and charities without one, are treated as
+// registered. SetHiddenStatuses can leave more statuses out of listings.
+var (
+	RegisteredStatuses = []string{"Registered", "R"}
+	RemovedStatuses    = []string{"Removed", "RM"}
+)
+
+var (
+	statusMu       stdsync.RWMutex
+	hiddenStatuses []string
+)
+
+// SetHiddenStatuses leaves charities with any of statuses out of listings
+// (search, browsing, stats, exports and peer comparisons) as well as removed
+// ones. They're still scored, and their pages still shown. It's meant to be
+// called once at startup.
+func SetHiddenStatuses(statuses []string) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	hiddenStatuses = slices.Clone(statuses)
+}
+
+// IsRemoved reports whether a status means the charity has been removed from the
+// register
+func IsRemoved(status string) bool {
+	return slices.Contains(RemovedStatuses, status)
+}
+
+// IsListed reports whether charities with status appear in listings: they
+// haven't been removed and the status isn't hidden
+func IsListed(status string) bool {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	return !IsRemoved(status) && !slices.Contains(hiddenStatuses, status)
+}
+
+// NotRemovedSQL returns a condition on a status column that holds for charities
+// still on the register, e.g. NotRemovedSQL("c.status"). It's used for scoring
+// and charity pages.
+func NotRemovedSQL(column string) string {
+	return statusNotIn(column, RemovedStatuses)
+}
+
+// ListedSQL returns a condition on a status column that holds for the charities
+// IsListed accepts, e.g. ListedSQL("c.status"). It's used for every listing.
+func ListedSQL(column string) string {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	return statusNotIn(column, append(slices.Clone(RemovedStatuses), hiddenStatuses...))
+}
+
+// statusNotIn returns a condition that column isn't one of statuses, with a
+// missing status treated as an empty one so those charities aren't dropped
+func statusNotIn(column string, statuses []string) string {
+	quoted := make([]string, len(statuses))
+	for i, status := range statuses {
+		quoted[i] = "'" + strings.ReplaceAll(status, "'", "''") + "'"
+	}
+	return "COALESCE(" + column + ", '') NOT IN (" + strings.Join(quoted, ", ") + ")"
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestStatuses(t *testing.T) {
+	SetHiddenStatuses([]string{"Insolvent", "O'Brien"})
+	t.Cleanup(func() { SetHiddenStatuses(nil) })
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		status  any // nil for a missing status
+		removed bool
+		listed  bool
+	}{
+		{"Registered", false, true},
+		{"R", false, true},
+		{"Removed", true, false},
+		{"RM", true, false},
+		{"removed", false, true}, // Statuses are case-sensitive
+		{"", false, true},
+		{nil, false, true},
+		{"Insolvent", false, false},
+		{"O'Brien", false, false},
+		{"Other", false, true},
+	}
+
+	for _, tt := range tests {
+		status, _ := tt.status.(string)
+		if got := IsRemoved(status); got != tt.removed {
+			t.Errorf("IsRemoved(%q) = %v, want %v", status, got, tt.removed)
+		}
+		if got := IsListed(status); got != tt.listed {
+			t.Errorf("IsListed(%q) = %v, want %v", status, got, tt.listed)
+		}
+
+		var notRemoved, listed bool
+		if err := db.QueryRow(`SELECT `+NotRemovedSQL("?")+`, `+ListedSQL("?"), tt.status, tt.status).Scan(&notRemoved, &listed); err != nil {
+			t.Fatalf("evaluating conditions for %v: %v", tt.status, err)
+		}
+		if notRemoved == tt.removed {
+			t.Errorf("NotRemovedSQL for %v = %v, want %v", tt.status, notRemoved, !tt.removed)
+		}
+		if listed != tt.listed {
+			t.Errorf("ListedSQL for %v = %v, want %v", tt.status, listed, tt.listed)
+		}
+	}
+}
+
+func TestSetHiddenStatusesReplaces(t *testing.T) {
+	t.Cleanup(func() { SetHiddenStatuses(nil) })
+
+	SetHiddenStatuses([]string{"Insolvent"})
+	SetHiddenStatuses([]string{"Dormant"})
+	if !IsListed("Insolvent") || IsListed("Dormant") {
+		t.Errorf("IsListed after replacing hidden statuses: Insolvent %v, Dormant %v; want true, false",
+			IsListed("Insolvent"), IsListed("Dormant"))
+	}
+
+	SetHiddenStatuses(nil)
+	if want := `COALESCE(c.status, '') NOT IN ('Removed', 'RM')`; ListedSQL("c.status") != want {
+		t.Errorf("ListedSQL without hidden statuses = %q, want %q", ListedSQL("c.status"), want)
+	}
+}
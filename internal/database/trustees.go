@@ -0,0 +1,27 @@
+package database
+
+import "strings"
+
+// InsertTrusteeSQL stores a trustee unless the charity already has one with the
+// same normalised name (see TrusteeNameKey), in which case the stored row,
+// including its last_updated, is left alone. RowsAffected is 1 for a new trustee
+// and 0 for one already stored, so re-imports don't churn unchanged rows.
+//
+// Arguments: charity_number, name, name_key, last_updated.
+const InsertTrusteeSQL = `
+	INSERT INTO trustees (charity_number, name, name_key, last_updated)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT DO NOTHING
+`
+
+// TrusteeNameKey normalises a trustee's name to identify them within a charity:
+// whitespace runs are collapsed and trimmed, and ASCII letters lowercased, to
+// match SQLite's LOWER in the migration that added the key.
+func TrusteeNameKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,37 @@
+package dates
+
+import (
+	"strings"
+	"time"
+
+	"charitylens/internal/logger"
+)
+
+// Layouts lists the date formats seen in Charity Commission data (API responses
+// and bulk extracts), most common first. Fractional seconds are accepted by
+// the layouts with a seconds field even though they are not spelled out.
+var Layouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"02/01/2006",
+}
+
+// Parse parses a Charity Commission date string using the shared layout list.
+// Returns the zero time if the value is empty or matches none of the layouts.
+func Parse(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range Layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+
+	logger.Debug("Failed to parse date", "value", value)
+	return time.Time{}
+}
@@ -0,0 +1,36 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{"API timestamp", "2023-03-31T00:00:00", time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"API timestamp with fraction", "2023-03-31T12:30:45.123", time.Date(2023, 3, 31, 12, 30, 45, 123000000, time.UTC)},
+		{"date only", "2023-03-31", time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"RFC 3339", "2023-03-31T12:30:45Z", time.Date(2023, 3, 31, 12, 30, 45, 0, time.UTC)},
+		{"RFC 3339 with offset", "2023-03-31T12:30:45+01:00", time.Date(2023, 3, 31, 11, 30, 45, 0, time.UTC)},
+		{"extract timestamp", "2023-03-31 12:30:45", time.Date(2023, 3, 31, 12, 30, 45, 0, time.UTC)},
+		{"extract timestamp with fraction", "2023-03-31 12:30:45.5", time.Date(2023, 3, 31, 12, 30, 45, 500000000, time.UTC)},
+		{"UK date", "31/03/2023", time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"surrounding whitespace", "  2023-03-31\n", time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"empty", "", time.Time{}},
+		{"blank", "   ", time.Time{}},
+		{"US date", "03/31/2023", time.Time{}},
+		{"not a date", "unknown", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.value); !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
+
+	"charitylens/internal/httpclient"
+	"charitylens/internal/version"
 )
 
 // FileType represents a type of data file to download
@@ -21,17 +26,53 @@ const (
 	FileCharityAnnualReturnA    FileType = "charity_annual_return_parta"
 	FileCharityAnnualReturnB    FileType = "charity_annual_return_partb"
 	FileCharityAnnualReturnHist FileType = "charity_annual_return_history"
+	FileCharityClassification   FileType = "charity_classification"
+	FileCharityEventHistory     FileType = "charity_event_history"
 )
 
 // baseURL is the Azure blob storage URL for Charity Commission data
 const baseURL = "https://ccewuksprdoneregsadata1.blob.core.windows.net/data/json/publicextract.%s.zip"
 
+// defaultMaxDownloadBytes caps the size of a single ZIP download
+const defaultMaxDownloadBytes = 2 * 1024 * 1024 * 1024 // 2GB
+
+// defaultMaxRetryDelay caps the backoff between attempts and any Retry-After wait
+const defaultMaxRetryDelay = 5 * time.Minute
+
+// StatusError is returned when the server answers with a status other than 200
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // From the Retry-After header, 0 if absent
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Temporary reports whether the request may succeed if retried: rate limiting
+// (429) and server errors (5xx). Other 4xx responses such as 403 or 404 won't
+// change on retry.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
 // DownloadedFile represents a file that has been downloaded and extracted in memory
 type DownloadedFile struct {
-	Type     FileType
-	FileName string
-	Data     []byte
-	Size     int64
+	Type           FileType
+	FileName       string
+	Data           []byte
+	Size           int64         // Extracted JSON size
+	CompressedSize int64         // Downloaded ZIP size
+	StartTime      time.Time     // When the download started
+	Duration       time.Duration // Time spent downloading (excludes extraction)
+}
+
+// Throughput returns the download rate in bytes per second
+func (f *DownloadedFile) Throughput() float64 {
+	if f.Duration <= 0 {
+		return 0
+	}
+	return float64(f.CompressedSize) / f.Duration.Seconds()
 }
 
 // Downloader manages downloading and extracting Charity Commission data files
@@ -39,15 +80,28 @@ type Downloader struct {
 	httpClient      *http.Client
 	maxRetries      int
 	retryDelay      time.Duration
+	maxRetryDelay   time.Duration
+	maxBytes        int64
+	userAgent       string
 	progressHandler func(fileType FileType, bytesDownloaded, totalBytes int64)
 }
 
 // Config holds configuration for the downloader
 type Config struct {
-	Timeout         time.Duration
+	Timeout         time.Duration // Overall timeout for a single file download (0 = default of 10 minutes)
 	MaxRetries      int
 	RetryDelay      time.Duration
+	MaxBytes        int64  // Maximum ZIP size per file (0 = default of 2GB)
+	UserAgent       string // User-Agent header sent with downloads (empty = version.UserAgent())
 	ProgressHandler func(fileType FileType, bytesDownloaded, totalBytes int64)
+
+	// MaxRetryDelay caps the backoff between attempts, which starts at RetryDelay
+	// and doubles, and any Retry-After wait the server asks for (0 = default of 5 minutes)
+	MaxRetryDelay time.Duration
+
+	// Transport tunes connection pooling and the response header timeout, which
+	// detects a stalled server long before the overall download timeout
+	Transport httpclient.TransportConfig
 }
 
 // NewDownloader creates a new downloader with the given configuration
@@ -61,29 +115,97 @@ func NewDownloader(config Config) *Downloader {
 	if config.RetryDelay == 0 {
 		config.RetryDelay = 5 * time.Second
 	}
+	if config.MaxRetryDelay == 0 {
+		config.MaxRetryDelay = defaultMaxRetryDelay
+	}
+	if config.MaxBytes == 0 {
+		config.MaxBytes = defaultMaxDownloadBytes
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = version.UserAgent()
+	}
 
 	return &Downloader{
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: httpclient.NewTransport(config.Transport),
 		},
 		maxRetries:      config.MaxRetries,
 		retryDelay:      config.RetryDelay,
+		maxRetryDelay:   config.MaxRetryDelay,
+		maxBytes:        config.MaxBytes,
+		userAgent:       config.UserAgent,
 		progressHandler: config.ProgressHandler,
 	}
 }
 
+// FileInfo describes a data file as reported by the server, without downloading it
+type FileInfo struct {
+	Type         FileType
+	URL          string
+	Size         int64     // ZIP size in bytes, -1 if not reported
+	LastModified time.Time // Zero if not reported
+}
+
+// URL returns the download URL of a data file
+func URL(fileType FileType) string {
+	return fmt.Sprintf(baseURL, string(fileType))
+}
+
+// CheckFile confirms a data file is available with a HEAD request, retried like a
+// download, and returns its size and last-modified time
+func (d *Downloader) CheckFile(ctx context.Context, fileType FileType) (*FileInfo, error) {
+	url := URL(fileType)
+	var info *FileInfo
+
+	err := d.withRetry(ctx, fileType, "Check", func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", d.userAgent)
+
+		resp, err := d.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return &StatusError{
+				StatusCode: resp.StatusCode,
+				RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			}
+		}
+
+		info = &FileInfo{Type: fileType, URL: url, Size: resp.ContentLength}
+		if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+			info.LastModified = modified
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s: %w", fileType, err)
+	}
+	return info, nil
+}
+
 // DownloadFile downloads and extracts a single file in memory
 func (d *Downloader) DownloadFile(ctx context.Context, fileType FileType) (*DownloadedFile, error) {
-	url := fmt.Sprintf(baseURL, string(fileType))
+	url := URL(fileType)
 	log.Printf("Downloading %s from %s", fileType, url)
 
 	// Download the ZIP file with retries
-	zipData, err := d.downloadWithRetry(ctx, url, fileType)
+	startTime := time.Now()
+	zipData, _, err := d.downloadWithRetry(ctx, url, fileType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download %s: %w", fileType, err)
 	}
+	duration := time.Since(startTime)
 
-	log.Printf("Download complete for %s (%d bytes), extracting...", fileType, len(zipData))
+	log.Printf("Download complete for %s (%d bytes in %v, %.2f MB/s), extracting...",
+		fileType, len(zipData), duration.Round(time.Millisecond),
+		float64(len(zipData))/1024/1024/duration.Seconds())
 
 	// Extract the JSON file from the ZIP
 	jsonData, fileName, err := extractJSONFromZip(zipData)
@@ -94,98 +216,161 @@ func (d *Downloader) DownloadFile(ctx context.Context, fileType FileType) (*Down
 	log.Printf("Extraction complete for %s: %s (%d bytes)", fileType, fileName, len(jsonData))
 
 	return &DownloadedFile{
-		Type:     fileType,
-		FileName: fileName,
-		Data:     jsonData,
-		Size:     int64(len(jsonData)),
+		Type:           fileType,
+		FileName:       fileName,
+		Data:           jsonData,
+		Size:           int64(len(jsonData)),
+		CompressedSize: int64(len(zipData)),
+		StartTime:      startTime,
+		Duration:       duration,
 	}, nil
 }
 
 // DownloadFiles downloads multiple files in parallel and returns them in memory
 func (d *Downloader) DownloadFiles(ctx context.Context, fileTypes []FileType) (map[FileType]*DownloadedFile, error) {
 	results := make(map[FileType]*DownloadedFile)
-	errors := make(map[FileType]error)
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	var errMsg string
 
-	for _, fileType := range fileTypes {
-		wg.Add(1)
-		go func(ft FileType) {
-			defer wg.Done()
+	d.DownloadEach(ctx, fileTypes, 0, func(ft FileType, file *DownloadedFile, err error) {
+		if err != nil {
+			errMsg += fmt.Sprintf("%s: %v; ", ft, err)
+		} else {
+			results[ft] = file
+		}
+	})
 
-			file, err := d.DownloadFile(ctx, ft)
+	// Check if any critical errors occurred
+	if errMsg != "" {
+		return results, fmt.Errorf("some downloads failed: %s", errMsg)
+	}
 
-			mu.Lock()
-			defer mu.Unlock()
+	return results, nil
+}
 
-			if err != nil {
-				errors[ft] = err
-			} else {
-				results[ft] = file
-			}
-		}(fileType)
+// DownloadEach downloads files in parallel, at most concurrency at once (0 = all),
+// and calls handle with each in the order given, as soon as it and the files
+// before it are done. A failed download is handed over with its error. Another
+// download only starts once handle returns, so with a limit no more than
+// concurrency files are held in memory, provided handle doesn't keep their data.
+func (d *Downloader) DownloadEach(ctx context.Context, fileTypes []FileType, concurrency int, handle func(FileType, *DownloadedFile, error)) {
+	if concurrency <= 0 || concurrency > len(fileTypes) {
+		concurrency = len(fileTypes)
 	}
 
-	wg.Wait()
+	type result struct {
+		file *DownloadedFile
+		err  error
+	}
+	results := make([]chan result, len(fileTypes))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
 
-	// Check if any critical errors occurred
-	if len(errors) > 0 {
-		var errMsg string
-		for ft, err := range errors {
-			errMsg += fmt.Sprintf("%s: %v; ", ft, err)
+	// Downloads start in order, so the next file to handle is always under way
+	slots := make(chan struct{}, concurrency)
+	go func() {
+		for i, ft := range fileTypes {
+			slots <- struct{}{}
+			go func() {
+				file, err := d.DownloadFile(ctx, ft)
+				results[i] <- result{file, err}
+			}()
 		}
-		return results, fmt.Errorf("some downloads failed: %s", errMsg)
+	}()
+
+	for i, ft := range fileTypes {
+		r := <-results[i]
+		handle(ft, r.file, r.err)
+		<-slots
 	}
+}
 
-	return results, nil
+// downloadWithRetry downloads data from a URL with retry logic, returning it with
+// its Content-Type
+func (d *Downloader) downloadWithRetry(ctx context.Context, url string, fileType FileType) ([]byte, string, error) {
+	var data []byte
+	var contentType string
+	err := d.withRetry(ctx, fileType, "Download", func() error {
+		var err error
+		data, contentType, err = d.download(ctx, url, fileType)
+		return err
+	})
+	return data, contentType, err
 }
 
-// downloadWithRetry downloads data from a URL with retry logic
-func (d *Downloader) downloadWithRetry(ctx context.Context, url string, fileType FileType) ([]byte, error) {
+// withRetry runs a request for a file until it succeeds or attempts run out.
+// Network errors, 429 and 5xx responses are retried with exponential backoff, or
+// after the server's Retry-After if it sent one; other 4xx responses fail
+// straight away. action names the request in log messages.
+func (d *Downloader) withRetry(ctx context.Context, fileType FileType, action string, attempt func() error) error {
 	var lastErr error
+	delay := d.retryDelay
+
+	for n := 1; n <= d.maxRetries; n++ {
+		if n > 1 {
+			wait := delay
+			var statusErr *StatusError
+			if errors.As(lastErr, &statusErr) && statusErr.RetryAfter > 0 {
+				wait = statusErr.RetryAfter
+			}
+			wait = min(wait, d.maxRetryDelay)
+			delay *= 2
 
-	for attempt := 1; attempt <= d.maxRetries; attempt++ {
-		if attempt > 1 {
-			log.Printf("Retrying %s (attempt %d/%d)...", fileType, attempt, d.maxRetries)
+			log.Printf("Retrying %s in %v (attempt %d/%d)...", fileType, wait, n, d.maxRetries)
 			select {
 			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(d.retryDelay):
+				return ctx.Err()
+			case <-time.After(wait):
 			}
 		}
 
-		data, err := d.download(ctx, url, fileType)
+		err := attempt()
 		if err == nil {
-			return data, nil
+			return nil
 		}
 
 		lastErr = err
-		log.Printf("Download attempt %d failed for %s: %v", attempt, fileType, err)
+		log.Printf("%s attempt %d failed for %s: %v", action, n, fileType, err)
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && !statusErr.Temporary() {
+			return err
+		}
 	}
 
-	return nil, fmt.Errorf("failed after %d attempts: %w", d.maxRetries, lastErr)
+	return fmt.Errorf("failed after %d attempts: %w", d.maxRetries, lastErr)
 }
 
-// download performs a single download operation
-func (d *Downloader) download(ctx context.Context, url string, fileType FileType) ([]byte, error) {
+// download performs a single download operation, returning the data with its
+// Content-Type
+func (d *Downloader) download(ctx context.Context, url string, fileType FileType) ([]byte, string, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	req.Header.Set("User-Agent", d.userAgent)
 
 	resp, err := d.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, "", &StatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	// Reject oversized downloads up front when the server reports a length
+	totalBytes := resp.ContentLength
+	if totalBytes > d.maxBytes {
+		return nil, "", fmt.Errorf("download too large: %d bytes (limit %d)", totalBytes, d.maxBytes)
 	}
 
 	// Read with progress tracking
 	var buf bytes.Buffer
-	totalBytes := resp.ContentLength
 	var bytesRead int64
 
 	// Create a buffer for efficient copying
@@ -197,6 +382,10 @@ func (d *Downloader) download(ctx context.Context, url string, fileType FileType
 			buf.Write(buffer[:n])
 			bytesRead += int64(n)
 
+			if bytesRead > d.maxBytes {
+				return nil, "", fmt.Errorf("download too large: exceeded limit of %d bytes", d.maxBytes)
+			}
+
 			// Report progress if handler is set
 			if d.progressHandler != nil && totalBytes > 0 {
 				d.progressHandler(fileType, bytesRead, totalBytes)
@@ -207,11 +396,28 @@ func (d *Downloader) download(ctx context.Context, url string, fileType FileType
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 	}
 
-	return buf.Bytes(), nil
+	return buf.Bytes(), resp.Header.Get("Content-Type"), nil
+}
+
+// parseRetryAfter returns the wait requested by a Retry-After header, given as
+// either delay seconds or an HTTP date. It returns 0 if the header is absent,
+// malformed or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(0, time.Duration(seconds)*time.Second)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(0, date.Sub(now))
+	}
+	return 0
 }
 
 // extractJSONFromZip extracts the first JSON file from a ZIP archive
@@ -259,5 +465,40 @@ func DefaultFileSet() []FileType {
 		FileCharityAnnualReturnA,
 		FileCharityAnnualReturnB,
 		FileCharityAnnualReturnHist,
+		FileCharityClassification,
+		FileCharityEventHistory,
+	}
+}
+
+// ParseFileTypes parses a comma-separated list of file type names (e.g.
+// "charity,charity_annual_return_partb"), validating each against the known
+// file types. An empty list returns DefaultFileSet.
+func ParseFileTypes(list string) ([]FileType, error) {
+	known := make(map[FileType]bool)
+	for _, ft := range DefaultFileSet() {
+		known[ft] = true
+	}
+
+	var fileTypes []FileType
+	seen := make(map[FileType]bool)
+	for _, name := range strings.Split(list, ",") {
+		ft := FileType(strings.TrimSpace(name))
+		if ft == "" || seen[ft] {
+			continue
+		}
+		if !known[ft] {
+			var names []string
+			for _, k := range DefaultFileSet() {
+				names = append(names, string(k))
+			}
+			return nil, fmt.Errorf("unknown file type %q (valid types: %s)", ft, strings.Join(names, ", "))
+		}
+		seen[ft] = true
+		fileTypes = append(fileTypes, ft)
+	}
+
+	if len(fileTypes) == 0 {
+		return DefaultFileSet(), nil
 	}
+	return fileTypes, nil
 }
@@ -10,6 +10,8 @@ var (
 	ErrNotFound      = errors.New("not found")
 	ErrInvalidInput  = errors.New("invalid input")
 	ErrUnauthorized  = errors.New("unauthorized")
+	ErrForbidden     = errors.New("forbidden")
+	ErrConflict      = errors.New("conflict")
 	ErrRateLimit     = errors.New("rate limit exceeded")
 	ErrExternalAPI   = errors.New("external API error")
 	ErrDatabaseError = errors.New("database error")
@@ -26,6 +28,11 @@ func (e ValidationError) Error() string {
 	return fmt.Sprintf("validation error: %s - %s", e.Field, e.Message)
 }
 
+// Is allows error comparison using errors.Is
+func (e ValidationError) Is(target error) bool {
+	return errors.Is(target, ErrInvalidInput)
+}
+
 // APIError represents errors from external APIs
 type APIError struct {
 	Service    string
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"charitylens/internal/background"
+	apperrors "charitylens/internal/errors"
+	"charitylens/internal/logger"
+	"charitylens/internal/models"
+	"charitylens/internal/scoring"
+	"charitylens/internal/sync"
+)
+
+// backgroundScoreTimeout bounds each score calculation started in the background,
+// including any retries while the database is busy
+const backgroundScoreTimeout = 30 * time.Second
+
+// backgroundCtx is the parent of background score calculations, cancelled by
+// StopBackgroundWork
+var backgroundCtx, stopBackground = context.WithCancel(context.Background())
+
+// StopBackgroundWork cancels background syncs and score calculations in progress,
+// so they don't hold up shutdown. Work started afterwards fails immediately.
+func StopBackgroundWork() {
+	stopBackground()
+	sync.StopBackgroundSyncs()
+}
+
+// scoreInBackground calculates and stores a charity's score from a background
+// goroutine, giving up after backgroundScoreTimeout or on shutdown
+func scoreInBackground(db *sql.DB, charityNum int) (models.CharityScore, error) {
+	ctx, cancel := context.WithTimeout(backgroundCtx, backgroundScoreTimeout)
+	defer cancel()
+
+	score, err := scoring.CalculateScoreContext(ctx, db, charityNum)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.Warn("Background score calculation timed out", "operation", "score", "charity_number", charityNum, "timeout", backgroundScoreTimeout)
+	case errors.Is(err, context.Canceled):
+		logger.Info("Background score calculation cancelled for shutdown", "operation", "score", "charity_number", charityNum)
+	}
+	return score, err
+}
+
+// GetBackgroundStats reports how much background work, such as the syncs and
+// score calculations triggered by searches, is running against the limit set by
+// MAX_BACKGROUND_TASKS, and how many tasks have been dropped at the limit. Like
+// GetKeyStats it requires AdminAPIKey to be configured.
+func (h *CharityHandler) GetBackgroundStats(w http.ResponseWriter, r *http.Request) {
+	if h.Cfg.AdminAPIKey == "" {
+		writeError(w, fmt.Errorf("admin API key is not configured: %w", apperrors.ErrForbidden))
+		return
+	}
+	if !h.isAdmin(r) {
+		writeError(w, apperrors.ErrUnauthorized)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, background.CurrentStats())
+}
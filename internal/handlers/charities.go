@@ -1,38 +1,51 @@
 package handlers
 
 import (
+	"context"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
 	"math/rand"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"charitylens/internal/api"
+	"charitylens/internal/background"
 	"charitylens/internal/config"
+	"charitylens/internal/database"
+	apperrors "charitylens/internal/errors"
+	"charitylens/internal/logger"
 	"charitylens/internal/models"
 	"charitylens/internal/scoring"
 	"charitylens/internal/sync"
+	"charitylens/internal/validation"
+	"charitylens/web/templates"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type CharityHandler struct {
-	DB  *sql.DB
-	Cfg *config.Config
-}
+	DB     *sql.DB // Primary database
+	Cfg    *config.Config
+	stats  *statsCache
+	pages  *pageCache
+	recent *recentCache
+
+	// dbs sends search, detail and compare reads to the read replica, if any
+	dbs *database.DB
 
-func NewCharityHandler(db *sql.DB, cfg *config.Config) *CharityHandler {
-	return &CharityHandler{DB: db, Cfg: cfg}
+	// rescoring is set while a Rescore job runs
+	rescoring atomic.Bool
 }
 
-// debugLog logs a message only if debug mode is enabled
-func (h *CharityHandler) debugLog(format string, args ...any) {
-	if h.Cfg.Debug {
-		log.Printf(format, args...)
-	}
+func NewCharityHandler(dbs *database.DB, cfg *config.Config) *CharityHandler {
+	return &CharityHandler{DB: dbs.Writer(), Cfg: cfg, stats: &statsCache{}, pages: getPageCache(cfg), recent: &recentCache{pages: map[string]recentPage{}}, dbs: dbs}
 }
 
 // writeJSON is a helper to write JSON responses
@@ -40,47 +53,44 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
-		log.Printf("Error encoding JSON response: %v", err)
+		logger.Error("Error encoding JSON response", "error", err)
 	}
 }
 
 func (h *CharityHandler) SearchCharities(w http.ResponseWriter, r *http.Request) {
 	query := strings.TrimSpace(r.URL.Query().Get("q"))
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	category := strings.TrimSpace(r.URL.Query().Get("category"))
+	complete := r.URL.Query().Get("complete") == "true"
+	limit, offset := parsePage(r, 50, 100)
 
-	limit := 50 // Default page size
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
-
-	offset := 0
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
-	}
-
-	if query == "" {
-		http.Error(w, "Query parameter 'q' is required", http.StatusBadRequest)
+	if query == "" && category == "" {
+		writeError(w, apperrors.ValidationError{Field: "q", Message: "Query parameter 'q' or 'category' is required"})
 		return
 	}
 
 	// Basic input validation
 	if len(query) > 200 {
-		http.Error(w, "Query too long (max 200 characters)", http.StatusBadRequest)
+		writeError(w, apperrors.ValidationError{Field: "q", Message: "Query too long (max 200 characters)"})
+		return
+	}
+
+	// A name search scans every charity's name, and a very short one matches most
+	// of them. Number searches are exact lookups, so any length is fine.
+	if _, err := strconv.Atoi(query); err != nil && query != "" && utf8.RuneCountInString(query) < h.Cfg.SearchMinQueryLength {
+		writeError(w, apperrors.ValidationError{
+			Field:   "q",
+			Message: fmt.Sprintf("Query too short (min %d characters)", h.Cfg.SearchMinQueryLength),
+		})
 		return
 	}
 
-	log.Printf("Search request for query: '%s' (length: %d, limit: %d, offset: %d)", query, len(query), limit, offset)
+	logger.Info("Search request", "operation", "search", "query", query, "category", category, "limit", limit, "offset", offset)
 
 	// Try searching by number first if query looks like a number
 	if charityNum, err := strconv.Atoi(query); err == nil {
-		h.debugLog("Searching by number: %d", charityNum)
+		logger.Debug("Searching by number", "operation", "search", "charity_number", charityNum)
 		charities := h.searchByNumber(charityNum, limit)
-		h.debugLog("Number search returned %d results", len(charities))
+		logger.Debug("Number search complete", "operation", "search", "charity_number", charityNum, "results", len(charities))
 
 		response := map[string]any{
 			"results": charities,
@@ -88,14 +98,15 @@ func (h *CharityHandler) SearchCharities(w http.ResponseWriter, r *http.Request)
 			"limit":   limit,
 			"offset":  offset,
 		}
+		addPagination(w, r, response, limit, offset, len(charities))
 		writeJSON(w, http.StatusOK, response)
 		return
 	}
 
 	// Search by name
-	h.debugLog("Searching by name: %s", query)
-	charities, total := h.searchByName(query, limit, offset)
-	h.debugLog("Name search returned %d results (out of %d total)", len(charities), total)
+	logger.Debug("Searching by name", "operation", "search", "query", query)
+	charities, total, liveUnavailable := h.searchByName(query, category, complete, limit, offset)
+	logger.Debug("Name search complete", "operation", "search", "query", query, "results", len(charities), "total", total)
 
 	response := map[string]any{
 		"results":  charities,
@@ -104,27 +115,39 @@ func (h *CharityHandler) SearchCharities(w http.ResponseWriter, r *http.Request)
 		"offset":   offset,
 		"has_more": offset+len(charities) < total,
 	}
+	if liveUnavailable {
+		// Results are from the local database only
+		response["live_search_unavailable"] = true
+	}
+	addPagination(w, r, response, limit, offset, total)
 	writeJSON(w, http.StatusOK, response)
 }
 
+// searchSideEffects reports whether searches may call the live API and trigger
+// background syncs and score calculations. When false, search only reads what is
+// already in the database.
+func (h *CharityHandler) searchSideEffects() bool {
+	return !h.Cfg.OfflineMode && h.Cfg.EnableSearchSideEffects
+}
+
 func (h *CharityHandler) searchByNumber(charityNum int, limit int) []models.Charity {
-	h.debugLog("Searching for charity number: %d", charityNum)
+	logger.Debug("Searching for charity number", "operation", "search", "charity_number", charityNum)
 
 	// First check if we already have this charity in the database with score (main charity only, exclude removed)
 	var existing models.Charity
 	var overallScore float64
-	var address, website, email, whatTheCharityDoes sql.NullString
-	err := h.DB.QueryRow(`
-		SELECT c.registered_number, c.name, c.status, c.address, c.website, c.email, 
+	var address, website, email, phone, whatTheCharityDoes sql.NullString
+	err := h.dbs.Reader().QueryRow(`
+		SELECT c.registered_number, c.name, c.status, c.address, c.website, c.email, c.phone,
 		       c.what_the_charity_does, COALESCE(s.overall_score, 0) as overall_score
 		FROM charities c
 		LEFT JOIN charity_scores s ON c.registered_number = s.charity_number
 		WHERE c.registered_number = ? 
 		  AND c.linked_charity_number = 0
-		  AND c.status NOT IN ('Removed', 'RM')
+		  AND `+database.ListedSQL("c.status")+`
 	`, charityNum).Scan(
 		&existing.RegisteredNumber, &existing.Name, &existing.Status,
-		&address, &website, &email, &whatTheCharityDoes,
+		&address, &website, &email, &phone, &whatTheCharityDoes,
 		&overallScore,
 	)
 
@@ -139,60 +162,146 @@ func (h *CharityHandler) searchByNumber(charityNum int, limit int) []models.Char
 		if email.Valid {
 			existing.Email = email.String
 		}
+		if phone.Valid {
+			existing.Phone = phone.String
+		}
 		if whatTheCharityDoes.Valid {
 			existing.WhatTheCharityDoes = whatTheCharityDoes.String
 		}
 
-		h.debugLog("Found charity %d in database: %s (score: %.1f)", charityNum, existing.Name, overallScore)
+		logger.Debug("Found charity in database", "operation", "search", "charity_number", charityNum, "name", existing.Name, "score", overallScore)
 		existing.OverallScore = overallScore
 		// Charity exists in database
 		return []models.Charity{existing}
 	}
 
-	// In offline mode, don't try to search API
-	if h.Cfg.OfflineMode {
-		h.debugLog("Charity %d not in database (offline mode - no API search)", charityNum)
+	// In offline mode, or with search side effects disabled, don't try to search API
+	if !h.searchSideEffects() {
+		logger.Debug("Charity not in database (API search disabled)", "operation", "search", "charity_number", charityNum)
+		return []models.Charity{}
+	}
+	if sync.APIBudgetExhausted() {
+		logger.Warn("Charity not in database (API budget exhausted)", "operation", "search", "charity_number", charityNum)
 		return []models.Charity{}
 	}
 
-	h.debugLog("Charity %d not in database, searching API", charityNum)
+	logger.Debug("Charity not in database, searching API", "operation", "search", "charity_number", charityNum)
 
 	// Charity not in database, search via API
 	results, err := sync.SearchCharitiesByNumber(h.Cfg, strconv.Itoa(charityNum))
 	if err != nil {
-		log.Printf("Error searching by number: %v", err)
+		logger.Error("Error searching by number", "operation", "search", "charity_number", charityNum, "error", err)
 		return []models.Charity{}
 	}
 
-	h.debugLog("API search returned %d results for number %d", len(results), charityNum)
+	logger.Debug("API number search complete", "operation", "search", "charity_number", charityNum, "results", len(results))
 	return h.processSearchResults(results, limit)
 }
 
-func (h *CharityHandler) searchByName(query string, limit int, offset int) ([]models.Charity, int) {
-	h.debugLog("Searching for charity name: %s (limit=%d, offset=%d)", query, limit, offset)
+// waitForSearch runs a live API search, waiting up to SearchAPITimeout for its
+// results. Past the deadline it returns no results, leaving the search to finish
+// and populate the database in the background. As the search may outlive the
+// request, it counts towards the background work limit, and no search is made
+// while that's been reached.
+func (h *CharityHandler) waitForSearch(query string, search func() ([]models.Charity, error)) ([]models.Charity, error) {
+	ctx := context.Background()
+	if h.Cfg.SearchAPITimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Cfg.SearchAPITimeout)
+		defer cancel()
+	}
+
+	type searchResult struct {
+		charities []models.Charity
+		err       error
+	}
+	done := make(chan searchResult, 1) // Buffered so a late search doesn't block
+	if !background.Go("search", func() {
+		charities, err := search()
+		done <- searchResult{charities, err}
+	}) {
+		return nil, nil
+	}
+
+	select {
+	case result := <-done:
+		return result.charities, result.err
+	case <-ctx.Done():
+		logger.Warn("Live API search timed out, serving database results while it finishes in the background",
+			"operation", "search", "query", query, "timeout", h.Cfg.SearchAPITimeout)
+		return nil, nil
+	}
+}
+
+// likeEscaper escapes LIKE's wildcards and its escape character, for patterns
+// used with ESCAPE '!'. It isn't a backslash because MySQL treats backslash as a
+// string escape, so ESCAPE '\' is a syntax error there.
+var likeEscaper = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+
+// escapeLike escapes s for use inside a LIKE pattern, so it only matches itself
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// searchByName searches the database, topping it up from the live API where needed.
+// complete restricts results to charities with financial data and a medium or high
+// confidence score. liveUnavailable is true when a live search was wanted but the API was down.
+func (h *CharityHandler) searchByName(query string, category string, complete bool, limit int, offset int) (charities []models.Charity, total int, liveUnavailable bool) {
+	logger.Debug("Searching for charity name", "operation", "search", "query", query, "category", category, "complete", complete, "limit", limit, "offset", offset)
+
+	// The query is matched literally, so "100%" or "_" aren't treated as wildcards,
+	// against the folded name so case and accents don't matter
+	pattern := escapeLike(database.FoldName(query))
+
+	// Optional category filter restricts results to charities with a matching classification code
+	filterClause := ""
+	filterArgs := []any{"%" + pattern + "%", pattern + "%"}
+	if category != "" {
+		filterClause = `
+		  AND EXISTS (
+			SELECT 1 FROM charity_classifications cc
+			WHERE cc.registered_charity_number = c.registered_number
+			  AND cc.classification_code = ?
+		  )`
+		filterArgs = append(filterArgs, category)
+	}
+
+	// Optional completeness filter keeps only charities that can be meaningfully scored
+	if complete {
+		filterClause += `
+		  AND c.registered_number IN (SELECT charity_number FROM financials)
+		  AND c.registered_number IN (
+			SELECT charity_number FROM charity_scores
+			WHERE confidence_level != 'low'
+		  )`
+	}
 
 	// First, get total count of matching charities in database (main charities only, exclude removed)
 	var totalInDB int
-	h.DB.QueryRow(`
-		SELECT COUNT(*) FROM charities
-		WHERE (LOWER(name) LIKE LOWER(?) OR LOWER(name) LIKE LOWER(?))
-		  AND linked_charity_number = 0
-		  AND status NOT IN ('Removed', 'RM')
-	`, "%"+query+"%", query+"%").Scan(&totalInDB)
+	h.dbs.Reader().QueryRow(`
+		SELECT COUNT(*) FROM charities c
+		WHERE (c.name_folded LIKE ? ESCAPE '!' OR c.name_folded LIKE ? ESCAPE '!')
+		  AND c.linked_charity_number = 0
+		  AND `+database.ListedSQL("c.status")+filterClause,
+		filterArgs...).Scan(&totalInDB)
 
-	h.debugLog("Total charities in database matching '%s': %d", query, totalInDB)
+	logger.Debug("Counted matching charities in database", "operation", "search", "query", query, "total", totalInDB)
 
 	// Decide whether to search the API to discover new charities:
 	// 1. If we have < 10 results (need more data)
 	// 2. Or periodically for popular searches (7+ days old or 10% random)
-	// But skip API search entirely if in offline mode
-	shouldSearchAPI := !h.Cfg.OfflineMode && totalInDB < 10 && len(query) >= 3
+	// But skip API search entirely if in offline mode or search side effects are disabled,
+	// or when filtering by category or completeness (API search results carry no
+	// classification, financial or score data). Queries shorter than
+	// SearchMinQueryLength have already been refused.
+	canSearchAPI := h.searchSideEffects() && category == "" && !complete
+	shouldSearchAPI := canSearchAPI && totalInDB < 10
 	searchInBackground := false
 
 	// For popular searches (10+ results), periodically refresh to find newly registered charities
-	if !h.Cfg.OfflineMode && !shouldSearchAPI && totalInDB >= 10 && len(query) >= 3 {
+	if canSearchAPI && !shouldSearchAPI && totalInDB >= 10 {
 		var lastRefresh time.Time
-		err := h.DB.QueryRow(`
+		err := h.dbs.Reader().QueryRow(`
 			SELECT last_searched FROM search_cache 
 			WHERE query = ? AND search_type = 'name'
 		`, query).Scan(&lastRefresh)
@@ -204,33 +313,48 @@ func (h *CharityHandler) searchByName(query string, limit int, offset int) ([]mo
 			shouldSearchAPI = true
 			searchInBackground = true // Don't block user response for refreshes
 			if randomRefresh {
-				log.Printf("Random refresh triggered for popular search '%s' (10%% chance)", query)
+				logger.Info("Random refresh triggered for popular search (10% chance)", "operation", "search", "query", query)
 			} else if err == sql.ErrNoRows {
-				log.Printf("First-time API search for '%s'", query)
+				logger.Info("First-time API search", "operation", "search", "query", query)
 				searchInBackground = false // Wait for first search to complete
 			} else {
-				log.Printf("Periodic refresh for '%s' (last searched %.1f hours ago)", query, hoursSinceRefresh)
+				logger.Info("Periodic refresh", "operation", "search", "query", query, "hours_since_refresh", hoursSinceRefresh)
 			}
 		}
 	}
 
+	// Skip the API entirely while it is known to be down, rather than making the
+	// user wait out retries; serve database results instead
+	if shouldSearchAPI && !sync.APIAvailable(h.Cfg) {
+		logger.Warn("Charity Commission API unavailable, serving database results", "operation", "search", "query", query)
+		shouldSearchAPI = false
+		liveUnavailable = !searchInBackground
+	}
+
+	// Likewise once the API budget is used up, until it resets
+	if shouldSearchAPI && sync.APIBudgetExhausted() {
+		logger.Warn("API budget exhausted, serving database results", "operation", "search", "query", query)
+		shouldSearchAPI = false
+		liveUnavailable = !searchInBackground
+	}
+
 	// If we should search API, fetch and store ALL results
 	if shouldSearchAPI {
-		h.debugLog("Searching API for '%s' (totalInDB=%d, query_length=%d, background=%v)", query, totalInDB, len(query), searchInBackground)
+		logger.Debug("Searching API", "operation", "search", "query", query, "total_in_db", totalInDB, "background", searchInBackground)
 
 		var apiCharities []models.Charity
 
-		syncFunc := func() []models.Charity {
+		syncFunc := func() ([]models.Charity, error) {
 			results, err := sync.SearchCharitiesByName(h.Cfg, query)
 			if err != nil {
-				log.Printf("API search error for '%s': %v", query, err)
-				return nil
+				logger.Error("API search failed", "operation", "search", "query", query, "error", err)
+				return nil, err
 			}
 
-			log.Printf("API search returned %d results for '%s'", len(results), query)
+			logger.Info("API search complete", "operation", "search", "query", query, "results", len(results))
 
 			// Update search cache
-			h.DB.Exec(`
+			database.ExecRetry(h.DB, `
 				INSERT INTO search_cache (query, search_type, last_searched, result_count)
 				VALUES (?, 'name', ?, ?)
 				ON CONFLICT(query, search_type) DO UPDATE SET
@@ -241,18 +365,23 @@ func (h *CharityHandler) searchByName(query string, limit int, offset int) ([]mo
 			// Process ALL results to get charity objects
 			// Note: processSearchResults handles background sync and score calculation internally
 			allCharities := h.processSearchResults(results, len(results))
-			log.Printf("Processed %d charities from API (out of %d total)", len(allCharities), len(results))
+			logger.Info("Processed charities from API", "operation", "search", "query", query, "processed", len(allCharities), "total", len(results))
 
-			return allCharities
+			return allCharities, nil
 		}
 
 		if searchInBackground {
 			// Background refresh for popular searches - don't wait
-			h.debugLog("Running API search in background")
-			go syncFunc()
+			logger.Debug("Running API search in background", "operation", "search", "query", query)
+			background.Go("search", func() { syncFunc() })
 		} else {
-			// Synchronous for first-time searches - wait and use results
-			apiCharities = syncFunc()
+			// Synchronous for first-time searches - wait and use results, but only
+			// up to SearchAPITimeout so a slow API can't hang the page
+			var err error
+			apiCharities, err = h.waitForSearch(query, syncFunc)
+			if err != nil && !errors.Is(err, api.ErrNotFound) {
+				liveUnavailable = true
+			}
 			if len(apiCharities) > 0 {
 				// Return paginated slice of API results
 				start := offset
@@ -265,35 +394,35 @@ func (h *CharityHandler) searchByName(query string, limit int, offset int) ([]mo
 				}
 
 				paginatedResults := apiCharities[start:end]
-				h.debugLog("Returning %d charities from API results (offset=%d, total=%d)", len(paginatedResults), offset, len(apiCharities))
-				return paginatedResults, len(apiCharities)
+				logger.Debug("Returning charities from API results", "operation", "search", "query", query, "results", len(paginatedResults), "offset", offset, "total", len(apiCharities))
+				return paginatedResults, len(apiCharities), false
 			}
 		}
 	}
 
 	// Return paginated results from database (for existing data or if API failed, main charities only, exclude removed)
-	rows, err := h.DB.Query(`
-		SELECT c.registered_number, c.name, c.status, c.address, c.website, c.email, 
+	pageArgs := append(append([]any{}, filterArgs...), limit, offset)
+	rows, err := h.dbs.Reader().Query(`
+		SELECT c.registered_number, c.name, c.status, c.address, c.website, c.email, c.phone,
 		       c.what_the_charity_does, COALESCE(s.overall_score, 0) as overall_score
 		FROM charities c
 		LEFT JOIN charity_scores s ON c.registered_number = s.charity_number
-		WHERE (LOWER(c.name) LIKE LOWER(?) OR LOWER(c.name) LIKE LOWER(?))
+		WHERE (c.name_folded LIKE ? ESCAPE '!' OR c.name_folded LIKE ? ESCAPE '!')
 		  AND c.linked_charity_number = 0
-		  AND c.status NOT IN ('Removed', 'RM')
+		  AND `+database.ListedSQL("c.status")+filterClause+`
 		ORDER BY c.name
 		LIMIT ? OFFSET ?
-	`, "%"+query+"%", query+"%", limit, offset)
+	`, pageArgs...)
 
-	var charities []models.Charity
 	if err == nil {
 		defer rows.Close()
 		for rows.Next() {
 			var charity models.Charity
 			var overallScore float64
-			var address, website, email, whatTheCharityDoes sql.NullString
+			var address, website, email, phone, whatTheCharityDoes sql.NullString
 			err := rows.Scan(
 				&charity.RegisteredNumber, &charity.Name, &charity.Status,
-				&address, &website, &email, &whatTheCharityDoes,
+				&address, &website, &email, &phone, &whatTheCharityDoes,
 				&overallScore,
 			)
 			if err == nil {
@@ -307,6 +436,9 @@ func (h *CharityHandler) searchByName(query string, limit int, offset int) ([]mo
 				if email.Valid {
 					charity.Email = email.String
 				}
+				if phone.Valid {
+					charity.Phone = phone.String
+				}
 				if whatTheCharityDoes.Valid {
 					charity.WhatTheCharityDoes = whatTheCharityDoes.String
 				}
@@ -318,180 +450,469 @@ func (h *CharityHandler) searchByName(query string, limit int, offset int) ([]mo
 	}
 
 	// Recalculate total (main charities only, exclude removed)
-	h.DB.QueryRow(`
-		SELECT COUNT(*) FROM charities
-		WHERE (LOWER(name) LIKE LOWER(?) OR LOWER(name) LIKE LOWER(?))
-		  AND linked_charity_number = 0
-		  AND status NOT IN ('Removed', 'RM')
-	`, "%"+query+"%", query+"%").Scan(&totalInDB)
-
-	h.debugLog("Returning %d charities from database (offset=%d, total=%d)", len(charities), offset, totalInDB)
-	return charities, totalInDB
+	h.dbs.Reader().QueryRow(`
+		SELECT COUNT(*) FROM charities c
+		WHERE (c.name_folded LIKE ? ESCAPE '!' OR c.name_folded LIKE ? ESCAPE '!')
+		  AND c.linked_charity_number = 0
+		  AND `+database.ListedSQL("c.status")+filterClause,
+		filterArgs...).Scan(&totalInDB)
+
+	logger.Debug("Returning charities from database", "operation", "search", "query", query, "results", len(charities), "offset", offset, "total", totalInDB)
+	return charities, totalInDB, liveUnavailable
 }
 
 func (h *CharityHandler) GetCharity(w http.ResponseWriter, r *http.Request) {
 	numberStr := chi.URLParam(r, "number")
 	number, err := strconv.Atoi(numberStr)
-	if err != nil || number < 1 || number > 9999999999 {
-		http.Error(w, "Invalid charity number", http.StatusBadRequest)
-		return
-	}
-
-	// Get charity details (main charity only, linked_charity_number = 0)
-	var charity models.Charity
-	var website, email, address, whatTheCharityDoes sql.NullString
-	err = h.DB.QueryRow(`
-		SELECT registered_number, name, status, date_registered, address, website,
-		       email, what_the_charity_does
-		FROM charities WHERE registered_number = ? AND linked_charity_number = 0
-	`, number).Scan(
-		&charity.RegisteredNumber, &charity.Name, &charity.Status,
-		&charity.DateRegistered, &address, &website,
-		&email, &whatTheCharityDoes,
-	)
-
-	// Convert NullString to string
-	if address.Valid {
-		charity.Address = address.String
-	}
-	if website.Valid {
-		charity.Website = website.String
+	if err == nil {
+		err = validation.ValidateCharityNumber(number)
 	}
-	if email.Valid {
-		charity.Email = email.String
+	if err != nil {
+		writeError(w, apperrors.ValidationError{Field: "number", Message: "Invalid charity number"})
+		return
 	}
-	if whatTheCharityDoes.Valid {
-		charity.WhatTheCharityDoes = whatTheCharityDoes.String
+
+	serveCharityJSON(w, h.dbs, h.Cfg, number)
+}
+
+// charityNumberParam validates the {number} URL parameter and checks the charity
+// exists, writing the error response and returning false if not
+func (h *CharityHandler) charityNumberParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err == nil {
+		err = validation.ValidateCharityNumber(number)
 	}
 	if err != nil {
+		writeError(w, apperrors.ValidationError{Field: "number", Message: "Invalid charity number"})
+		return 0, false
+	}
+
+	if _, err := loadCharity(h.dbs.Reader(), number); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Charity not found"})
-			return
+			err = apperrors.CharityNotFoundError{Number: number}
 		}
-		log.Printf("Database error: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		writeError(w, err)
+		return 0, false
+	}
+	return number, true
+}
+
+// GetCharityRaw returns the raw Charity Commission API response stored when the
+// charity was last synced, less fields that can't be redistributed. It never
+// calls the API itself, so charities only imported from the bulk extracts, or
+// not synced since raw responses were kept, are not found.
+func (h *CharityHandler) GetCharityRaw(w http.ResponseWriter, r *http.Request) {
+	number, ok := h.charityNumberParam(w, r)
+	if !ok {
 		return
 	}
 
-	// Calculate score (don't cache in offline mode - always fresh)
-	score, err := scoring.CalculateScore(h.DB, number, !h.Cfg.OfflineMode)
-	scoreError := ""
+	var response string
+	var fetchedAt time.Time
+	err := h.dbs.Reader().QueryRow(`
+		SELECT response, fetched_at FROM charity_raw_responses WHERE charity_number = ?
+	`, number).Scan(&response, &fetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, fmt.Errorf("no raw API data stored for charity %d: %w", number, apperrors.ErrNotFound))
+		return
+	}
 	if err != nil {
-		// If error, continue without score but log it
-		log.Printf("Error calculating score for charity %d: %v", number, err)
-		scoreError = err.Error()
-		score = models.CharityScore{
-			CharityNumber: number,
-		}
+		writeError(w, fmt.Errorf("loading raw API data: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"charity_number": number,
+		"fetched_at":     fetchedAt,
+		"data":           json.RawMessage(response),
+	})
+}
+
+// GetTrustees returns a page of a charity's trustees, ordered by name
+func (h *CharityHandler) GetTrustees(w http.ResponseWriter, r *http.Request) {
+	number, ok := h.charityNumberParam(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := parsePage(r, detailPageSize, maxDetailPageSize)
+
+	trustees, total, err := loadTrustees(h.dbs.Reader(), number, limit, offset)
+	if err != nil {
+		writeError(w, fmt.Errorf("loading trustees: %w", err))
+		return
+	}
+
+	// Include the display name so lazy-loaded trustees match the rendered page
+	type trusteeResult struct {
+		models.Trustee
+		DisplayName string `json:"display_name"`
+	}
+	results := make([]trusteeResult, len(trustees))
+	for i, trustee := range trustees {
+		results[i] = trusteeResult{Trustee: trustee, DisplayName: templates.TitleCase(trustee.Name)}
 	}
 
-	type Response struct {
-		Charity    models.Charity      `json:"charity"`
-		Score      models.CharityScore `json:"score"`
-		ScoreError string              `json:"score_error,omitempty"`
+	response := map[string]any{
+		"results":  results,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": offset+len(results) < total,
 	}
+	addPagination(w, r, response, limit, offset, total)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// GetActivities returns a page of a charity's activities, ordered by description
+func (h *CharityHandler) GetActivities(w http.ResponseWriter, r *http.Request) {
+	number, ok := h.charityNumberParam(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := parsePage(r, detailPageSize, maxDetailPageSize)
 
-	response := Response{
-		Charity:    charity,
-		Score:      score,
-		ScoreError: scoreError,
+	activities, total, err := loadActivities(h.dbs.Reader(), number, limit, offset)
+	if err != nil {
+		writeError(w, fmt.Errorf("loading activities: %w", err))
+		return
 	}
 
+	response := map[string]any{
+		"results":  activities,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": offset+len(activities) < total,
+	}
+	addPagination(w, r, response, limit, offset, total)
 	writeJSON(w, http.StatusOK, response)
 }
 
+// GetCharitiesByCompany returns the charities registered under a Companies House
+// number. Linked entities are included, so there may be more than one.
+func (h *CharityHandler) GetCharitiesByCompany(w http.ResponseWriter, r *http.Request) {
+	companyNumber, err := validation.NormalizeCompanyNumber(chi.URLParam(r, "companyNumber"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The register doesn't always keep leading zeros, so match both forms
+	rows, err := h.dbs.Reader().Query(`
+		SELECT c.organisation_number, c.registered_number, c.linked_charity_number,
+		       c.company_number, c.name, c.status, c.address, c.website,
+		       COALESCE(s.overall_score, 0)
+		FROM charities c
+		LEFT JOIN charity_scores s ON c.registered_number = s.charity_number
+		WHERE c.company_number IN (?, ?)
+		  AND `+database.ListedSQL("c.status")+`
+		ORDER BY c.registered_number, c.linked_charity_number
+	`, companyNumber, strings.TrimLeft(companyNumber, "0"))
+	if err != nil {
+		writeError(w, fmt.Errorf("looking up company number: %w", err))
+		return
+	}
+	defer rows.Close()
+
+	charities := []models.Charity{}
+	for rows.Next() {
+		var charity models.Charity
+		var address, website sql.NullString
+		if err := rows.Scan(
+			&charity.OrganisationNumber, &charity.RegisteredNumber, &charity.LinkedCharityNumber,
+			&charity.CompanyNumber, &charity.Name, &charity.Status, &address, &website,
+			&charity.OverallScore,
+		); err != nil {
+			writeError(w, fmt.Errorf("scanning charity: %w", err))
+			return
+		}
+		charity.Address = address.String
+		charity.Website = website.String
+		charities = append(charities, charity)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(w, fmt.Errorf("looking up company number: %w", err))
+		return
+	}
+
+	if len(charities) == 0 {
+		writeError(w, fmt.Errorf("no charities registered with company number %s: %w", companyNumber, apperrors.ErrNotFound))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"company_number": companyNumber,
+		"results":        charities,
+		"total":          len(charities),
+	})
+}
+
+// compareWarning explains why a requested charity is missing from a comparison.
+// Code is CodeInvalidInput for a malformed number or CodeCharityNotFound for one
+// that isn't in the database.
+type compareWarning struct {
+	Number  string `json:"number"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 func (h *CharityHandler) CompareCharities(w http.ResponseWriter, r *http.Request) {
 	numbersStr := strings.TrimSpace(r.URL.Query().Get("numbers"))
 	if numbersStr == "" {
-		http.Error(w, "Query parameter 'numbers' is required", http.StatusBadRequest)
+		writeError(w, apperrors.ValidationError{Field: "numbers", Message: "Query parameter 'numbers' is required"})
 		return
 	}
 
 	numberStrs := strings.Split(numbersStr, ",")
-	if len(numberStrs) > 5 {
-		http.Error(w, "Cannot compare more than 5 charities", http.StatusBadRequest)
+	maxCharities := h.Cfg.MaxCompareCharities
+	if len(numberStrs) > maxCharities {
+		writeError(w, apperrors.ValidationError{Field: "numbers", Message: fmt.Sprintf("Cannot compare more than %d charities", maxCharities)})
 		return
 	}
 	if len(numberStrs) < 2 {
-		http.Error(w, "Please provide at least 2 charity numbers to compare", http.StatusBadRequest)
+		writeError(w, apperrors.ValidationError{Field: "numbers", Message: "Please provide at least 2 charity numbers to compare"})
 		return
 	}
 
 	var charities []models.Charity
 	var scores []models.CharityScore
+	warnings := []compareWarning{}
 
 	for _, numStr := range numberStrs {
 		numStr = strings.TrimSpace(numStr)
 		number, err := strconv.Atoi(numStr)
+		if err == nil {
+			err = validation.ValidateCharityNumber(number)
+		}
 		if err != nil {
-			continue // skip invalid
+			warnings = append(warnings, compareWarning{
+				Number:  numStr,
+				Code:    CodeInvalidInput,
+				Message: "Not a valid charity number",
+			})
+			continue
 		}
 
 		var charity models.Charity
 		var address, website sql.NullString
-		err = h.DB.QueryRow(`
+		err = h.dbs.Reader().QueryRow(`
 			SELECT registered_number, name, status, address, website
 			FROM charities WHERE registered_number = ? AND linked_charity_number = 0
 		`, number).Scan(&charity.RegisteredNumber, &charity.Name, &charity.Status, &address, &website)
-		if err == nil {
-			// Convert NullString to string
-			if address.Valid {
-				charity.Address = address.String
-			}
-			if website.Valid {
-				charity.Website = website.String
-			}
+		if errors.Is(err, sql.ErrNoRows) {
+			warnings = append(warnings, compareWarning{
+				Number:  numStr,
+				Code:    CodeCharityNotFound,
+				Message: "Charity not found",
+			})
+			continue
+		}
+		if err != nil {
+			writeError(w, fmt.Errorf("loading charity %d for comparison: %w", number, err))
+			return
+		}
 
-			charities = append(charities, charity)
+		// Convert NullString to string
+		if address.Valid {
+			charity.Address = address.String
+		}
+		if website.Valid {
+			charity.Website = website.String
+		}
+
+		charities = append(charities, charity)
 
-			var score models.CharityScore
-			h.DB.QueryRow(`
-				SELECT overall_score, efficiency_score, financial_health_score,
-				       transparency_score, governance_score
-				FROM charity_scores WHERE charity_number = ?
-			`, number).Scan(&score.OverallScore, &score.EfficiencyScore, &score.FinancialHealthScore,
-				&score.TransparencyScore, &score.GovernanceScore)
-			scores = append(scores, score)
+		var score models.CharityScore
+		err = h.dbs.Reader().QueryRow(`
+			SELECT overall_score, efficiency_score, financial_health_score,
+			       transparency_score, governance_score, completeness_score, scoring_version
+			FROM charity_scores WHERE charity_number = ?
+		`, number).Scan(&score.OverallScore, &score.EfficiencyScore, &score.FinancialHealthScore,
+			&score.TransparencyScore, &score.GovernanceScore, &score.CompletenessScore, &score.ScoringVersion)
+
+		// Recalculate scores cached by an older version of the formula
+		if err == nil && score.ScoringVersion < scoring.ScoringVersion {
+			if fresh, err := scoring.CalculateScore(h.DB, number, !h.Cfg.OfflineMode); err == nil {
+				score = fresh
+			}
 		}
+		scores = append(scores, score)
+	}
+
+	if wantsCSV(r) {
+		writeCompareCSV(w, h.dbs.Reader(), charities, scores)
+		return
 	}
 
 	response := struct {
-		Charities []models.Charity      `json:"charities"`
-		Scores    []models.CharityScore `json:"scores"`
+		Charities    []models.Charity      `json:"charities"`
+		Scores       []models.CharityScore `json:"scores"`
+		Warnings     []compareWarning      `json:"warnings"`
+		MaxCharities int                   `json:"max_charities"`
 	}{
-		Charities: charities,
-		Scores:    scores,
+		Charities:    charities,
+		Scores:       scores,
+		Warnings:     warnings,
+		MaxCharities: maxCharities,
 	}
 
 	writeJSON(w, http.StatusOK, response)
 }
 
+// ListCategories returns all classification codes with the number of charities in each
+func (h *CharityHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	classificationType := strings.TrimSpace(r.URL.Query().Get("type"))
+
+	// Count main, non-removed charities per classification code
+	rows, err := h.dbs.Reader().Query(`
+		SELECT cc.classification_code, COALESCE(MAX(cc.classification_type), ''),
+		       COALESCE(MAX(cc.classification_description), ''),
+		       COUNT(DISTINCT cc.registered_charity_number)
+		FROM charity_classifications cc
+		JOIN charities c ON c.registered_number = cc.registered_charity_number
+		WHERE c.linked_charity_number = 0
+		  AND `+database.ListedSQL("c.status")+`
+		  AND (? = '' OR LOWER(cc.classification_type) = LOWER(?))
+		GROUP BY cc.classification_code
+		ORDER BY cc.classification_code
+	`, classificationType, classificationType)
+	if err != nil {
+		writeError(w, fmt.Errorf("listing categories: %w", err))
+		return
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.Code, &category.Type, &category.Description, &category.CharityCount); err != nil {
+			logger.Error("Error scanning category", "error", err)
+			continue
+		}
+		categories = append(categories, category)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"categories": categories,
+		"total":      len(categories),
+	})
+}
+
 func (h *CharityHandler) SyncData(w http.ResponseWriter, r *http.Request) {
 	// Reject sync requests in offline mode
 	if h.Cfg.OfflineMode {
-		http.Error(w, "Sync is disabled in offline mode", http.StatusForbidden)
+		writeError(w, fmt.Errorf("sync is disabled in offline mode: %w", apperrors.ErrForbidden))
 		return
 	}
 
 	// Check for admin authentication
-	if h.Cfg.AdminAPIKey != "" {
-		authHeader := r.Header.Get("Authorization")
-		expectedAuth := "Bearer " + h.Cfg.AdminAPIKey
-		if authHeader != expectedAuth {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
+	if h.Cfg.AdminAPIKey != "" && !h.isAdmin(r) {
+		writeError(w, apperrors.ErrUnauthorized)
+		return
 	}
 
 	if err := sync.SyncCharities(h.Cfg, h.DB); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, fmt.Errorf("sync failed: %w", err))
 		return
 	}
+	h.pages.clear()
 	writeJSON(w, http.StatusOK, map[string]string{"status": "sync completed"})
 }
 
+// GetKeyStats returns live per-key usage for the server's API client. Unlike sync,
+// it requires AdminAPIKey to be configured.
+func (h *CharityHandler) GetKeyStats(w http.ResponseWriter, r *http.Request) {
+	if h.Cfg.AdminAPIKey == "" {
+		writeError(w, fmt.Errorf("admin API key is not configured: %w", apperrors.ErrForbidden))
+		return
+	}
+	if !h.isAdmin(r) {
+		writeError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	if h.Cfg.OfflineMode {
+		writeError(w, fmt.Errorf("the API client is disabled in offline mode: %w", apperrors.ErrForbidden))
+		return
+	}
+
+	client := sync.Client(h.Cfg)
+	available, refused := client.GetRetryBudgetStats()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": client.GetKeyUsage(),
+		"retry_budget": map[string]any{
+			"available": available,
+			"refused":   refused,
+		},
+		"call_budget": sync.APIBudgetStats(),
+	})
+}
+
+// GetAPIBudget reports how much of the hourly and daily API budget set by
+// API_BUDGET_HOURLY and API_BUDGET_DAILY has been used, and when each resets.
+// Like GetKeyStats it requires AdminAPIKey to be configured.
+func (h *CharityHandler) GetAPIBudget(w http.ResponseWriter, r *http.Request) {
+	if h.Cfg.AdminAPIKey == "" {
+		writeError(w, fmt.Errorf("admin API key is not configured: %w", apperrors.ErrForbidden))
+		return
+	}
+	if !h.isAdmin(r) {
+		writeError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	if h.Cfg.OfflineMode {
+		writeError(w, fmt.Errorf("the API client is disabled in offline mode: %w", apperrors.ErrForbidden))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sync.APIBudgetStats())
+}
+
+// retrySyncsTimeout bounds a RetryFailedSyncs request, leaving it time to respond
+// before the API's 30 second request timeout
+const retrySyncsTimeout = 20 * time.Second
+
+// RetryFailedSyncs retries failed background syncs, oldest first, and reports how
+// each went. limit (default 50, at most 500) caps how many are retried; any not
+// reached within retrySyncsTimeout are counted in remaining for a later request.
+// Like GetKeyStats it requires AdminAPIKey to be configured.
+func (h *CharityHandler) RetryFailedSyncs(w http.ResponseWriter, r *http.Request) {
+	if h.Cfg.AdminAPIKey == "" {
+		writeError(w, fmt.Errorf("admin API key is not configured: %w", apperrors.ErrForbidden))
+		return
+	}
+	if !h.isAdmin(r) {
+		writeError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	if h.Cfg.OfflineMode {
+		writeError(w, fmt.Errorf("sync is disabled in offline mode: %w", apperrors.ErrForbidden))
+		return
+	}
+
+	limit, _ := parsePage(r, 50, 500)
+	ctx, cancel := context.WithTimeout(r.Context(), retrySyncsTimeout)
+	defer cancel()
+
+	report, err := sync.RetryFailedSyncs(ctx, h.Cfg, h.DB, limit)
+	if err != nil {
+		writeError(w, fmt.Errorf("retrying failed syncs: %w", err))
+		return
+	}
+	if report.Succeeded > 0 {
+		h.pages.clear()
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// isAdmin reports whether the request carries the admin API key as a bearer token
+func (h *CharityHandler) isAdmin(r *http.Request) bool {
+	expectedAuth := "Bearer " + h.Cfg.AdminAPIKey
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expectedAuth)) == 1
+}
+
 func (h *CharityHandler) processSearchResults(results []map[string]any, limit int) []models.Charity {
-	h.debugLog("PROCESSING SEARCH RESULTS: %d total", len(results))
+	logger.Debug("Processing search results", "operation", "search", "total", len(results))
 	var charities []models.Charity
 	rmCount := 0
 
@@ -505,44 +926,24 @@ func (h *CharityHandler) processSearchResults(results []map[string]any, limit in
 		}
 
 		// Extract charity data from search result
-		h.debugLog("Search result raw data: %+v", result)
+		logger.Debug("Search result raw data", "operation", "search", "result", result)
 
 		// Try different possible field names for registration number
 		// Note: The search API sometimes returns organisation_number which might differ from reg_charity_number
 		possibleRegFields := []string{"registered_charity_number", "reg_charity_number", "charity_registration_number"}
 		for _, field := range possibleRegFields {
-			if rn, ok := result[field]; ok && rn != nil {
-				h.debugLog("Found reg number in field %s: %v (type: %T)", field, rn, rn)
-				switch v := rn.(type) {
-				case string:
-					if parsed, err := strconv.Atoi(v); err == nil {
-						charity.RegisteredNumber = parsed
-					}
-				case float64:
-					charity.RegisteredNumber = int(v)
-				case int:
-					charity.RegisteredNumber = v
-				}
-				if charity.RegisteredNumber != 0 {
-					break // Found a valid number
-				}
+			if rn, ok := api.ParseFlexibleInt(result[field]); ok && rn != 0 {
+				logger.Debug("Found registration number", "operation", "search", "field", field, "value", rn)
+				charity.RegisteredNumber = rn
+				break // Found a valid number
 			}
 		}
 
 		// If we still don't have a number, try organisation_number as fallback
 		if charity.RegisteredNumber == 0 {
-			if orgNum, ok := result["organisation_number"]; ok && orgNum != nil {
-				h.debugLog("Warning: Using organisation_number as fallback: %v", orgNum)
-				switch v := orgNum.(type) {
-				case string:
-					if parsed, err := strconv.Atoi(v); err == nil {
-						charity.RegisteredNumber = parsed
-					}
-				case float64:
-					charity.RegisteredNumber = int(v)
-				case int:
-					charity.RegisteredNumber = v
-				}
+			if orgNum, ok := api.ParseFlexibleInt(result["organisation_number"]); ok {
+				logger.Debug("Using organisation_number as fallback", "operation", "search", "organisation_number", orgNum)
+				charity.RegisteredNumber = orgNum
 			}
 		}
 		if name, ok := result["charity_name"].(string); ok {
@@ -553,22 +954,28 @@ func (h *CharityHandler) processSearchResults(results []map[string]any, limit in
 		}
 
 		// Skip removed charities (status RM with non-null date_of_removal)
-		if charity.Status == "RM" {
+		if database.IsRemoved(charity.Status) {
 			removalDate, ok := result["date_of_removal"]
-			h.debugLog("RM charity check: %s, has field: %v, value: %v, type: %T", charity.Name, ok, removalDate, removalDate)
+			logger.Debug("RM charity check", "operation", "search", "name", charity.Name, "has_removal_date", ok, "date_of_removal", removalDate)
 			if ok {
 				if str, isString := removalDate.(string); isString && str != "" {
-					h.debugLog("Skipping removed charity: %s (removed: %s)", charity.Name, str)
+					logger.Debug("Skipping removed charity", "operation", "search", "charity_number", charity.RegisteredNumber, "name", charity.Name, "date_of_removal", str)
 					rmCount++
 					continue // Skip this charity
 				}
 			}
 		}
 
-		h.debugLog("Processed search result: reg_num=%d, name=%s, status=%s", charity.RegisteredNumber, charity.Name, charity.Status)
+		// Leave out charities with a hidden status, as database searches do
+		if !database.IsRemoved(charity.Status) && !database.IsListed(charity.Status) {
+			logger.Debug("Skipping charity with hidden status", "operation", "search", "charity_number", charity.RegisteredNumber, "status", charity.Status)
+			continue
+		}
+
+		logger.Debug("Processed search result", "operation", "search", "charity_number", charity.RegisteredNumber, "name", charity.Name, "status", charity.Status)
 
-		// Trigger background operations for this charity (only if not in offline mode)
-		if !h.Cfg.OfflineMode && charity.RegisteredNumber > 0 {
+		// Trigger background operations for this charity (only if search side effects are allowed)
+		if h.searchSideEffects() && charity.RegisteredNumber > 0 {
 			var exists bool
 			var hasScore bool
 
@@ -577,46 +984,43 @@ func (h *CharityHandler) processSearchResults(results []map[string]any, limit in
 
 			// Sync charity data if it doesn't exist
 			if !exists {
-				h.debugLog("Triggering background sync for charity %d", charity.RegisteredNumber)
-				go func(charityNum int, cfg *config.Config) {
-					charityNumStr := strconv.Itoa(charityNum)
-					if err := sync.FetchAndStoreCharity(cfg, h.DB, charityNumStr); err != nil {
-						log.Printf("Background sync failed for charity %s: %v", charityNumStr, err)
-					} else {
-						h.debugLog("Background sync completed for charity %s", charityNumStr)
-
-						// After sync, calculate score
-						if score, err := scoring.CalculateScore(h.DB, charityNum); err == nil {
-							h.debugLog("Score calculated for charity %d: %.2f", charityNum, score.OverallScore)
-						}
+				logger.Debug("Triggering background sync", "operation", "sync", "charity_number", charity.RegisteredNumber)
+				charityNum := charity.RegisteredNumber
+				sync.SyncInBackground(h.Cfg, h.DB, charityNum, func() {
+					// After sync, calculate score
+					if score, err := scoreInBackground(h.DB, charityNum); err == nil {
+						logger.Debug("Score calculated", "operation", "score", "charity_number", charityNum, "score", score.OverallScore)
 					}
-				}(charity.RegisteredNumber, h.Cfg)
+					h.pages.invalidate(charityNum)
+				})
 			} else if !hasScore {
 				// Charity exists but no score - check if it has financial data before calculating
-				h.debugLog("Checking if charity %d has financial data for scoring", charity.RegisteredNumber)
-				go func(charityNum int) {
+				logger.Debug("Checking for financial data before scoring", "operation", "score", "charity_number", charity.RegisteredNumber)
+				charityNum := charity.RegisteredNumber
+				background.Go("score", func() {
 					// Check if charity has financial data (required for scoring)
 					var hasFinancials bool
-					h.DB.QueryRow("SELECT 1 FROM financials WHERE charity_number = ?", charityNum).Scan(&hasFinancials)
+					h.DB.QueryRowContext(backgroundCtx, "SELECT 1 FROM financials WHERE charity_number = ?", charityNum).Scan(&hasFinancials)
 
 					if hasFinancials {
-						if score, err := scoring.CalculateScore(h.DB, charityNum); err == nil {
-							h.debugLog("Score calculated for charity %d: %.2f", charityNum, score.OverallScore)
+						if score, err := scoreInBackground(h.DB, charityNum); err == nil {
+							logger.Debug("Score calculated", "operation", "score", "charity_number", charityNum, "score", score.OverallScore)
+							h.pages.invalidate(charityNum)
 						} else {
-							log.Printf("Score calculation failed for charity %d: %v", charityNum, err)
+							logger.Error("Score calculation failed", "operation", "score", "charity_number", charityNum, "error", err)
 						}
 					} else {
-						h.debugLog("Charity %d has no financial data yet, skipping score calculation", charityNum)
+						logger.Debug("No financial data yet, skipping score calculation", "operation", "score", "charity_number", charityNum)
 					}
-				}(charity.RegisteredNumber)
+				})
 			} else {
-				h.debugLog("Charity %d already exists with score in database", charity.RegisteredNumber)
+				logger.Debug("Charity already exists with score in database", "operation", "search", "charity_number", charity.RegisteredNumber)
 			}
 		}
 
 		charities = append(charities, charity)
 	}
 
-	h.debugLog("Returning %d charities from search (filtered %d RM charities)", len(charities), rmCount)
+	logger.Debug("Returning charities from search", "operation", "search", "results", len(charities), "removed_filtered", rmCount)
 	return charities
 }
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"alpha trust", "alpha trust"},
+		{"100%", "100!%"},
+		{"a_b", "a!_b"},
+		{`c:\temp`, `c:\temp`},
+		{"hello!", "hello!!"},
+		{`!%_\`, `!!!%!_\`},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestEscapeLikeMatches checks escaped patterns only match themselves when used
+// with ESCAPE '!' as the search queries do
+func TestEscapeLikeMatches(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		name, query string
+		want        bool
+	}{
+		{"100% fund", "100%", true},
+		{"1000 fund", "100%", false},
+		{"a_b trust", "a_b", true},
+		{"axb trust", "a_b", false},
+		{`c:\temp`, `\t`, true},
+		{"ctemp", `\t`, false},
+		{"hello! trust", "hello!", true},
+		{"hello trust", "hello!", false},
+	}
+
+	for _, tt := range tests {
+		var matched bool
+		if err := db.QueryRow(`SELECT ? LIKE ? ESCAPE '!'`, tt.name, "%"+escapeLike(tt.query)+"%").Scan(&matched); err != nil {
+			t.Fatalf("matching %q: %v", tt.query, err)
+		}
+		if matched != tt.want {
+			t.Errorf("%q matching %q = %v, want %v", tt.name, tt.query, matched, tt.want)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"charitylens/internal/config"
+	"charitylens/internal/database"
+	apperrors "charitylens/internal/errors"
+	"charitylens/internal/logger"
+	"charitylens/internal/models"
+	"charitylens/internal/scoring"
+)
+
+// detailPageSize is how many trustees or activities are loaded with a charity,
+// and returned per page by the paginated endpoints by default
+const (
+	detailPageSize    = 25
+	maxDetailPageSize = 100
+)
+
+// CharityDetail is everything shown for a single charity, shared by the
+// JSON API and the HTML detail page
+type CharityDetail struct {
+	Charity    models.Charity          `json:"charity"`
+	Score      models.CharityScore     `json:"score"`
+	ScoreError string                  `json:"score_error,omitempty"`
+	Financial  models.Financial        `json:"financial"`
+	Metrics    models.FinancialMetrics `json:"metrics"`
+	Trustees   []models.Trustee        `json:"trustees"`   // First page only
+	Activities []models.Activity       `json:"activities"` // First page only
+
+	TrusteesTotal   int `json:"trustees_total"`
+	ActivitiesTotal int `json:"activities_total"`
+
+	// Removal is why the charity was removed from the register, set only for
+	// removed charities whose reason is known
+	Removal *models.CharityRemoval `json:"removal,omitempty"`
+}
+
+// loadCharity loads a main charity record (linked_charity_number = 0) by registered number.
+// Returns sql.ErrNoRows if the charity is not in the database.
+func loadCharity(db *sql.DB, number int) (models.Charity, error) {
+	var charity models.Charity
+	var website, email, phone, address, whatTheCharityDoes sql.NullString
+	var dataExtractDate sql.NullTime
+	var detailsSource, financialsSource, trusteesSource sql.NullString
+	err := db.QueryRow(`
+		SELECT registered_number, name, status, date_registered, address, website,
+		       email, phone, what_the_charity_does, data_extract_date,
+		       details_source, financials_source, trustees_source
+		FROM charities WHERE registered_number = ? AND linked_charity_number = 0
+	`, number).Scan(
+		&charity.RegisteredNumber, &charity.Name, &charity.Status,
+		&charity.DateRegistered, &address, &website,
+		&email, &phone, &whatTheCharityDoes, &dataExtractDate,
+		&detailsSource, &financialsSource, &trusteesSource,
+	)
+	if err != nil {
+		return charity, err
+	}
+
+	// Convert NullString to string
+	if address.Valid {
+		charity.Address = address.String
+	}
+	if website.Valid {
+		charity.Website = website.String
+	}
+	if email.Valid {
+		charity.Email = email.String
+	}
+	if phone.Valid {
+		charity.Phone = phone.String
+	}
+	if whatTheCharityDoes.Valid {
+		charity.WhatTheCharityDoes = whatTheCharityDoes.String
+	}
+	if dataExtractDate.Valid {
+		charity.DataExtractDate = &dataExtractDate.Time
+	}
+	charity.DataSources = &models.DataSources{
+		Charity:    detailsSource.String,
+		Financials: financialsSource.String,
+		Trustees:   trusteesSource.String,
+	}
+
+	return charity, nil
+}
+
+// loadCharityWithScore loads a charity with its score, latest financials, trustees
+// and activities. A scoring failure is reported in ScoreError rather than returned,
+// so the charity can still be displayed. Scores are not cached in offline mode, or
+// when the stored score is already current, so viewing a charity doesn't move its
+// score's last_calculated on. The score is calculated on the primary, which it's
+// cached to; everything else is read from the replica, if any.
+func loadCharityWithScore(dbs *database.DB, number int, offline bool) (CharityDetail, error) {
+	db := dbs.Reader()
+	charity, err := loadCharity(db, number)
+	if err != nil {
+		return CharityDetail{}, err
+	}
+
+	detail := CharityDetail{
+		Charity:    charity,
+		Trustees:   []models.Trustee{},
+		Activities: []models.Activity{},
+	}
+
+	if database.IsRemoved(charity.Status) {
+		detail.Removal, err = loadRemoval(db, number)
+		if err != nil {
+			logger.Error("Failed to get removal reason", "operation", "load", "charity_number", number, "error", err)
+		}
+	}
+
+	// Always recalculate rather than reading charity_scores, so the score reflects
+	// the current data and scoring.ScoringVersion
+	cacheScore := !offline
+	if times, err := loadCharityTimes(db, number); err == nil && times.scoreIsCurrent() {
+		cacheScore = false
+	}
+	score, err := scoring.CalculateScore(dbs.Writer(), number, cacheScore)
+	if err != nil {
+		// If error, continue without score but log it
+		logger.Error("Error calculating score", "operation", "score", "charity_number", number, "error", err)
+		detail.ScoreError = err.Error()
+		score = models.CharityScore{
+			CharityNumber: number,
+		}
+	}
+	detail.Score = score
+
+	// Get financial data (latest year only)
+	detail.Financial, err = loadLatestFinancial(db, number)
+	if err == nil {
+		detail.Metrics = scoring.FinancialMetrics(detail.Financial)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("Failed to get financial data", "operation", "load", "charity_number", number, "error", err)
+	}
+
+	// Get the first page of trustees and activities; the rest are served by the
+	// paginated endpoints
+	detail.Trustees, detail.TrusteesTotal, err = loadTrustees(db, number, detailPageSize, 0)
+	if err != nil {
+		return detail, err
+	}
+	detail.Activities, detail.ActivitiesTotal, err = loadActivities(db, number, detailPageSize, 0)
+	if err != nil {
+		return detail, err
+	}
+
+	return detail, nil
+}
+
+// charityTimes is when a main charity's data and its stored score last changed
+type charityTimes struct {
+	removed        bool
+	dataUpdated    time.Time // Latest last_updated of the charity, its financials and trustees
+	scored         time.Time // When the stored score was calculated; zero without one
+	scoringVersion int
+}
+
+// loadCharityTimes loads when a main charity's data and stored score last changed.
+// Returns sql.ErrNoRows if the charity isn't stored.
+func loadCharityTimes(db *sql.DB, number int) (charityTimes, error) {
+	var times charityTimes
+	var status sql.NullString
+	var lastUpdated sql.NullTime
+	err := db.QueryRow(`
+		SELECT status, last_updated FROM charities
+		WHERE registered_number = ? AND linked_charity_number = 0
+	`, number).Scan(&status, &lastUpdated)
+	if err != nil {
+		return charityTimes{}, err
+	}
+	times.removed = database.IsRemoved(status.String)
+	times.dataUpdated = lastUpdated.Time
+
+	// Financials and trustees are updated by their own imports, without touching
+	// the charity's row
+	for _, query := range []string{
+		`SELECT last_updated FROM financials WHERE charity_number = ? AND last_updated IS NOT NULL
+		 ORDER BY last_updated DESC LIMIT 1`,
+		`SELECT last_updated FROM trustees WHERE charity_number = ? AND last_updated IS NOT NULL
+		 ORDER BY last_updated DESC LIMIT 1`,
+	} {
+		var updated sql.NullTime
+		if err := db.QueryRow(query, number).Scan(&updated); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return charityTimes{}, err
+		}
+		if updated.Time.After(times.dataUpdated) {
+			times.dataUpdated = updated.Time
+		}
+	}
+
+	var scored sql.NullTime
+	var version sql.NullInt64
+	err = db.QueryRow(`
+		SELECT last_calculated, scoring_version FROM charity_scores WHERE charity_number = ?
+	`, number).Scan(&scored, &version)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return charityTimes{}, err
+	}
+	times.scored = scored.Time
+	times.scoringVersion = int(version.Int64)
+	return times, nil
+}
+
+// scoreIsCurrent reports whether the stored score was calculated by the current
+// scoring version since the charity's data last changed, so recalculating it for
+// display needn't store it again. A removed charity's never is, so its stale
+// score is dropped.
+func (t charityTimes) scoreIsCurrent() bool {
+	return !t.removed && !t.scored.IsZero() && t.scoringVersion == scoring.ScoringVersion &&
+		!t.scored.Before(t.dataUpdated)
+}
+
+// loadRemoval loads why a charity was removed from the register, or nil if the
+// event history hasn't been imported or has no removal for it
+func loadRemoval(db *sql.DB, number int) (*models.CharityRemoval, error) {
+	removal := models.CharityRemoval{CharityNumber: number}
+	var reason, successorName sql.NullString
+	var dateRemoved sql.NullTime
+	var successorNumber sql.NullInt64
+	err := db.QueryRow(`
+		SELECT reason, date_removed, successor_charity_number, successor_name
+		FROM charity_removals WHERE charity_number = ?
+	`, number).Scan(&reason, &dateRemoved, &successorNumber, &successorName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	removal.Reason = reason.String
+	if dateRemoved.Valid {
+		removal.DateRemoved = &dateRemoved.Time
+	}
+	removal.SuccessorCharityNumber = int(successorNumber.Int64)
+	removal.SuccessorName = successorName.String
+	return &removal, nil
+}
+
+// loadTrustees loads one page of a charity's trustees ordered by name, with the
+// total number of trustees
+func loadTrustees(db *sql.DB, number, limit, offset int) ([]models.Trustee, int, error) {
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM trustees WHERE charity_number = ?`, number).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(`
+		SELECT name FROM trustees WHERE charity_number = ?
+		ORDER BY name
+		LIMIT ? OFFSET ?
+	`, number, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	trustees := []models.Trustee{}
+	for rows.Next() {
+		trustee := models.Trustee{CharityNumber: number}
+		if err := rows.Scan(&trustee.Name); err != nil {
+			return nil, 0, err
+		}
+		trustees = append(trustees, trustee)
+	}
+	return trustees, total, rows.Err()
+}
+
+// loadActivities loads one page of a charity's activities ordered by description,
+// with the total number of activities
+func loadActivities(db *sql.DB, number, limit, offset int) ([]models.Activity, int, error) {
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM activities WHERE charity_number = ?`, number).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(`
+		SELECT description FROM activities WHERE charity_number = ?
+		ORDER BY description
+		LIMIT ? OFFSET ?
+	`, number, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	activities := []models.Activity{}
+	for rows.Next() {
+		activity := models.Activity{CharityNumber: number}
+		if err := rows.Scan(&activity.Description); err != nil {
+			return nil, 0, err
+		}
+		activities = append(activities, activity)
+	}
+	return activities, total, rows.Err()
+}
+
+// loadLatestFinancial loads a charity's most recent financial year, with its
+// annual return Part A if there is one. Returns sql.ErrNoRows if the charity has
+// no financial data. Trustees is counted from the trustees table, as scoring
+// does, rather than read from financials.
+func loadLatestFinancial(db *sql.DB, number int) (models.Financial, error) {
+	var fin models.Financial
+	err := db.QueryRow(`
+		SELECT financial_year_end, total_income, total_spending, charitable_activities_spend,
+		       raising_funds_spend, other_spend, reserves, assets,
+		       (SELECT COUNT(*) FROM trustees WHERE charity_number = f.charity_number)
+		FROM financials f WHERE charity_number = ?
+		ORDER BY financial_year_end DESC LIMIT 1
+	`, number).Scan(
+		&fin.FinancialYearEnd, &fin.TotalIncome, &fin.TotalSpending,
+		&fin.CharitableActivitiesSpend, &fin.RaisingFundsSpend,
+		&fin.OtherSpend, &fin.Reserves, &fin.Assets, &fin.Trustees,
+	)
+	if err != nil {
+		return models.Financial{}, err
+	}
+
+	fin.CharityNumber = number
+	if err := scoring.LoadPartA(context.Background(), db, &fin); err != nil {
+		return models.Financial{}, err
+	}
+	return fin, nil
+}
+
+// serveCharityJSON writes the JSON payload for a charity, shared by the API
+// endpoint and the content-negotiated web page.
+func serveCharityJSON(w http.ResponseWriter, dbs *database.DB, cfg *config.Config, number int) {
+	detail, err := loadCharityWithScore(dbs, number, cfg.OfflineMode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = apperrors.CharityNotFoundError{Number: number}
+		}
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// wantsJSON reports whether the request's Accept header asks for JSON rather than HTML
+func wantsJSON(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+
+	json, html := false, false
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json":
+			json = true
+		case "text/html":
+			html = true
+		}
+	}
+	return json && !html
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"charitylens/internal/logger"
+	"charitylens/internal/models"
+)
+
+// compareCSVHeader lists the columns of a comparison export, one row per charity
+var compareCSVHeader = []string{
+	"registered_number", "name", "status",
+	"overall_score", "efficiency_score", "financial_health_score", "transparency_score", "governance_score",
+	"financial_year_end", "total_income", "total_spending", "charitable_activities_spend",
+	"raising_funds_spend", "reserves", "assets",
+}
+
+// wantsCSV reports whether a compare request asked for CSV, either through the
+// .csv path suffix or an Accept header
+func wantsCSV(r *http.Request) bool {
+	return strings.HasSuffix(r.URL.Path, ".csv") || strings.Contains(r.Header.Get("Accept"), "text/csv")
+}
+
+// writeCompareCSV writes a comparison as a CSV download. charities and scores are
+// parallel slices as built by CompareCharities; financials are from each charity's
+// latest year and left blank when there is none.
+func writeCompareCSV(w http.ResponseWriter, db *sql.DB, charities []models.Charity, scores []models.CharityScore) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="charity-comparison.csv"`)
+
+	cw := csv.NewWriter(w)
+	cw.Write(compareCSVHeader)
+
+	for i, charity := range charities {
+		score := scores[i]
+		row := []string{
+			strconv.Itoa(charity.RegisteredNumber), csvSafe(charity.Name), charity.Status,
+			formatScore(score.OverallScore), formatScore(score.EfficiencyScore),
+			formatScore(score.FinancialHealthScore), formatScore(score.TransparencyScore),
+			formatScore(score.GovernanceScore),
+		}
+
+		fin, err := loadLatestFinancial(db, charity.RegisteredNumber)
+		if err == nil {
+			row = append(row,
+				fin.FinancialYearEnd.Format("2006-01-02"),
+				formatAmount(fin.TotalIncome), formatAmount(fin.TotalSpending),
+				formatAmount(fin.CharitableActivitiesSpend), formatAmount(fin.RaisingFundsSpend),
+				formatAmount(fin.Reserves), formatAmount(fin.Assets),
+			)
+		} else {
+			if !errors.Is(err, sql.ErrNoRows) {
+				logger.Error("Failed to get financial data", "operation", "compare", "charity_number", charity.RegisteredNumber, "error", err)
+			}
+			row = append(row, make([]string, len(compareCSVHeader)-len(row))...)
+		}
+
+		cw.Write(row)
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.Error("Error writing comparison CSV", "operation", "compare", "error", err)
+	}
+}
+
+// formatScore formats a 0-100 score to one decimal place
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 1, 64)
+}
+
+// formatAmount formats a money amount without trailing zeros or exponent
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+// csvSafe stops text that begins with a formula character from being evaluated
+// when the file is opened in a spreadsheet
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
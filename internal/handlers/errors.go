@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	apperrors "charitylens/internal/errors"
+	"charitylens/internal/logger"
+)
+
+// Machine-readable error codes returned in API error responses. These are part of
+// the API contract and must not change once published.
+const (
+	CodeInvalidInput     = "invalid_input"
+	CodeCharityNotFound  = "charity_not_found"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeConflict         = "conflict"
+	CodeRateLimited      = "rate_limited"
+	CodeUpstreamError    = "upstream_error"
+	CodeInternalError    = "internal_error"
+)
+
+// ErrorBody is the payload of an API error response
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// ErrorResponse is the JSON shape of every /api error response
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// writeError maps an application error to a status code and machine-readable code
+// and writes it as JSON. Unrecognised errors are logged and reported as a generic
+// internal error so details aren't leaked to clients.
+func writeError(w http.ResponseWriter, err error) {
+	status, body := errorBody(err)
+	if status == http.StatusInternalServerError {
+		logger.Error("Internal error", "error", err)
+	}
+	writeJSON(w, status, ErrorResponse{Error: body})
+}
+
+// APINotFound responds to unknown /api routes
+func APINotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, fmt.Errorf("no such endpoint: %w", apperrors.ErrNotFound))
+}
+
+// APIMethodNotAllowed responds to /api routes called with an unsupported method
+func APIMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: ErrorBody{
+		Code:    CodeMethodNotAllowed,
+		Message: "Method not allowed",
+	}})
+}
+
+// errorBody returns the HTTP status and response body for an error
+func errorBody(err error) (int, ErrorBody) {
+	var validationErr apperrors.ValidationError
+	var notFoundErr apperrors.CharityNotFoundError
+	var apiErr apperrors.APIError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest, ErrorBody{Code: CodeInvalidInput, Message: validationErr.Message, Field: validationErr.Field}
+	case errors.As(err, &notFoundErr):
+		return http.StatusNotFound, ErrorBody{Code: CodeCharityNotFound, Message: "Charity not found"}
+	case errors.As(err, &apiErr), errors.Is(err, apperrors.ErrExternalAPI):
+		return http.StatusBadGateway, ErrorBody{Code: CodeUpstreamError, Message: "Charity Commission API error"}
+	case errors.Is(err, apperrors.ErrInvalidInput):
+		return http.StatusBadRequest, ErrorBody{Code: CodeInvalidInput, Message: messageFor(err, apperrors.ErrInvalidInput)}
+	case errors.Is(err, apperrors.ErrNotFound):
+		return http.StatusNotFound, ErrorBody{Code: CodeNotFound, Message: messageFor(err, apperrors.ErrNotFound)}
+	case errors.Is(err, apperrors.ErrUnauthorized):
+		return http.StatusUnauthorized, ErrorBody{Code: CodeUnauthorized, Message: messageFor(err, apperrors.ErrUnauthorized)}
+	case errors.Is(err, apperrors.ErrForbidden):
+		return http.StatusForbidden, ErrorBody{Code: CodeForbidden, Message: messageFor(err, apperrors.ErrForbidden)}
+	case errors.Is(err, apperrors.ErrConflict):
+		return http.StatusConflict, ErrorBody{Code: CodeConflict, Message: messageFor(err, apperrors.ErrConflict)}
+	case errors.Is(err, apperrors.ErrRateLimit):
+		return http.StatusTooManyRequests, ErrorBody{Code: CodeRateLimited, Message: messageFor(err, apperrors.ErrRateLimit)}
+	default:
+		return http.StatusInternalServerError, ErrorBody{Code: CodeInternalError, Message: "Internal server error"}
+	}
+}
+
+// messageFor returns the context wrapped around a sentinel error as a sentence, e.g.
+// "Sync is disabled in offline mode" for fmt.Errorf("sync is disabled in offline mode: %w", ErrForbidden),
+// or the sentinel's own text when it was returned unwrapped
+func messageFor(err, sentinel error) string {
+	message := strings.TrimSuffix(err.Error(), ": "+sentinel.Error())
+	if message == "" {
+		return message
+	}
+	return strings.ToUpper(message[:1]) + message[1:]
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"charitylens/internal/database"
+	apperrors "charitylens/internal/errors"
+	"charitylens/internal/logger"
+)
+
+// exportCSVHeader lists the columns of the full export, one row per charity. New
+// columns are only ever appended, so scripts can rely on the order.
+var exportCSVHeader = []string{
+	"registered_number", "name", "status", "company_number", "date_registered",
+	"overall_score", "efficiency_score", "financial_health_score", "transparency_score", "governance_score",
+	"confidence_level", "scoring_version",
+	"financial_year_end", "total_income", "total_spending", "charitable_activities_spend",
+	"raising_funds_spend", "reserves", "assets",
+}
+
+// exportFlushRows is how often the export is flushed to the client
+const exportFlushRows = 1000
+
+// ExportFullCSV streams every main, non-removed charity with its stored score and
+// latest financial year as a gzip-compressed CSV, ordered by charity number. Score
+// columns are blank for charities not yet scored and financial columns for those
+// without financial data. Like GetKeyStats it requires AdminAPIKey to be configured.
+//
+// Rows are written straight from the database cursor, so the export is never held
+// in memory, and the server's write timeout is lifted for the download.
+func (h *CharityHandler) ExportFullCSV(w http.ResponseWriter, r *http.Request) {
+	if h.Cfg.AdminAPIKey == "" {
+		writeError(w, fmt.Errorf("admin API key is not configured: %w", apperrors.ErrForbidden))
+		return
+	}
+	if !h.isAdmin(r) {
+		writeError(w, apperrors.ErrUnauthorized)
+		return
+	}
+
+	rows, err := h.DB.QueryContext(r.Context(), `
+		SELECT c.registered_number, c.name, COALESCE(c.status, ''), COALESCE(c.company_number, ''), c.date_registered,
+		       s.overall_score, s.efficiency_score, s.financial_health_score, s.transparency_score,
+		       s.governance_score, s.confidence_level, s.scoring_version,
+		       f.financial_year_end, f.total_income, f.total_spending, f.charitable_activities_spend,
+		       f.raising_funds_spend, f.reserves, f.assets
+		FROM charities c
+		LEFT JOIN charity_scores s ON s.charity_number = c.registered_number
+		LEFT JOIN financials f ON f.charity_number = c.registered_number
+		  AND f.financial_year_end = (
+			SELECT MAX(financial_year_end) FROM financials
+			WHERE charity_number = c.registered_number
+		  )
+		WHERE c.linked_charity_number = 0
+		  AND `+database.ListedSQL("c.status")+`
+		ORDER BY c.registered_number
+	`)
+	if err != nil {
+		writeError(w, fmt.Errorf("exporting charities: %w", err))
+		return
+	}
+	defer rows.Close()
+
+	// The export takes far longer than the server's WriteTimeout allows
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		logger.Warn("Could not lift write deadline for export", "operation", "export", "error", err)
+	}
+
+	filename := fmt.Sprintf("charitylens-%s.csv.gz", time.Now().UTC().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+
+	gz := gzip.NewWriter(w)
+	cw := csv.NewWriter(gz)
+	cw.Write(exportCSVHeader)
+
+	count := 0
+	for rows.Next() {
+		var number int
+		var name, status, companyNumber string
+		var dateRegistered sql.NullTime
+		var overall, efficiency, financialHealth, transparency, governance sql.NullFloat64
+		var confidence sql.NullString
+		var scoringVersion sql.NullInt64
+		var yearEnd sql.NullTime
+		var income, spending, charitable, raisingFunds, reserves, assets sql.NullFloat64
+		if err := rows.Scan(
+			&number, &name, &status, &companyNumber, &dateRegistered,
+			&overall, &efficiency, &financialHealth, &transparency, &governance,
+			&confidence, &scoringVersion,
+			&yearEnd, &income, &spending, &charitable, &raisingFunds, &reserves, &assets,
+		); err != nil {
+			logger.Error("Error scanning charity for export", "operation", "export", "error", err)
+			abortExport()
+		}
+
+		cw.Write([]string{
+			strconv.Itoa(number), csvSafe(name), status, csvSafe(companyNumber), exportDate(dateRegistered),
+			exportScore(overall), exportScore(efficiency), exportScore(financialHealth),
+			exportScore(transparency), exportScore(governance),
+			confidence.String, exportInt(scoringVersion),
+			exportDate(yearEnd), exportAmount(income), exportAmount(spending), exportAmount(charitable),
+			exportAmount(raisingFunds), exportAmount(reserves), exportAmount(assets),
+		})
+
+		count++
+		if count%exportFlushRows == 0 {
+			cw.Flush()
+			gz.Flush()
+			rc.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Error reading charities for export", "operation", "export", "error", err)
+		abortExport()
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.Error("Error writing export", "operation", "export", "error", err)
+	}
+	if err := gz.Close(); err != nil {
+		logger.Error("Error compressing export", "operation", "export", "error", err)
+	}
+	logger.Info("Full export complete", "operation", "export", "charities", count)
+}
+
+// abortExport drops the connection part way through an export. The gzip stream is
+// deliberately left unclosed: its trailer would make a truncated file look like a
+// complete one, whereas a stream cut off without it fails to decompress.
+func abortExport() {
+	panic(http.ErrAbortHandler)
+}
+
+// exportScore formats a stored score, blank if the charity hasn't been scored
+func exportScore(score sql.NullFloat64) string {
+	if !score.Valid {
+		return ""
+	}
+	return formatScore(score.Float64)
+}
+
+// exportAmount formats a money amount, blank if there's no figure
+func exportAmount(amount sql.NullFloat64) string {
+	if !amount.Valid {
+		return ""
+	}
+	return formatAmount(amount.Float64)
+}
+
+// exportInt formats an optional integer, blank if absent
+func exportInt(value sql.NullInt64) string {
+	if !value.Valid {
+		return ""
+	}
+	return strconv.FormatInt(value.Int64, 10)
+}
+
+// exportDate formats an optional date as YYYY-MM-DD, blank if absent or unset
+func exportDate(date sql.NullTime) string {
+	if !date.Valid || date.Time.IsZero() {
+		return ""
+	}
+	return date.Time.Format("2006-01-02")
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"charitylens/internal/database"
+)
+
+// DataHealth reports how fresh the imported bulk data is
+type DataHealth struct {
+	Status        string     `json:"status"` // "ok", "stale" or "no_extract"
+	DateOfExtract *time.Time `json:"date_of_extract"`
+	MaxAge        string     `json:"max_age"`
+	Charities     int        `json:"charities"`
+	ScoringRun    bool       `json:"scoring_run"`
+}
+
+// DataHealth returns the most recent bulk data extract date, the number of charities
+// and whether scores have been calculated. Responds 503 when no extract has been
+// imported or the latest is older than the configured DATA_MAX_AGE, so monitoring
+// can alert when a scheduled re-import silently fails.
+func (h *CharityHandler) DataHealth(w http.ResponseWriter, r *http.Request) {
+	health := DataHealth{MaxAge: h.Cfg.DataMaxAge.String()}
+
+	// Latest extract across all imported files
+	var extractDate sql.NullTime
+	err := h.DB.QueryRow(`
+		SELECT date_of_extract FROM data_extracts
+		ORDER BY date_of_extract DESC LIMIT 1
+	`).Scan(&extractDate)
+	if err != nil && err != sql.ErrNoRows {
+		writeError(w, fmt.Errorf("reading extract date: %w", err))
+		return
+	}
+	if extractDate.Valid {
+		health.DateOfExtract = &extractDate.Time
+	}
+
+	// Main charities only, exclude removed
+	err = h.DB.QueryRow(`
+		SELECT COUNT(*) FROM charities
+		WHERE linked_charity_number = 0
+		  AND ` + database.ListedSQL("status") + `
+	`).Scan(&health.Charities)
+	if err != nil {
+		writeError(w, fmt.Errorf("counting charities: %w", err))
+		return
+	}
+
+	err = h.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM charity_scores)`).Scan(&health.ScoringRun)
+	if err != nil {
+		writeError(w, fmt.Errorf("checking scores: %w", err))
+		return
+	}
+
+	status := http.StatusOK
+	switch {
+	case health.DateOfExtract == nil:
+		health.Status = "no_extract"
+		status = http.StatusServiceUnavailable
+	case time.Since(*health.DateOfExtract) > h.Cfg.DataMaxAge:
+		health.Status = "stale"
+		status = http.StatusServiceUnavailable
+	default:
+		health.Status = "ok"
+	}
+
+	writeJSON(w, status, health)
+}
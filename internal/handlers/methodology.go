@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"charitylens/internal/scoring"
+)
+
+// Methodology describes how scores are calculated, so clients can reproduce or
+// audit the scores they receive. It reflects the configuration in use, such as
+// neutral scores overridden at startup.
+type Methodology struct {
+	ScoringVersion int `json:"scoring_version"`
+
+	// Weights of each subscore in overall_score
+	Weights struct {
+		Efficiency      float64 `json:"efficiency"`
+		FinancialHealth float64 `json:"financial_health"`
+		Transparency    float64 `json:"transparency"`
+		Governance      float64 `json:"governance"`
+	} `json:"weights"`
+
+	// TransparencyPoints is what each component adds to the transparency score
+	TransparencyPoints struct {
+		Website           float64 `json:"website"`
+		Financials        float64 `json:"financials"`
+		Trustees          float64 `json:"trustees"`
+		FilingTimeliness  float64 `json:"filing_timeliness"`
+		FilingConsistency float64 `json:"filing_consistency"`
+		AccountsQuality   float64 `json:"accounts_quality"`
+	} `json:"transparency_points"`
+
+	// Neutrals are the scores used when the data behind a component wasn't reported
+	Neutrals struct {
+		Efficiency      float64 `json:"efficiency"`
+		FinancialHealth float64 `json:"financial_health"`
+		Filing          float64 `json:"filing"`
+		AccountsQuality float64 `json:"accounts_quality"`
+
+		// Website applies to charities without a website whose latest income is
+		// below WebsiteIncomeLimit; a limit of 0 means a missing website scores 0
+		Website            float64 `json:"website"`
+		WebsiteIncomeLimit float64 `json:"website_income_limit"`
+	} `json:"neutrals"`
+
+	// Thresholds behind the financial health and governance scores
+	Thresholds struct {
+		MinReserveMonths       float64 `json:"min_reserve_months"`
+		MaxReserveMonths       float64 `json:"max_reserve_months"`
+		FullGovernanceTrustees int     `json:"full_governance_trustees"`
+
+		// Trustees earning the full governance score in each income band
+		GovernanceTrusteesByBand []bandTrustees `json:"governance_trustees_by_band"`
+	} `json:"thresholds"`
+
+	// CompletenessDimensions are the kinds of data completeness_score looks for,
+	// each an equal share of it. They don't affect overall_score.
+	CompletenessDimensions []string `json:"completeness_dimensions"`
+}
+
+// bandTrustees is how many trustees earn the full governance score in an income
+// band
+type bandTrustees struct {
+	IncomeBand incomeBand `json:"income_band"`
+	Trustees   int        `json:"trustees"`
+}
+
+// GetMethodology returns the scoring weights, transparency points, neutral
+// scores and scoring version in use. The methodology page explains them.
+func (h *CharityHandler) GetMethodology(w http.ResponseWriter, r *http.Request) {
+	var m Methodology
+	m.ScoringVersion = scoring.ScoringVersion
+
+	m.Weights.Efficiency = scoring.WeightEfficiency
+	m.Weights.FinancialHealth = scoring.WeightFinancialHealth
+	m.Weights.Transparency = scoring.WeightTransparency
+	m.Weights.Governance = scoring.WeightGovernance
+
+	m.TransparencyPoints.Website = scoring.TransparencyWebsitePoints
+	m.TransparencyPoints.Financials = scoring.TransparencyFinancialsPoints
+	m.TransparencyPoints.Trustees = scoring.TransparencyTrusteesPoints
+	m.TransparencyPoints.FilingTimeliness = scoring.TransparencyFilingTimelinessPoints
+	m.TransparencyPoints.FilingConsistency = scoring.TransparencyFilingConsistencyPoints
+	m.TransparencyPoints.AccountsQuality = scoring.TransparencyAccountsQualityPoints
+
+	neutrals := scoring.CurrentNeutrals()
+	m.Neutrals.Efficiency = neutrals.Efficiency
+	m.Neutrals.FinancialHealth = neutrals.FinancialHealth
+	m.Neutrals.Filing = neutrals.Filing
+	m.Neutrals.AccountsQuality = neutrals.AccountsQuality
+	m.Neutrals.Website = neutrals.Website
+	m.Neutrals.WebsiteIncomeLimit = neutrals.WebsiteIncomeLimit
+
+	m.Thresholds.MinReserveMonths = scoring.MinReserveMonths
+	m.Thresholds.MaxReserveMonths = scoring.MaxReserveMonths
+	m.Thresholds.FullGovernanceTrustees = scoring.FullGovernanceTrustees
+	for band, trustees := range scoring.GovernanceTrusteesByBand {
+		lower := 0.0
+		if band > 0 {
+			lower = scoring.IncomeBandLimits[band-1]
+		}
+		m.Thresholds.GovernanceTrusteesByBand = append(m.Thresholds.GovernanceTrusteesByBand,
+			bandTrustees{IncomeBand: incomeBandFor(lower), Trustees: trustees})
+	}
+
+	m.CompletenessDimensions = scoring.CompletenessDimensions
+
+	writeJSON(w, http.StatusOK, m)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"strconv"
+
+	"charitylens/internal/logger"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Open Graph preview images are the 1.91:1 size the major networks recommend
+const (
+	ogImageWidth  = 1200
+	ogImageHeight = 630
+)
+
+// ogImageMaxAge is how long an image may be cached. An image only depends on the
+// score in its URL, so it never changes.
+const ogImageMaxAge = "public, max-age=604800, immutable"
+
+// Colours matching the score classes and theme in main.css
+var (
+	ogPrimary    = color.RGBA{0x63, 0x66, 0xf1, 0xff}
+	ogHigh       = color.RGBA{0x10, 0xb9, 0x81, 0xff}
+	ogMedium     = color.RGBA{0xf5, 0x9e, 0x0b, 0xff}
+	ogLow        = color.RGBA{0xef, 0x44, 0x44, 0xff}
+	ogTrack      = color.RGBA{0xe5, 0xe7, 0xeb, 0xff}
+	ogBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// sevenSegments are the lit segments of each digit, as bits a (top) to g
+// (middle) clockwise from the top
+var sevenSegments = [10]uint8{0x3f, 0x06, 0x5b, 0x4f, 0x66, 0x6d, 0x7d, 0x07, 0x7f, 0x6f}
+
+// ScoreImage serves the preview image for a charity page with a score, drawn
+// large in the colour of its score band above a bar filled to it
+func (h *WebHandler) ScoreImage(w http.ResponseWriter, r *http.Request) {
+	score, err := strconv.Atoi(chi.URLParam(r, "score"))
+	if err != nil || score < 0 || score > 100 {
+		http.NotFound(w, r)
+		return
+	}
+	writePNG(w, scoreImage(score))
+}
+
+// DefaultImage serves the generic preview image used by pages without a score
+func (h *WebHandler) DefaultImage(w http.ResponseWriter, r *http.Request) {
+	img := image.NewRGBA(image.Rect(0, 0, ogImageWidth, ogImageHeight))
+	fill(img, img.Bounds(), ogPrimary)
+
+	// A lens: a ring with a handle
+	fillCircle(img, ogImageWidth/2-40, ogImageHeight/2-40, 180, ogBackground)
+	fillCircle(img, ogImageWidth/2-40, ogImageHeight/2-40, 140, ogPrimary)
+	for i := 10; i < 130; i++ {
+		x, y := ogImageWidth/2+80+i, ogImageHeight/2+80+i
+		fill(img, image.Rect(x-22, y-22, x+22, y+22), ogBackground)
+	}
+	writePNG(w, img)
+}
+
+// scoreImage draws the preview image for a 0-100 score
+func scoreImage(score int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, ogImageWidth, ogImageHeight))
+	fill(img, img.Bounds(), ogBackground)
+	fill(img, image.Rect(0, 0, ogImageWidth, 24), ogPrimary)
+
+	band := ogLow
+	switch {
+	case score >= 80:
+		band = ogHigh
+	case score >= 60:
+		band = ogMedium
+	}
+
+	// The score, centred above the bar
+	const digitWidth, digitHeight, thickness, gap = 140, 260, 34, 50
+	digits := strconv.Itoa(score)
+	width := len(digits)*digitWidth + (len(digits)-1)*gap
+	x := (ogImageWidth - width) / 2
+	for _, d := range digits {
+		drawDigit(img, x, 110, digitWidth, digitHeight, thickness, int(d-'0'), band)
+		x += digitWidth + gap
+	}
+
+	// The bar, filled to the score
+	bar := image.Rect(120, 460, ogImageWidth-120, 520)
+	fill(img, bar, ogTrack)
+	fill(img, image.Rect(bar.Min.X, bar.Min.Y, bar.Min.X+bar.Dx()*score/100, bar.Max.Y), band)
+
+	return img
+}
+
+// drawDigit draws a seven-segment digit with its top left corner at x, y
+func drawDigit(img *image.RGBA, x, y, width, height, thickness, digit int, c color.Color) {
+	mid := y + (height-thickness)/2
+	segments := [7]image.Rectangle{
+		image.Rect(x, y, x+width, y+thickness),                   // a: top
+		image.Rect(x+width-thickness, y, x+width, mid+thickness), // b: top right
+		image.Rect(x+width-thickness, mid, x+width, y+height),    // c: bottom right
+		image.Rect(x, y+height-thickness, x+width, y+height),     // d: bottom
+		image.Rect(x, mid, x+thickness, y+height),                // e: bottom left
+		image.Rect(x, y, x+thickness, mid+thickness),             // f: top left
+		image.Rect(x, mid, x+width, mid+thickness),               // g: middle
+	}
+	for i, segment := range segments {
+		if sevenSegments[digit]&(1<<i) != 0 {
+			fill(img, segment, c)
+		}
+	}
+}
+
+// fill paints a rectangle of img in a solid colour
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// fillCircle paints a solid circle of img
+func fillCircle(img *image.RGBA, cx, cy, radius int, c color.Color) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				img.Set(cx+x, cy+y, c)
+			}
+		}
+	}
+}
+
+// writePNG encodes and writes a preview image
+func writePNG(w http.ResponseWriter, img image.Image) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		http.Error(w, "Error rendering image", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", ogImageMaxAge)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logger.Error("Error writing image", "error", err)
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"charitylens/internal/config"
+)
+
+// pageCache holds rendered charity pages keyed by charity number, evicting the
+// least recently used page once full
+type pageCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries map[int]*list.Element
+	order   *list.List // Front is most recently used
+}
+
+// cachedPage is one rendered page in the cache
+type cachedPage struct {
+	number  int
+	body    []byte
+	expires time.Time
+}
+
+var (
+	sharedPageCache     *pageCache
+	sharedPageCacheOnce sync.Once
+)
+
+// getPageCache returns the page cache shared by all handlers, or nil if it is
+// disabled. Sharing one cache lets the API handlers invalidate pages the web
+// handler rendered.
+func getPageCache(cfg *config.Config) *pageCache {
+	sharedPageCacheOnce.Do(func() {
+		if !cfg.PageCacheEnabled || cfg.PageCacheSize <= 0 {
+			return
+		}
+		ttl := cfg.PageCacheTTL
+		if cfg.OfflineMode {
+			ttl = cfg.PageCacheOfflineTTL
+		}
+		sharedPageCache = &pageCache{
+			ttl:     ttl,
+			size:    cfg.PageCacheSize,
+			entries: make(map[int]*list.Element),
+			order:   list.New(),
+		}
+	})
+	return sharedPageCache
+}
+
+// get returns the rendered page for a charity if it is cached and not expired
+func (c *pageCache) get(number int) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[number]
+	if !ok {
+		return nil, false
+	}
+	page := element.Value.(*cachedPage)
+	if time.Now().After(page.expires) {
+		c.order.Remove(element)
+		delete(c.entries, number)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return page.body, true
+}
+
+// set stores a rendered page, evicting the least recently used page if full
+func (c *pageCache) set(number int, body []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(c.ttl)
+	if element, ok := c.entries[number]; ok {
+		page := element.Value.(*cachedPage)
+		page.body = body
+		page.expires = expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[number] = c.order.PushFront(&cachedPage{number: number, body: body, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedPage).number)
+	}
+}
+
+// invalidate drops a charity's page after its data or score changes
+func (c *pageCache) invalidate(number int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[number]; ok {
+		c.order.Remove(element)
+		delete(c.entries, number)
+	}
+}
+
+// clear drops every cached page, e.g. after a bulk sync
+func (c *pageCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[int]*list.Element)
+	c.order.Init()
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"charitylens/web/templates"
+)
+
+// pageDescriptionLength is the most characters of a charity's activities used in
+// its page description, as previews truncate longer ones anyway
+const pageDescriptionLength = 200
+
+// defaultPageDescription describes the site on pages without a charity to describe
+const defaultPageDescription = "Transparency scores and analysis of the finances and governance of UK registered charities."
+
+// pageMeta is the Open Graph and Twitter card metadata for a page, which social
+// networks and chat apps use to preview shared links. URL and Image are absolute,
+// and empty when PublicURL isn't set.
+type pageMeta struct {
+	Title       string
+	Description string
+	URL         string
+	Image       string
+}
+
+// charityPage is the data the charity page is rendered with
+type charityPage struct {
+	CharityDetail
+	Meta pageMeta
+}
+
+// errorPage is the data the error and loading pages are rendered with
+type errorPage struct {
+	Code      int
+	Title     string
+	Message   string
+	IsLoading bool
+	RetryURL  string
+	Meta      pageMeta
+}
+
+// absoluteURL returns path made absolute with PublicURL, or "" if PublicURL isn't
+// set. The request's Host and X-Forwarded-Proto aren't used instead, as they're
+// client controlled and the page may be cached and served to others.
+func (h *WebHandler) absoluteURL(path string) string {
+	if h.Cfg.PublicURL == "" {
+		return ""
+	}
+	return h.Cfg.PublicURL + path
+}
+
+// defaultPageMeta returns generic metadata for the requested page, used where
+// there's no charity to describe, such as the loading and error pages
+func (h *WebHandler) defaultPageMeta(r *http.Request) pageMeta {
+	return pageMeta{
+		Title:       "CharityLens",
+		Description: defaultPageDescription,
+		URL:         h.absoluteURL(r.URL.Path),
+		Image:       h.absoluteURL("/og/default.png"),
+	}
+}
+
+// charityPageMeta returns the metadata for a charity's page, with its score in
+// the description and a preview image of it
+func (h *WebHandler) charityPageMeta(detail CharityDetail) pageMeta {
+	score := int(detail.Score.OverallScore + 0.5)
+
+	description := fmt.Sprintf("Transparency score %d/100 (%s confidence).", score, detail.Score.ConfidenceLevel)
+	if activities := strings.Join(strings.Fields(detail.Charity.WhatTheCharityDoes), " "); activities != "" {
+		description += " " + truncateText(activities, pageDescriptionLength)
+	}
+
+	return pageMeta{
+		Title:       templates.TitleCase(detail.Charity.Name) + " - CharityLens",
+		Description: description,
+		URL:         h.absoluteURL(fmt.Sprintf("/charity/%d", detail.Charity.RegisteredNumber)),
+		Image:       h.absoluteURL(fmt.Sprintf("/og/score/%d.png", score)),
+	}
+}
+
+// truncateText shortens text to at most limit characters, breaking at a word
+// where possible and marking the cut with an ellipsis
+func truncateText(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)[:limit-1]
+	cut := string(runes)
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
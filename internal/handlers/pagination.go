@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// parsePage reads the limit and offset query parameters, falling back to
+// defaultLimit when limit is missing or outside 1..maxLimit and to 0 for a
+// missing or negative offset
+func parsePage(r *http.Request, defaultLimit, maxLimit int) (limit, offset int) {
+	limit = defaultLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= maxLimit {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+	return limit, offset
+}
+
+// addPagination adds page and total_pages to a list response and sets an RFC 8288
+// Link header with first, prev, next and last relations. prev is omitted on the
+// first page and next on the last.
+func addPagination(w http.ResponseWriter, r *http.Request, response map[string]any, limit, offset, total int) {
+	totalPages := (total + limit - 1) / limit
+	if totalPages < 1 {
+		totalPages = 1
+	}
+	response["page"] = offset/limit + 1
+	response["total_pages"] = totalPages
+
+	links := []string{pageLink(r, "first", limit, 0)}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink(r, "prev", limit, prev))
+	}
+	if offset+limit < total {
+		links = append(links, pageLink(r, "next", limit, offset+limit))
+	}
+	links = append(links, pageLink(r, "last", limit, (totalPages-1)*limit))
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// pageLink formats one Link header entry for the request URL at the given offset,
+// keeping all other query parameters
+func pageLink(r *http.Request, rel string, limit, offset int) string {
+	query := r.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	return fmt.Sprintf(`<%s?%s>; rel="%s"`, r.URL.Path, query.Encode(), rel)
+}
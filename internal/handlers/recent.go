@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"charitylens/internal/database"
+	apperrors "charitylens/internal/errors"
+	"charitylens/internal/models"
+)
+
+const (
+	// recentCacheTTL is how long a page of the recent charities feed is reused.
+	// It's short so a sync's new data shows up quickly.
+	recentCacheTTL = time.Minute
+
+	// maxRecentCacheEntries caps how many pages are cached at once; deep pages
+	// beyond it aren't cached
+	maxRecentCacheEntries = 100
+)
+
+// recentColumns maps each type of recent charities feed to the column it's
+// ordered by
+var recentColumns = map[string]string{
+	"added":   "date_registered",
+	"updated": "last_updated",
+}
+
+// recentPage is a cached page of the recent charities feed
+type recentPage struct {
+	charities []models.Charity
+	total     int
+	expires   time.Time
+}
+
+// recentCache holds recently requested pages of the feed, keyed by type, limit
+// and offset
+type recentCache struct {
+	mu    sync.Mutex
+	pages map[string]recentPage
+}
+
+// get returns the cached page for key, if it hasn't expired
+func (c *recentCache) get(key string) (recentPage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	page, ok := c.pages[key]
+	if !ok || time.Now().After(page.expires) {
+		return recentPage{}, false
+	}
+	return page, true
+}
+
+// put caches page under key, dropping expired pages first
+func (c *recentCache) put(key string, page recentPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, p := range c.pages {
+		if now.After(p.expires) {
+			delete(c.pages, k)
+		}
+	}
+	if len(c.pages) < maxRecentCacheEntries {
+		page.expires = now.Add(recentCacheTTL)
+		c.pages[key] = page
+	}
+}
+
+// GetRecentCharities returns a page of main, non-removed charities, most
+// recently registered first (type=added, the default) or most recently updated
+// first (type=updated). It's a browse entry point beyond search, and shows
+// whether new data is arriving after a sync.
+func (h *CharityHandler) GetRecentCharities(w http.ResponseWriter, r *http.Request) {
+	recentType := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("type")))
+	if recentType == "" {
+		recentType = "added"
+	}
+	column, ok := recentColumns[recentType]
+	if !ok {
+		writeError(w, apperrors.ValidationError{Field: "type", Message: "Type must be 'added' or 'updated'"})
+		return
+	}
+	limit, offset := parsePage(r, 50, 100)
+
+	key := fmt.Sprintf("%s:%d:%d", recentType, limit, offset)
+	page, ok := h.recent.get(key)
+	if !ok {
+		charities, total, err := loadRecentCharities(h.dbs.Reader(), column, limit, offset)
+		if err != nil {
+			writeError(w, fmt.Errorf("loading recent charities: %w", err))
+			return
+		}
+		page = recentPage{charities: charities, total: total}
+		h.recent.put(key, page)
+	}
+
+	response := map[string]any{
+		"type":     recentType,
+		"results":  page.charities,
+		"total":    page.total,
+		"limit":    limit,
+		"offset":   offset,
+		"has_more": offset+len(page.charities) < page.total,
+	}
+	addPagination(w, r, response, limit, offset, page.total)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// loadRecentCharities returns a page of main, listed charities with a value in
+// column, newest first, and how many there are in all
+func loadRecentCharities(db *sql.DB, column string, limit, offset int) ([]models.Charity, int, error) {
+	where := `
+		WHERE c.linked_charity_number = 0
+		  AND ` + database.ListedSQL("c.status") + `
+		  AND c.` + column + ` IS NOT NULL`
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM charities c` + where).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(`
+		SELECT c.organisation_number, c.registered_number, c.linked_charity_number,
+		       COALESCE(c.company_number, ''), c.name, COALESCE(c.status, ''),
+		       c.date_registered, c.address, c.website, c.last_updated,
+		       COALESCE(s.overall_score, 0)
+		FROM charities c
+		LEFT JOIN charity_scores s ON c.registered_number = s.charity_number`+where+`
+		ORDER BY c.`+column+` DESC, c.registered_number DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	charities := []models.Charity{}
+	for rows.Next() {
+		var charity models.Charity
+		var address, website sql.NullString
+		var dateRegistered, lastUpdated sql.NullTime
+		if err := rows.Scan(
+			&charity.OrganisationNumber, &charity.RegisteredNumber, &charity.LinkedCharityNumber,
+			&charity.CompanyNumber, &charity.Name, &charity.Status,
+			&dateRegistered, &address, &website, &lastUpdated,
+			&charity.OverallScore,
+		); err != nil {
+			return nil, 0, err
+		}
+		charity.DateRegistered = dateRegistered.Time
+		charity.Address = address.String
+		charity.Website = website.String
+		charity.LastUpdated = lastUpdated.Time
+		charities = append(charities, charity)
+	}
+	return charities, total, rows.Err()
+}
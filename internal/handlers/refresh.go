@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// refreshLimitPerIP is the number of forced refreshes one client may make per refreshWindow
+	refreshLimitPerIP = 10
+	refreshWindow     = time.Hour
+
+	// refreshCooldown is the minimum time between live refreshes of the same charity,
+	// whoever asks for them
+	refreshCooldown = 10 * time.Minute
+
+	// refreshTimeout bounds a live refresh, retries included, so the page stays
+	// responsive when the API is slow; the stored data is shown instead
+	refreshTimeout = 5 * time.Second
+
+	// refreshPruneSize is the number of tracked entries above which expired ones are dropped
+	refreshPruneSize = 1000
+)
+
+// refreshGuard limits ?refresh=1 requests so the detail page can't be used as a
+// free proxy to the Charity Commission API
+type refreshGuard struct {
+	mu        sync.Mutex
+	clients   map[string]*refreshWindowCount
+	charities map[int]time.Time
+}
+
+// refreshWindowCount counts one client's refreshes within the current window
+type refreshWindowCount struct {
+	start time.Time
+	count int
+}
+
+func newRefreshGuard() *refreshGuard {
+	return &refreshGuard{
+		clients:   make(map[string]*refreshWindowCount),
+		charities: make(map[int]time.Time),
+	}
+}
+
+// refreshDecision is the outcome of a forced refresh request
+type refreshDecision int
+
+const (
+	refreshAllowed     refreshDecision = iota // fetch from the live API now
+	refreshCoolingDown                        // refreshed recently, serve stored data
+	refreshRateLimited                        // client has used up its refreshes
+)
+
+// check decides whether the client at ip may refresh a charity. The cooldown is
+// checked first so refreshes that wouldn't reach the API don't count against the
+// client. An allowed refresh is recorded immediately so concurrent requests for
+// the same charity don't all reach the API.
+func (g *refreshGuard) check(ip string, number int) refreshDecision {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.prune(now)
+
+	if last, ok := g.charities[number]; ok && now.Sub(last) < refreshCooldown {
+		return refreshCoolingDown
+	}
+
+	entry, ok := g.clients[ip]
+	if !ok || now.Sub(entry.start) >= refreshWindow {
+		entry = &refreshWindowCount{start: now}
+		g.clients[ip] = entry
+	}
+	if entry.count >= refreshLimitPerIP {
+		return refreshRateLimited
+	}
+
+	entry.count++
+	g.charities[number] = now
+	return refreshAllowed
+}
+
+// prune drops expired entries once the maps grow large. Caller must hold g.mu.
+func (g *refreshGuard) prune(now time.Time) {
+	if len(g.clients)+len(g.charities) < refreshPruneSize {
+		return
+	}
+	for ip, entry := range g.clients {
+		if now.Sub(entry.start) >= refreshWindow {
+			delete(g.clients, ip)
+		}
+	}
+	for number, last := range g.charities {
+		if now.Sub(last) >= refreshCooldown {
+			delete(g.charities, number)
+		}
+	}
+}
+
+// clientIP returns the address of the client that made the request. Behind the
+// Fly proxy RemoteAddr is the proxy's, so Fly-Client-IP is used if set, then the
+// right-most X-Forwarded-For hop, the one the nearest proxy added; earlier hops
+// are whatever the client sent. Otherwise it's RemoteAddr without its port.
+func clientIP(r *http.Request) string {
+	if ip := strings.TrimSpace(r.Header.Get("Fly-Client-IP")); ip != "" {
+		return ip
+	}
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(forwarded[len(forwarded)-1], ",")
+		if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string][]string
+		want       string
+	}{
+		{name: "remote addr", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "remote addr without port", remoteAddr: "203.0.113.7", want: "203.0.113.7"},
+		{name: "ipv6 remote addr", remoteAddr: "[2001:db8::1]:51234", want: "2001:db8::1"},
+		{
+			name:       "fly client ip",
+			remoteAddr: "172.16.0.2:40000",
+			headers: map[string][]string{
+				"Fly-Client-IP":   {"198.51.100.4"},
+				"X-Forwarded-For": {"192.0.2.1, 198.51.100.9"},
+			},
+			want: "198.51.100.4",
+		},
+		{
+			// The client can put anything in the earlier hops
+			name:       "right-most forwarded hop",
+			remoteAddr: "172.16.0.2:40000",
+			headers:    map[string][]string{"X-Forwarded-For": {"192.0.2.1, 198.51.100.9"}},
+			want:       "198.51.100.9",
+		},
+		{
+			name:       "forwarded across headers",
+			remoteAddr: "172.16.0.2:40000",
+			headers:    map[string][]string{"X-Forwarded-For": {"192.0.2.1", "198.51.100.9"}},
+			want:       "198.51.100.9",
+		},
+		{
+			name:       "empty headers",
+			remoteAddr: "203.0.113.7:51234",
+			headers:    map[string][]string{"Fly-Client-IP": {" "}, "X-Forwarded-For": {""}},
+			want:       "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/charity/1000", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for name, values := range tt.headers {
+				for _, v := range values {
+					r.Header.Add(name, v)
+				}
+			}
+			if got := clientIP(r); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
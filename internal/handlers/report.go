@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"charitylens/internal/database"
+	"charitylens/internal/models"
+	"charitylens/internal/scoring"
+)
+
+// reportSchemaVersion is the version of the CharityReport document. It's bumped
+// when a field is removed or changes meaning; fields may be added without it.
+const reportSchemaVersion = 1
+
+// CharityReport is everything known about one charity, as a self-contained
+// document that can be shared. Unlike CharityDetail, trustees and activities are
+// complete and every financial year is included.
+type CharityReport struct {
+	SchemaVersion int       `json:"schema_version"`
+	GeneratedAt   time.Time `json:"generated_at"`
+
+	Charity models.Charity         `json:"charity"`
+	Removal *models.CharityRemoval `json:"removal,omitempty"`
+
+	Score          models.CharityScore `json:"score"`
+	ScoreError     string              `json:"score_error,omitempty"`
+	ScoreBreakdown []scoreComponent    `json:"score_breakdown"`
+	PeerPercentile *peerPercentile     `json:"peer_percentile"` // Nil without financial data or scored peers
+
+	LatestFinancial  *models.Financial        `json:"latest_financial"`
+	Metrics          *models.FinancialMetrics `json:"metrics"`
+	FinancialHistory []models.Financial       `json:"financial_history"` // Most recent year first
+
+	Trustees   []models.Trustee  `json:"trustees"`
+	Activities []models.Activity `json:"activities"`
+}
+
+// scoreComponent is one subscore's part in the overall score
+type scoreComponent struct {
+	Component    string  `json:"component"`
+	Score        float64 `json:"score"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"` // Score * Weight
+}
+
+// peerPercentile ranks a charity's overall score among the scored main charities
+// in its income band
+type peerPercentile struct {
+	IncomeBand incomeBand `json:"income_band"`
+	Percentile float64    `json:"percentile"` // Percentage of peers scoring lower
+	Peers      int        `json:"peers"`      // Scored charities in the band, excluding this one
+}
+
+// GetCharityReport returns a CharityReport for a charity. The response carries an
+// ETag of its content, ignoring when it was generated, so clients can revalidate
+// with If-None-Match and get a 304 while nothing has changed.
+func (h *CharityHandler) GetCharityReport(w http.ResponseWriter, r *http.Request) {
+	number, ok := h.charityNumberParam(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.loadCharityReport(number)
+	if err != nil {
+		writeError(w, fmt.Errorf("building report: %w", err))
+		return
+	}
+
+	// The score is recalculated for every report, so its timestamp changes too
+	tagged := report
+	tagged.GeneratedAt = time.Time{}
+	tagged.Score.LastCalculated = time.Time{}
+	content, err := json.Marshal(tagged)
+	if err != nil {
+		writeError(w, fmt.Errorf("encoding report: %w", err))
+		return
+	}
+	sum := sha256.Sum256(content)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="charitylens-report-%d.json"`, number))
+	writeJSON(w, http.StatusOK, report)
+}
+
+// loadCharityReport gathers a charity's report, scoring it as the detail
+// endpoint does
+func (h *CharityHandler) loadCharityReport(number int) (CharityReport, error) {
+	detail, err := loadCharityWithScore(h.dbs, number, h.Cfg.OfflineMode)
+	if err != nil {
+		return CharityReport{}, err
+	}
+
+	report := CharityReport{
+		SchemaVersion: reportSchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		Charity:       detail.Charity,
+		Removal:       detail.Removal,
+		Score:         detail.Score,
+		ScoreError:    detail.ScoreError,
+		ScoreBreakdown: []scoreComponent{
+			newScoreComponent("efficiency", detail.Score.EfficiencyScore, scoring.WeightEfficiency),
+			newScoreComponent("financial_health", detail.Score.FinancialHealthScore, scoring.WeightFinancialHealth),
+			newScoreComponent("transparency", detail.Score.TransparencyScore, scoring.WeightTransparency),
+			newScoreComponent("governance", detail.Score.GovernanceScore, scoring.WeightGovernance),
+		},
+	}
+
+	db := h.dbs.Reader()
+	report.FinancialHistory, err = loadFinancialHistory(db, number)
+	if err != nil {
+		return report, err
+	}
+	if len(report.FinancialHistory) > 0 {
+		report.LatestFinancial = &detail.Financial
+		report.Metrics = &detail.Metrics
+
+		report.PeerPercentile, err = loadPeerPercentile(db, number, detail.Financial.TotalIncome, detail.Score.OverallScore)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	// A negative limit is no limit to SQLite, so every trustee and activity is loaded
+	report.Trustees, _, err = loadTrustees(db, number, -1, 0)
+	if err != nil {
+		return report, err
+	}
+	report.Activities, _, err = loadActivities(db, number, -1, 0)
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// newScoreComponent returns a subscore with its weighted contribution
+func newScoreComponent(component string, score, weight float64) scoreComponent {
+	return scoreComponent{
+		Component:    component,
+		Score:        score,
+		Weight:       weight,
+		Contribution: math.Round(score*weight*100) / 100,
+	}
+}
+
+// loadFinancialHistory loads every financial year of a charity, most recent first.
+// Trustees is left at zero, as only the current trustees are known.
+func loadFinancialHistory(db *sql.DB, number int) ([]models.Financial, error) {
+	rows, err := db.Query(`
+		SELECT financial_year_end, total_income, total_spending, charitable_activities_spend,
+		       raising_funds_spend, other_spend, reserves, assets
+		FROM financials WHERE charity_number = ?
+		ORDER BY financial_year_end DESC
+	`, number)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.Financial{}
+	for rows.Next() {
+		fin := models.Financial{CharityNumber: number}
+		if err := rows.Scan(
+			&fin.FinancialYearEnd, &fin.TotalIncome, &fin.TotalSpending,
+			&fin.CharitableActivitiesSpend, &fin.RaisingFundsSpend,
+			&fin.OtherSpend, &fin.Reserves, &fin.Assets,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, fin)
+	}
+	return history, rows.Err()
+}
+
+// loadPeerPercentile ranks a score among the stored scores of the main,
+// non-removed charities whose latest income is in the same band as income, as
+// GetSimilarCharities chooses peers. It returns nil if no peer has been scored.
+func loadPeerPercentile(db *sql.DB, number int, income, score float64) (*peerPercentile, error) {
+	band := incomeBandFor(income)
+	upper := math.MaxFloat64
+	if band.Max != nil {
+		upper = *band.Max
+	}
+
+	var peers, lower int
+	err := db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(s.overall_score < ?), 0)
+		FROM financials f
+		JOIN charities c ON c.registered_number = f.charity_number
+		JOIN charity_scores s ON s.charity_number = c.registered_number
+		WHERE f.total_income >= ? AND f.total_income < ?
+		  AND f.financial_year_end = (
+			SELECT MAX(financial_year_end) FROM financials
+			WHERE charity_number = f.charity_number
+		  )
+		  AND c.registered_number != ?
+		  AND c.linked_charity_number = 0
+		  AND `+database.ListedSQL("c.status")+`
+	`, score, band.Min, upper, number).Scan(&peers, &lower)
+	if errors.Is(err, sql.ErrNoRows) || peers == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &peerPercentile{
+		IncomeBand: band,
+		Percentile: math.Round(float64(lower)/float64(peers)*1000) / 10,
+		Peers:      peers,
+	}, nil
+}
+
+// etagMatches reports whether an If-None-Match header matches etag. Weak
+// validators match too, as the comparison is only used to answer GET requests.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
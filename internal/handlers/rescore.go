@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"charitylens/internal/background"
+	"charitylens/internal/database"
+	"charitylens/internal/dates"
+	apperrors "charitylens/internal/errors"
+	"charitylens/internal/importer"
+	"charitylens/internal/logger"
+	"charitylens/internal/validation"
+)
+
+// maxRescoreCharities caps the charity_numbers of a rescore request
+const maxRescoreCharities = 1000
+
+// rescoreRequest selects the charities to rescore. Every filter that's set must
+// match; charities matched by a score filter must already have a score.
+type rescoreRequest struct {
+	MinScore       *float64 `json:"min_score"`
+	MaxScore       *float64 `json:"max_score"`
+	ScoredBefore   string   `json:"scored_before"` // Date, e.g. "2025-01-31"
+	CharityNumbers []int    `json:"charity_numbers"`
+}
+
+// Rescore recalculates the scores of the charities matching a JSON filter body in
+// the background, using the same bounded workers as the seeder's -warm-scores. It
+// responds 202 with a job_id whose progress GetScoringStatus reports. Only one
+// scoring run can be in progress at once, including one from the seeder. Like
+// GetScoringStatus it requires AdminAPIKey to be configured, and as scores aren't
+// cached in offline mode it's rejected there.
+func (h *CharityHandler) Rescore(w http.ResponseWriter, r *http.Request) {
+	if h.Cfg.AdminAPIKey == "" {
+		writeError(w, fmt.Errorf("admin API key is not configured: %w", apperrors.ErrForbidden))
+		return
+	}
+	if !h.isAdmin(r) {
+		writeError(w, apperrors.ErrUnauthorized)
+		return
+	}
+	if h.Cfg.OfflineMode {
+		writeError(w, fmt.Errorf("scores are not stored in offline mode: %w", apperrors.ErrForbidden))
+		return
+	}
+
+	var req rescoreRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, apperrors.ValidationError{Field: "body", Message: "Invalid JSON filter: " + err.Error()})
+		return
+	}
+
+	where, args, err := req.filter()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Claim the scoring run before selecting, so two requests can't both start one
+	if !h.rescoring.CompareAndSwap(false, true) {
+		writeError(w, fmt.Errorf("a rescore is already in progress: %w", apperrors.ErrConflict))
+		return
+	}
+	started := false
+	defer func() {
+		if !started {
+			h.rescoring.Store(false)
+		}
+	}()
+
+	var running bool
+	err = h.DB.QueryRow(`
+		SELECT COUNT(*) > 0 FROM scoring_progress
+		WHERE id = 1 AND finished_at IS NULL AND updated_at > ?
+	`, time.Now().Add(-scoringStalledAfter)).Scan(&running)
+	if err != nil {
+		writeError(w, fmt.Errorf("reading scoring progress: %w", err))
+		return
+	}
+	if running {
+		writeError(w, fmt.Errorf("a scoring run is already in progress: %w", apperrors.ErrConflict))
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT c.registered_number FROM charities c
+		LEFT JOIN charity_scores s ON s.charity_number = c.registered_number
+		WHERE c.linked_charity_number = 0
+		  AND `+database.NotRemovedSQL("c.status")+where+`
+		ORDER BY c.registered_number
+	`, args...)
+	if err != nil {
+		writeError(w, fmt.Errorf("selecting charities to rescore: %w", err))
+		return
+	}
+	var charityNumbers []int
+	for rows.Next() {
+		var number int
+		if err := rows.Scan(&number); err != nil {
+			rows.Close()
+			writeError(w, fmt.Errorf("selecting charities to rescore: %w", err))
+			return
+		}
+		charityNumbers = append(charityNumbers, number)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		writeError(w, fmt.Errorf("selecting charities to rescore: %w", err))
+		return
+	}
+
+	if len(charityNumbers) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"job_id": nil, "charities": 0})
+		return
+	}
+
+	jobID := newJobID()
+	started = background.Go("rescore", func() {
+		defer h.rescoring.Store(false)
+
+		imp := importer.NewImporter(h.DB, importer.ImportConfig{ScoringJobID: jobID})
+		if err := imp.ScoreCharities("Rescore", charityNumbers); err != nil {
+			logger.Error("Rescore failed", "operation", "score", "job_id", jobID, "error", err)
+		}
+		h.pages.clear()
+
+		progress := imp.GetProgress()
+		logger.Info("Rescore finished", "operation", "score", "job_id", jobID,
+			"successful", progress.SuccessRecords, "failed", progress.FailedRecords, "skipped", progress.SkippedRecords)
+	})
+	if !started {
+		writeError(w, fmt.Errorf("too much background work in progress, try again later: %w", apperrors.ErrRateLimit))
+		return
+	}
+
+	logger.Info("Rescore started", "operation", "score", "job_id", jobID, "charities", len(charityNumbers))
+	writeJSON(w, http.StatusAccepted, map[string]any{"job_id": jobID, "charities": len(charityNumbers)})
+}
+
+// filter validates the request and returns the SQL conditions selecting its
+// charities, each starting with AND, against charities c and charity_scores s
+func (req rescoreRequest) filter() (string, []any, error) {
+	if req.MinScore == nil && req.MaxScore == nil && req.ScoredBefore == "" && len(req.CharityNumbers) == 0 {
+		return "", nil, apperrors.ValidationError{Field: "body",
+			Message: "At least one of min_score, max_score, scored_before or charity_numbers is required"}
+	}
+
+	var where strings.Builder
+	var args []any
+	if req.MinScore != nil {
+		where.WriteString(" AND s.overall_score >= ?")
+		args = append(args, *req.MinScore)
+	}
+	if req.MaxScore != nil {
+		where.WriteString(" AND s.overall_score <= ?")
+		args = append(args, *req.MaxScore)
+	}
+	if req.MinScore != nil && req.MaxScore != nil && *req.MinScore > *req.MaxScore {
+		return "", nil, apperrors.ValidationError{Field: "min_score", Message: "min_score must not be greater than max_score"}
+	}
+
+	if req.ScoredBefore != "" {
+		before := dates.Parse(req.ScoredBefore)
+		if before.IsZero() {
+			return "", nil, apperrors.ValidationError{Field: "scored_before", Message: "scored_before must be a date, e.g. 2025-01-31"}
+		}
+		where.WriteString(" AND datetime(s.last_calculated) < datetime(?)")
+		args = append(args, before.UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	if len(req.CharityNumbers) > 0 {
+		if len(req.CharityNumbers) > maxRescoreCharities {
+			return "", nil, apperrors.ValidationError{Field: "charity_numbers",
+				Message: fmt.Sprintf("At most %d charity numbers can be rescored at once", maxRescoreCharities)}
+		}
+		placeholders := make([]string, len(req.CharityNumbers))
+		for i, number := range req.CharityNumbers {
+			if err := validation.ValidateCharityNumber(number); err != nil {
+				return "", nil, apperrors.ValidationError{Field: "charity_numbers", Message: fmt.Sprintf("Invalid charity number %d", number)}
+			}
+			placeholders[i] = "?"
+			args = append(args, number)
+		}
+		where.WriteString(" AND c.registered_number IN (" + strings.Join(placeholders, ", ") + ")")
+	}
+
+	return where.String(), args, nil
+}
+
+// newJobID returns a random identifier for a background job
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	apperrors "charitylens/internal/errors"
+)
+
+// scoringStalledAfter is how long an unfinished scoring run can go without
+// publishing progress before it's reported as stalled. Runs publish every couple
+// of seconds, so this means the seeder has most likely been stopped.
+const scoringStalledAfter = 5 * time.Minute
+
+// scoringRun is the progress of a batch scoring run, as published by the seeder
+type scoringRun struct {
+	Label        string     `json:"label"`
+	TotalRecords int        `json:"total_records"`
+	Processed    int        `json:"processed"`
+	Successful   int        `json:"successful"`
+	Failed       int        `json:"failed"`
+	Skipped      int        `json:"skipped"`
+	Rate         float64    `json:"rate"`        // Charities per second
+	ETASeconds   *float64   `json:"eta_seconds"` // Remaining time at the current rate, nil if unknown or finished
+	StartedAt    time.Time  `json:"started_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+
+	// JobID identifies a run started by Rescore; it's empty for seeder runs
+	JobID string `json:"job_id,omitempty"`
+}
+
+// GetScoringStatus reports the progress of a batch scoring run (the seeder's score
+// mode, or the scoring step of an import) against this database. state is
+// "running", "idle" once the latest run has finished or if none has run, or
+// "stalled" if a run stopped publishing progress without finishing. With a job_id
+// query parameter it reports that Rescore job, or 404 if a later run has replaced
+// it. Like GetKeyStats it requires AdminAPIKey to be configured.
+func (h *CharityHandler) GetScoringStatus(w http.ResponseWriter, r *http.Request) {
+	if h.Cfg.AdminAPIKey == "" {
+		writeError(w, fmt.Errorf("admin API key is not configured: %w", apperrors.ErrForbidden))
+		return
+	}
+	if !h.isAdmin(r) {
+		writeError(w, apperrors.ErrUnauthorized)
+		return
+	}
+
+	var run scoringRun
+	var finishedAt sql.NullTime
+	var jobID sql.NullString
+	err := h.DB.QueryRow(`
+		SELECT label, total_records, processed, successful, failed, skipped,
+		       started_at, updated_at, finished_at, job_id
+		FROM scoring_progress WHERE id = 1
+	`).Scan(&run.Label, &run.TotalRecords, &run.Processed, &run.Successful, &run.Failed,
+		&run.Skipped, &run.StartedAt, &run.UpdatedAt, &finishedAt, &jobID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeError(w, fmt.Errorf("reading scoring progress: %w", err))
+		return
+	}
+
+	// Only the latest run is kept, so an older job can't be reported
+	if wanted := r.URL.Query().Get("job_id"); wanted != "" && wanted != jobID.String {
+		writeError(w, fmt.Errorf("scoring job %s not found or replaced by a later run: %w", wanted, apperrors.ErrNotFound))
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSON(w, http.StatusOK, map[string]any{"state": "idle", "run": nil})
+		return
+	}
+	run.JobID = jobID.String
+
+	if elapsed := run.UpdatedAt.Sub(run.StartedAt).Seconds(); elapsed > 0 {
+		run.Rate = float64(run.Processed) / elapsed
+	}
+
+	state := "running"
+	switch {
+	case finishedAt.Valid:
+		state = "idle"
+		run.FinishedAt = &finishedAt.Time
+	case time.Since(run.UpdatedAt) > scoringStalledAfter:
+		state = "stalled"
+	case run.Rate > 0:
+		eta := float64(run.TotalRecords-run.Processed) / run.Rate
+		run.ETASeconds = &eta
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"state": state, "run": run})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"charitylens/internal/database"
+	"charitylens/internal/models"
+	"charitylens/internal/scoring"
+)
+
+// Similar charities returned by default and at most
+const (
+	similarPageSize    = 10
+	maxSimilarPageSize = 25
+)
+
+// incomeBand is a range of latest-year income, with Max nil for the open top band
+type incomeBand struct {
+	Label string   `json:"label"`
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+}
+
+// incomeBandFor returns the scoring.IncomeBandLimits band containing income
+func incomeBandFor(income float64) incomeBand {
+	band := scoring.IncomeBand(income)
+	lower := 0.0
+	if band > 0 {
+		lower = scoring.IncomeBandLimits[band-1]
+	}
+	if band == len(scoring.IncomeBandLimits) {
+		return incomeBand{Label: "Over £" + bandAmount(lower), Min: lower}
+	}
+	upper := scoring.IncomeBandLimits[band]
+	return incomeBand{Label: fmt.Sprintf("£%s to £%s", bandAmount(lower), bandAmount(upper)), Min: lower, Max: &upper}
+}
+
+// bandAmount formats a band limit as e.g. "10k" or "5m"
+func bandAmount(amount float64) string {
+	switch {
+	case amount >= 1_000_000:
+		return fmt.Sprintf("%gm", amount/1_000_000)
+	case amount >= 1_000:
+		return fmt.Sprintf("%gk", amount/1_000)
+	default:
+		return fmt.Sprintf("%g", amount)
+	}
+}
+
+// similarCharity is a charity returned by GetSimilarCharities with the figures it
+// was ranked on
+type similarCharity struct {
+	models.Charity
+	LatestIncome          float64 `json:"latest_income"`
+	SharedClassifications int     `json:"shared_classifications"`
+}
+
+// GetSimilarCharities returns main, non-removed charities in the same income band
+// as the requested one, based on each charity's latest financial year. They're
+// ranked by the number of classification codes (cause areas, beneficiaries and
+// operations) shared with it, then by how close their income is. A charity with
+// no financial data has no band, so gets no results.
+func (h *CharityHandler) GetSimilarCharities(w http.ResponseWriter, r *http.Request) {
+	number, ok := h.charityNumberParam(w, r)
+	if !ok {
+		return
+	}
+	limit, _ := parsePage(r, similarPageSize, maxSimilarPageSize)
+
+	var income float64
+	err := h.dbs.Reader().QueryRow(`
+		SELECT COALESCE(total_income, 0) FROM financials
+		WHERE charity_number = ?
+		ORDER BY financial_year_end DESC
+		LIMIT 1
+	`, number).Scan(&income)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"charity_number": number,
+			"income_band":    nil,
+			"results":        []similarCharity{},
+			"total":          0,
+		})
+		return
+	}
+	if err != nil {
+		writeError(w, fmt.Errorf("loading income: %w", err))
+		return
+	}
+
+	band := incomeBandFor(income)
+	upper := math.MaxFloat64
+	if band.Max != nil {
+		upper = *band.Max
+	}
+
+	// Only each charity's latest year counts, so a charity that has since
+	// grown or shrunk out of the band isn't matched on old accounts
+	rows, err := h.dbs.Reader().Query(`
+		SELECT c.organisation_number, c.registered_number, c.linked_charity_number,
+		       COALESCE(c.company_number, ''), c.name, c.status, c.address, c.website,
+		       COALESCE(s.overall_score, 0), f.total_income,
+		       (SELECT COUNT(*) FROM charity_classifications cc
+		        WHERE cc.registered_charity_number = c.registered_number
+		          AND cc.classification_code IN (
+			        SELECT classification_code FROM charity_classifications
+			        WHERE registered_charity_number = ?
+		          )) AS shared
+		FROM financials f
+		JOIN charities c ON c.registered_number = f.charity_number
+		LEFT JOIN charity_scores s ON c.registered_number = s.charity_number
+		WHERE f.total_income >= ? AND f.total_income < ?
+		  AND f.financial_year_end = (
+			SELECT MAX(financial_year_end) FROM financials
+			WHERE charity_number = f.charity_number
+		  )
+		  AND c.registered_number != ?
+		  AND c.linked_charity_number = 0
+		  AND `+database.ListedSQL("c.status")+`
+		ORDER BY shared DESC, ABS(f.total_income - ?), c.registered_number
+		LIMIT ?
+	`, number, band.Min, upper, number, income, limit)
+	if err != nil {
+		writeError(w, fmt.Errorf("finding similar charities: %w", err))
+		return
+	}
+	defer rows.Close()
+
+	results := []similarCharity{}
+	for rows.Next() {
+		var result similarCharity
+		var address, website sql.NullString
+		if err := rows.Scan(
+			&result.OrganisationNumber, &result.RegisteredNumber, &result.LinkedCharityNumber,
+			&result.CompanyNumber, &result.Name, &result.Status, &address, &website,
+			&result.OverallScore, &result.LatestIncome, &result.SharedClassifications,
+		); err != nil {
+			writeError(w, fmt.Errorf("scanning charity: %w", err))
+			return
+		}
+		result.Address = address.String
+		result.Website = website.String
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(w, fmt.Errorf("finding similar charities: %w", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"charity_number": number,
+		"income_band":    band,
+		"results":        results,
+		"total":          len(results),
+	})
+}
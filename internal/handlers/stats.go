@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"charitylens/internal/database"
+)
+
+// statsCacheTTL controls how long aggregate stats are reused before recomputing
+const statsCacheTTL = 5 * time.Minute
+
+// DatasetStats holds headline aggregate metrics for the dataset
+type DatasetStats struct {
+	TotalCharities  int        `json:"total_charities"`
+	ScoredCharities int        `json:"scored_charities"`
+	AverageScore    float64    `json:"average_score"`
+	TotalIncome     float64    `json:"total_income"`
+	LastUpdated     *time.Time `json:"last_updated"`
+	GeneratedAt     time.Time  `json:"generated_at"`
+}
+
+// statsCache holds the most recently computed stats
+type statsCache struct {
+	mu    sync.Mutex
+	stats *DatasetStats
+}
+
+// GetStats returns aggregate metrics across all main, non-removed charities
+func (h *CharityHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	h.stats.mu.Lock()
+	defer h.stats.mu.Unlock()
+
+	if h.stats.stats == nil || time.Since(h.stats.stats.GeneratedAt) > statsCacheTTL {
+		stats, err := h.computeStats()
+		if err != nil {
+			writeError(w, fmt.Errorf("computing stats: %w", err))
+			return
+		}
+		h.stats.stats = stats
+	}
+
+	writeJSON(w, http.StatusOK, h.stats.stats)
+}
+
+// computeStats runs the aggregate queries behind GetStats
+func (h *CharityHandler) computeStats() (*DatasetStats, error) {
+	stats := &DatasetStats{GeneratedAt: time.Now()}
+
+	// Charity and score counts (main charities only, exclude removed)
+	var averageScore sql.NullFloat64
+	err := h.dbs.Reader().QueryRow(`
+		SELECT COUNT(*), COUNT(s.charity_number), AVG(s.overall_score)
+		FROM charities c
+		LEFT JOIN charity_scores s ON c.registered_number = s.charity_number
+		WHERE c.linked_charity_number = 0
+		  AND `+database.ListedSQL("c.status")+`
+	`).Scan(&stats.TotalCharities, &stats.ScoredCharities, &averageScore)
+	if err != nil {
+		return nil, err
+	}
+	if averageScore.Valid {
+		stats.AverageScore = averageScore.Float64
+	}
+
+	// Total income from each charity's latest financial year
+	var totalIncome sql.NullFloat64
+	err = h.dbs.Reader().QueryRow(`
+		SELECT SUM(f.total_income)
+		FROM financials f
+		JOIN (
+			SELECT charity_number, MAX(financial_year_end) AS latest
+			FROM financials
+			GROUP BY charity_number
+		) l ON f.charity_number = l.charity_number AND f.financial_year_end = l.latest
+		JOIN charities c ON c.registered_number = f.charity_number
+		WHERE c.linked_charity_number = 0
+		  AND ` + database.ListedSQL("c.status") + `
+	`).Scan(&totalIncome)
+	if err != nil {
+		return nil, err
+	}
+	if totalIncome.Valid {
+		stats.TotalIncome = totalIncome.Float64
+	}
+
+	// Most recent data refresh
+	var lastUpdated sql.NullTime
+	err = h.dbs.Reader().QueryRow(`
+		SELECT last_updated FROM charities
+		WHERE last_updated IS NOT NULL
+		  AND linked_charity_number = 0
+		  AND ` + database.ListedSQL("status") + `
+		ORDER BY last_updated DESC LIMIT 1
+	`).Scan(&lastUpdated)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if lastUpdated.Valid {
+		stats.LastUpdated = &lastUpdated.Time
+	}
+
+	return stats, nil
+}
@@ -1,268 +1,369 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
-	"log"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"charitylens/internal/config"
+	"charitylens/internal/database"
+	apperrors "charitylens/internal/errors"
+	"charitylens/internal/logger"
 	"charitylens/internal/models"
-	"charitylens/internal/scoring"
 	"charitylens/internal/sync"
+	"charitylens/internal/validation"
 	"charitylens/web/templates"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// charityPageMaxAge is how long browsers may reuse a charity page before
+// revalidating it with If-Modified-Since
+const charityPageMaxAge = time.Minute
+
 type WebHandler struct {
-	DB  *sql.DB
-	Cfg *config.Config
+	DB      *sql.DB // Primary database
+	Cfg     *config.Config
+	refresh *refreshGuard
+	pages   *pageCache
+
+	// dbs sends charity page reads to the read replica, if any
+	dbs *database.DB
+
+	// started is when the handler was created; pages are never older, as a
+	// restart may change templates or scoring configuration
+	started time.Time
 }
 
-func NewWebHandler(db *sql.DB, cfg *config.Config) *WebHandler {
-	return &WebHandler{DB: db, Cfg: cfg}
+func NewWebHandler(dbs *database.DB, cfg *config.Config) *WebHandler {
+	return &WebHandler{DB: dbs.Writer(), Cfg: cfg, refresh: newRefreshGuard(), pages: getPageCache(cfg), dbs: dbs, started: time.Now()}
 }
 
 func (h *WebHandler) SearchPage(w http.ResponseWriter, r *http.Request) {
-	if err := templates.Templates.ExecuteTemplate(w, "index.html", nil); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+	h.renderPage(w, r, http.StatusOK, "index.html", nil)
 }
 
 func (h *WebHandler) CharityPage(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	number, err := strconv.Atoi(idStr)
-	if err != nil || number < 1 || number > 9999999999 {
+	if err == nil {
+		err = validation.ValidateCharityNumber(number)
+	}
+	if err != nil {
 		http.Error(w, "Invalid charity ID", http.StatusBadRequest)
 		return
 	}
 
-	// Check if we have basic charity info (main charity only, linked_charity_number = 0)
-	var charity models.Charity
-	var website, email, address, whatTheCharityDoes sql.NullString
-	err = h.DB.QueryRow(`
-		SELECT registered_number, name, status, date_registered, address, website, email, what_the_charity_does
-		FROM charities WHERE registered_number = ? AND linked_charity_number = 0
-	`, number).Scan(
-		&charity.RegisteredNumber, &charity.Name, &charity.Status,
-		&charity.DateRegistered, &address, &website,
-		&email, &whatTheCharityDoes,
-	)
-
-	// Convert NullString to string
-	if address.Valid {
-		charity.Address = address.String
+	// The same URL serves HTML to browsers and JSON to API clients
+	w.Header().Set("Vary", "Accept")
+
+	// Only a rendered charity page may be cached, not the loading or error pages
+	w.Header().Set("Cache-Control", "no-store")
+
+	// ?refresh=1 pulls the latest data from the live API before rendering; the score
+	// is recalculated from the fresh data when the charity is loaded below
+	if r.URL.Query().Get("refresh") == "1" && !h.Cfg.OfflineMode {
+		switch h.refresh.check(clientIP(r), number) {
+		case refreshAllowed:
+			logger.Info("Refreshing charity from the API", "operation", "refresh", "charity_number", number)
+			ctx, cancel := context.WithTimeout(r.Context(), refreshTimeout)
+			err := sync.FetchAndStoreCharityContext(ctx, h.Cfg, h.DB, strconv.Itoa(number))
+			cancel()
+			if err != nil {
+				// Fall back to the stored data
+				logger.Error("Failed to refresh charity", "operation", "refresh", "charity_number", number, "error", err)
+			} else {
+				h.pages.invalidate(number)
+			}
+		case refreshRateLimited:
+			w.Header().Set("Retry-After", strconv.Itoa(int(refreshWindow.Seconds())))
+			if wantsJSON(r) {
+				writeError(w, fmt.Errorf("too many refresh requests: %w", apperrors.ErrRateLimit))
+				return
+			}
+			errorData := errorPage{
+				Code:     429,
+				Title:    "Too Many Refreshes",
+				Message:  "You've refreshed too many charities recently. Please try again later.",
+				RetryURL: r.URL.Path,
+				Meta:     h.defaultPageMeta(r),
+			}
+
+			h.renderPage(w, r, http.StatusTooManyRequests, "error.html", errorData)
+			return
+		}
 	}
-	if website.Valid {
-		charity.Website = website.String
+	if wantsJSON(r) {
+		serveCharityJSON(w, h.dbs, h.Cfg, number)
+		return
 	}
-	if email.Valid {
-		charity.Email = email.String
+
+	// Answer a browser revalidating its copy without rendering, if nothing changed
+	modified, hasModified := h.charityLastModified(number)
+	if hasModified && notModifiedSince(r, modified) {
+		setPageCaching(w, modified)
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
-	if whatTheCharityDoes.Valid {
-		charity.WhatTheCharityDoes = whatTheCharityDoes.String
+
+	// Serve a recently rendered page if the cache is enabled
+	if body, ok := h.pages.get(number); ok {
+		if hasModified {
+			setPageCaching(w, modified)
+		}
+		writeHTML(w, body)
+		return
 	}
 
+	// Load the charity with its score, financials, trustees and activities
+	detail, err := loadCharityWithScore(h.dbs, number, h.Cfg.OfflineMode)
+
 	// If charity not found, try to sync it first (unless in offline mode)
 	if err == sql.ErrNoRows {
 		if h.Cfg.OfflineMode {
 			// In offline mode, just show not found error
-			errorData := struct {
-				Code      int
-				Title     string
-				Message   string
-				IsLoading bool
-				RetryURL  string
-			}{
+			errorData := errorPage{
 				Code:    404,
 				Title:   "Charity Not Found",
 				Message: "We couldn't find this charity in our database. Please check the charity number is correct.",
+				Meta:    h.defaultPageMeta(r),
+			}
+
+			h.renderPage(w, r, http.StatusOK, "error.html", errorData)
+			return
+		}
+
+		// Stop showing the loading page once the background sync has given up
+		if status, ok := sync.BackgroundSyncStatus(number); ok && status.Failed() {
+			errorData := errorPage{
+				Code:    503,
+				Title:   "Charity Unavailable",
+				Message: "We couldn't fetch this charity from the Charity Commission. Please try again later.",
+				Meta:    h.defaultPageMeta(r),
 			}
+			if status.NotFound() {
+				errorData.Code = 404
+				errorData.Title = "Charity Not Found"
+				errorData.Message = "We couldn't find this charity in our database or on the Charity Commission register. Please check the charity number is correct."
+			}
+
+			h.renderPage(w, r, errorData.Code, "error.html", errorData)
+			return
+		}
 
-			if err := templates.Templates.ExecuteTemplate(w, "error.html", errorData); err != nil {
-				http.Error(w, "Error rendering page", http.StatusInternalServerError)
+		// There's no point waiting on a sync that can't be made until the API
+		// budget resets
+		if sync.APIBudgetExhausted() {
+			errorData := errorPage{
+				Code:     503,
+				Title:    "Charity Unavailable",
+				Message:  "We've reached our limit of requests to the Charity Commission for now, so we can't fetch this charity. Please try again later.",
+				RetryURL: r.URL.Path,
+				Meta:     h.defaultPageMeta(r),
 			}
+
+			h.renderPage(w, r, errorData.Code, "error.html", errorData)
 			return
 		}
 
-		log.Printf("Charity %d not found in database, showing loading page", number)
+		logger.Info("Charity not found in database, showing loading page", "operation", "sync", "charity_number", number)
 
 		// Show loading page
-		errorData := struct {
-			Code      int
-			Title     string
-			Message   string
-			IsLoading bool
-			RetryURL  string
-		}{
+		errorData := errorPage{
 			IsLoading: true,
+			Meta:      h.defaultPageMeta(r),
 		}
 
-		if err := templates.Templates.ExecuteTemplate(w, "error.html", errorData); err != nil {
-			http.Error(w, "Error rendering page", http.StatusInternalServerError)
-		}
+		h.renderPage(w, r, http.StatusOK, "error.html", errorData)
 
-		// Trigger background sync
-		go func() {
-			log.Printf("Starting background sync for charity %d", number)
-			if syncErr := sync.FetchAndStoreCharity(h.Cfg, h.DB, strconv.Itoa(number)); syncErr != nil {
-				log.Printf("Failed to sync charity %d: %v", number, syncErr)
-			} else {
-				log.Printf("Successfully synced charity %d", number)
-			}
-		}()
+		// Trigger background sync; reloads of the loading page while it's running
+		// don't start another
+		sync.SyncInBackground(h.Cfg, h.DB, number, nil)
 
 		return
 	} else if err != nil {
 		// Database error
-		log.Printf("Database error fetching charity %d: %v", number, err)
-		errorData := struct {
-			Code      int
-			Title     string
-			Message   string
-			IsLoading bool
-			RetryURL  string
-		}{
+		logger.Error("Database error fetching charity", "operation", "load", "charity_number", number, "error", err)
+		errorData := errorPage{
 			Code:     500,
 			Title:    "Database Error",
 			Message:  "We're having trouble accessing our database. Please try again later.",
 			RetryURL: r.URL.Path,
+			Meta:     h.defaultPageMeta(r),
 		}
 
-		if err := templates.Templates.ExecuteTemplate(w, "error.html", errorData); err != nil {
-			http.Error(w, "Error rendering page", http.StatusInternalServerError)
-		}
+		h.renderPage(w, r, http.StatusOK, "error.html", errorData)
 		return
 	}
 
 	// Check if charity is removed
-	if charity.Status == "Removed" || charity.Status == "RM" {
-		errorData := struct {
-			Code      int
-			Title     string
-			Message   string
-			IsLoading bool
-			RetryURL  string
-		}{
+	if database.IsRemoved(detail.Charity.Status) {
+		errorData := errorPage{
 			Code:    404,
 			Title:   "Charity Removed",
-			Message: "This charity has been removed from the register and is no longer active.",
+			Message: removalMessage(detail.Removal),
+			Meta:    h.defaultPageMeta(r),
 		}
 
-		if err := templates.Templates.ExecuteTemplate(w, "error.html", errorData); err != nil {
-			http.Error(w, "Error rendering page", http.StatusInternalServerError)
-		}
+		h.renderPage(w, r, http.StatusOK, "error.html", errorData)
 		return
 	}
 
-	// Calculate score (don't cache in offline mode - always fresh)
-	score, err := scoring.CalculateScore(h.DB, number, !h.Cfg.OfflineMode)
+	page := charityPage{CharityDetail: detail, Meta: h.charityPageMeta(detail)}
+	body, err := renderTemplate("charity.html", page)
 	if err != nil {
-		log.Printf("Failed to calculate score for charity %d: %v", number, err)
-		score = models.CharityScore{}
+		h.renderFailed(w, r, "charity.html", err)
+		return
 	}
 
-	// Get trustees
-	trusteeRows, err := h.DB.Query(`
-		SELECT name FROM trustees WHERE charity_number = ? ORDER BY name
-	`, number)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	h.pages.set(number, body)
+	if hasModified {
+		setPageCaching(w, modified)
 	}
-	defer trusteeRows.Close()
-
-	var trustees []models.Trustee
-	for trusteeRows.Next() {
-		var trustee models.Trustee
-		err := trusteeRows.Scan(&trustee.Name)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+	writeHTML(w, body)
+}
+
+// charityLastModified returns when a main, non-removed charity's page last
+// changed: the latest of when its data, financials or trustees were last updated,
+// when its stored score was last calculated, and when the server started. ok is
+// false for a charity that isn't stored, is removed, or has no last_updated,
+// whose pages aren't cacheable.
+func (h *WebHandler) charityLastModified(number int) (time.Time, bool) {
+	times, err := loadCharityTimes(h.dbs.Reader(), number)
+	if err != nil || times.removed || times.dataUpdated.IsZero() {
+		return time.Time{}, false
+	}
+
+	modified := times.dataUpdated
+	for _, t := range []time.Time{times.scored, h.started} {
+		if t.After(modified) {
+			modified = t
 		}
-		trustees = append(trustees, trustee)
 	}
+	// HTTP dates only have whole seconds
+	return modified.UTC().Truncate(time.Second), true
+}
+
+// notModifiedSince reports whether a request's If-Modified-Since is no earlier
+// than modified
+func notModifiedSince(r *http.Request, modified time.Time) bool {
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.After(since)
+}
+
+// setPageCaching lets browsers cache a rendered page briefly, then revalidate it
+func setPageCaching(w http.ResponseWriter, modified time.Time) {
+	w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(charityPageMaxAge.Seconds())))
+}
 
-	// Get financial data
-	var financial models.Financial
-	var trusteeCount sql.NullInt64
-	err = h.DB.QueryRow(`
-		SELECT financial_year_end, total_income, total_spending, charitable_activities_spend, 
-		       raising_funds_spend, other_spend, reserves, assets, trustees
-		FROM financials WHERE charity_number = ?
-		ORDER BY financial_year_end DESC LIMIT 1
-	`, number).Scan(
-		&financial.FinancialYearEnd, &financial.TotalIncome, &financial.TotalSpending,
-		&financial.CharitableActivitiesSpend, &financial.RaisingFundsSpend,
-		&financial.OtherSpend, &financial.Reserves, &financial.Assets, &trusteeCount,
-	)
-	if err != nil && err != sql.ErrNoRows {
-		log.Printf("Failed to get financial data for charity %d: %v", number, err)
+// removalMessage explains on the "Charity Removed" page why the charity was
+// removed, when the event history records it
+func removalMessage(removal *models.CharityRemoval) string {
+	msg := "This charity has been removed from the register"
+	if removal == nil {
+		return msg + " and is no longer active."
 	}
-	if trusteeCount.Valid {
-		financial.Trustees = int(trusteeCount.Int64)
+	if removal.DateRemoved != nil {
+		msg = "This charity was removed from the register on " + removal.DateRemoved.Format("2 January 2006")
 	}
-
-	// Get activities
-	activityRows, err := h.DB.Query(`
-		SELECT description FROM activities WHERE charity_number = ? ORDER BY description
-	`, number)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	if removal.Reason != "" {
+		msg += " (" + removal.Reason + ")"
 	}
-	defer activityRows.Close()
-
-	var activities []models.Activity
-	for activityRows.Next() {
-		var activity models.Activity
-		err := activityRows.Scan(&activity.Description)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+	msg += " and is no longer active."
+	if removal.SuccessorCharityNumber != 0 {
+		successor := fmt.Sprintf("charity %d", removal.SuccessorCharityNumber)
+		if removal.SuccessorName != "" {
+			successor = fmt.Sprintf("%s (%d)", removal.SuccessorName, removal.SuccessorCharityNumber)
 		}
-		activities = append(activities, activity)
+		msg += " Its funds were transferred to " + successor + "."
 	}
+	return msg
+}
 
-	data := struct {
-		Charity    models.Charity
-		Score      models.CharityScore
-		Financial  models.Financial
-		Trustees   []models.Trustee
-		Activities []models.Activity
-	}{
-		Charity:    charity,
-		Score:      score,
-		Financial:  financial,
-		Trustees:   trustees,
-		Activities: activities,
+// renderTemplate renders a page template in full, so nothing is sent if it fails
+// part way through
+func renderTemplate(name string, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.Templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
 	}
+	return buf.Bytes(), nil
+}
 
-	if err := templates.Templates.ExecuteTemplate(w, "charity.html", data); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// renderPage renders a page template and sends it with status. If the template
+// fails, a 500 error page is sent instead of a partly rendered page.
+func (h *WebHandler) renderPage(w http.ResponseWriter, r *http.Request, status int, name string, data any) {
+	body, err := renderTemplate(name, data)
+	if err != nil {
+		h.renderFailed(w, r, name, err)
 		return
 	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	writeHTML(w, body)
 }
 
-func (h *WebHandler) ComparePage(w http.ResponseWriter, r *http.Request) {
-	if err := templates.Templates.ExecuteTemplate(w, "compare.html", nil); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// renderFailed logs a template that failed to render and sends a 500 error page
+// in its place, falling back to plain text if the error page fails too
+func (h *WebHandler) renderFailed(w http.ResponseWriter, r *http.Request, name string, err error) {
+	logger.Error("Error rendering page", "template", name, "path", r.URL.Path, "error", err)
+
+	// Nothing rendered for this request may be cached
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Del("Last-Modified")
+
+	body, err := renderTemplate("error.html", errorPage{
+		Code:     500,
+		Title:    "Something Went Wrong",
+		Message:  "We couldn't display this page. Please try again later.",
+		RetryURL: r.URL.Path,
+		Meta:     h.defaultPageMeta(r),
+	})
+	if err != nil {
+		logger.Error("Error rendering error page", "path", r.URL.Path, "error", err)
+		http.Error(w, "Error rendering page", http.StatusInternalServerError)
 		return
 	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	writeHTML(w, body)
 }
 
-func (h *WebHandler) LicensePage(w http.ResponseWriter, r *http.Request) {
-	if err := templates.Templates.ExecuteTemplate(w, "license.html", nil); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+// writeHTML writes a pre-rendered HTML page
+func writeHTML(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write(body); err != nil {
+		logger.Error("Error writing page", "error", err)
 	}
 }
 
-func (h *WebHandler) MethodologyPage(w http.ResponseWriter, r *http.Request) {
-	if err := templates.Templates.ExecuteTemplate(w, "methodology.html", nil); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+// ComparePage renders the compare page with one selector slot per charity allowed
+// by MaxCompareCharities
+func (h *WebHandler) ComparePage(w http.ResponseWriter, r *http.Request) {
+	slots := make([]int, h.Cfg.MaxCompareCharities)
+	for i := range slots {
+		slots[i] = i + 1
 	}
+
+	data := struct {
+		MaxCharities int
+		Slots        []int
+	}{
+		MaxCharities: h.Cfg.MaxCompareCharities,
+		Slots:        slots,
+	}
+	h.renderPage(w, r, http.StatusOK, "compare.html", data)
+}
+
+func (h *WebHandler) LicensePage(w http.ResponseWriter, r *http.Request) {
+	h.renderPage(w, r, http.StatusOK, "license.html", nil)
+}
+
+func (h *WebHandler) MethodologyPage(w http.ResponseWriter, r *http.Request) {
+	h.renderPage(w, r, http.StatusOK, "methodology.html", nil)
 }
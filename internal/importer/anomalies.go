@@ -0,0 +1,115 @@
+package importer
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// defaultMaxExpenditureRatio is how many times its income a charity can spend in
+// a year before the figures are treated as implausible
+const defaultMaxExpenditureRatio = 100
+
+// maxAnomalyExamples caps the anomalies kept for the report; the rest are only
+// counted
+const maxAnomalyExamples = 20
+
+// FinancialAnomaly is a year of financial data rejected as implausible
+type FinancialAnomaly struct {
+	CharityNumber int       `json:"charity_number"`
+	YearEnd       time.Time `json:"year_end"`
+	Income        float64   `json:"income"`
+	Expenditure   float64   `json:"expenditure"`
+	Reason        string    `json:"reason"`
+}
+
+// AnomalyReport summarises the financial data rejected during an import
+type AnomalyReport struct {
+	Total    int                `json:"total"`
+	ByReason map[string]int     `json:"by_reason"`
+	Examples []FinancialAnomaly `json:"examples"` // The first maxAnomalyExamples
+}
+
+// checkFinancials reports whether a year's income and expenditure are plausible,
+// recording an anomaly if not. Negative figures are rejected, as is spending more
+// than MaxExpenditureRatio times a positive income; a charity with no income
+// spending its reserves is left alone.
+func (i *Importer) checkFinancials(charityNum int, yearEnd time.Time, income, expenditure float64) bool {
+	var reason string
+	switch {
+	case income < 0:
+		reason = "negative income"
+	case expenditure < 0:
+		reason = "negative expenditure"
+	case i.config.MaxExpenditureRatio > 0 && income > 0 && expenditure > income*i.config.MaxExpenditureRatio:
+		reason = fmt.Sprintf("expenditure over %g times income", i.config.MaxExpenditureRatio)
+	default:
+		return true
+	}
+
+	if i.config.Verbose {
+		log.Printf("Rejected financial data for charity %d (year end %s): %s (income %.2f, expenditure %.2f)",
+			charityNum, yearEnd.Format("2006-01-02"), reason, income, expenditure)
+	}
+
+	i.progressMu.Lock()
+	defer i.progressMu.Unlock()
+	if i.anomalies.ByReason == nil {
+		i.anomalies.ByReason = make(map[string]int)
+	}
+	i.anomalies.Total++
+	i.anomalies.ByReason[reason]++
+	if len(i.anomalies.Examples) < maxAnomalyExamples {
+		i.anomalies.Examples = append(i.anomalies.Examples, FinancialAnomaly{
+			CharityNumber: charityNum,
+			YearEnd:       yearEnd,
+			Income:        income,
+			Expenditure:   expenditure,
+			Reason:        reason,
+		})
+	}
+	return false
+}
+
+// GetAnomalyReport returns the financial data rejected so far
+func (i *Importer) GetAnomalyReport() AnomalyReport {
+	i.progressMu.Lock()
+	defer i.progressMu.Unlock()
+
+	report := AnomalyReport{
+		Total:    i.anomalies.Total,
+		ByReason: make(map[string]int, len(i.anomalies.ByReason)),
+		Examples: append([]FinancialAnomaly{}, i.anomalies.Examples...),
+	}
+	for reason, count := range i.anomalies.ByReason {
+		report.ByReason[reason] = count
+	}
+	return report
+}
+
+// LogAnomalyReport logs the financial data rejected during the import, if any
+func (i *Importer) LogAnomalyReport() {
+	report := i.GetAnomalyReport()
+	if report.Total == 0 {
+		return
+	}
+
+	log.Printf("\n=== Financial Anomalies ===")
+	log.Printf("Rejected %d years of implausible financial data", report.Total)
+
+	reasons := make([]string, 0, len(report.ByReason))
+	for reason := range report.ByReason {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		log.Printf("  %s: %d", reason, report.ByReason[reason])
+	}
+
+	log.Printf("Examples:")
+	for _, a := range report.Examples {
+		log.Printf("  Charity %d, year end %s: %s (income %.2f, expenditure %.2f)",
+			a.CharityNumber, a.YearEnd.Format("2006-01-02"), a.Reason, a.Income, a.Expenditure)
+	}
+}
@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"charitylens/internal/database"
+)
+
+// duplicateCharityAnomaly is the data_anomalies type of a charity number listed
+// twice as a main charity under different names
+const duplicateCharityAnomaly = "duplicate_charity_number"
+
+// mainCharity is the main entry imported for a charity number
+type mainCharity struct {
+	organisationNumber int
+	name               string
+}
+
+// checkDuplicate reports whether a main charity record repeats a charity number
+// already imported under a different name, which is an error in the extract.
+// The first entry is kept and the duplicate recorded in data_anomalies for
+// review, rather than silently replacing it. Only busy errors are returned, so
+// insertWithRetry can run the batch again.
+func (i *Importer) checkDuplicate(tx *sql.Tx, record CharityRecord) (bool, error) {
+	if record.LinkedCharityNumber != 0 {
+		return false, nil
+	}
+
+	kept, ok := i.mainCharities[record.RegisteredCharityNumber]
+	if !ok {
+		if i.mainCharities == nil {
+			i.mainCharities = make(map[int]mainCharity)
+		}
+		i.mainCharities[record.RegisteredCharityNumber] = mainCharity{
+			organisationNumber: record.OrganisationNumber,
+			name:               record.CharityName,
+		}
+		return false, nil
+	}
+	if strings.EqualFold(strings.TrimSpace(kept.name), strings.TrimSpace(record.CharityName)) {
+		return false, nil
+	}
+
+	details := fmt.Sprintf("kept %q (organisation %d), skipped %q (organisation %d)",
+		kept.name, kept.organisationNumber, record.CharityName, record.OrganisationNumber)
+	log.Printf("Duplicate charity number %d: %s", record.RegisteredCharityNumber, details)
+
+	_, err := tx.Exec(`
+		INSERT INTO data_anomalies (anomaly_type, charity_number, details, detected_at)
+		VALUES (?, ?, ?, ?)
+	`, duplicateCharityAnomaly, record.RegisteredCharityNumber, details, time.Now())
+	if database.IsBusy(err) {
+		return true, err
+	}
+	if err != nil {
+		log.Printf("Failed to record duplicate charity number %d: %v", record.RegisteredCharityNumber, err)
+	}
+	return true, nil
+}
+
+// DuplicateCharities returns how many main charity entries have been skipped as
+// duplicates of a charity number imported under a different name
+func (i *Importer) DuplicateCharities() int {
+	i.progressMu.Lock()
+	defer i.progressMu.Unlock()
+	return i.duplicates
+}
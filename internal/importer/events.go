@@ -0,0 +1,220 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"charitylens/internal/database"
+	"charitylens/internal/dates"
+)
+
+// removedEventType is the charity_event_type of the event recorded when a charity
+// is removed from the register
+const removedEventType = "Removed"
+
+// EventRecord represents a registration event (registered, removed, name change,
+// asset transfer, ...) from the charity_event_history JSON dump
+type EventRecord struct {
+	DateOfExtract                string  `json:"date_of_extract"`
+	OrganisationNumber           int     `json:"organisation_number"`
+	RegisteredCharityNumber      int     `json:"registered_charity_number"`
+	LinkedCharityNumber          int     `json:"linked_charity_number"`
+	CharityName                  string  `json:"charity_name"`
+	CharityEventType             string  `json:"charity_event_type"`
+	DateOfEvent                  *string `json:"date_of_event"`
+	Reason                       *string `json:"reason"`
+	AssocOrganisationNumber      *int    `json:"assoc_organisation_number"`
+	AssocRegisteredCharityNumber *int    `json:"assoc_registered_charity_number"`
+	AssocCharityName             *string `json:"assoc_charity_name"`
+}
+
+// ImportRemovalReasons imports why charities were removed from the register from
+// an event history file. Only removal events of main charities are kept; every
+// other event is counted as skipped.
+func (i *Importer) ImportRemovalReasons() error {
+	if i.config.EventHistoryFile == "" {
+		log.Println("No event history file specified, skipping")
+		return nil
+	}
+
+	log.Printf("Starting removal reason import from: %s", i.config.EventHistoryFile)
+	i.resetProgress(0)
+
+	readers, closeFiles, err := openInputs(i.config.EventHistoryFile)
+	if err != nil {
+		return fmt.Errorf("failed to open event history file: %w", err)
+	}
+	defer closeFiles()
+
+	return i.importRemovalReasonsFromReader(readers...)
+}
+
+// ImportRemovalReasonsFromReader imports removal reasons from event history data
+// in an io.Reader
+func (i *Importer) ImportRemovalReasonsFromReader(r io.Reader) error {
+	log.Println("Starting removal reason import from in-memory data")
+	i.resetProgress(0)
+
+	reader := stripBOM(r)
+	return i.importRemovalReasonsFromReader(reader)
+}
+
+// importRemovalReasonsFromReader is the internal implementation that works with any reader.
+// Several readers are imported in order as one dataset, sharing the batch,
+// progress and record limit.
+func (i *Importer) importRemovalReasonsFromReader(readers ...io.Reader) error {
+	batch := make([]EventRecord, 0, i.config.BatchSize)
+	recordNum := 0
+	var readErr error
+
+chunks:
+	for n, reader := range readers {
+		if len(readers) > 1 {
+			log.Printf("Reading file %d of %d", n+1, len(readers))
+		}
+		decoder := json.NewDecoder(reader)
+
+		// Stop at an empty or non-array file, keeping the records already read
+		if err := openArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
+		}
+
+		// Process array elements
+		for decoder.More() {
+			if i.reachedLimit(recordNum) {
+				break chunks
+			}
+
+			var record EventRecord
+			if err := decoder.Decode(&record); err != nil {
+				if !recordError(err) {
+					readErr = inputError(n, len(readers), fmt.Errorf("malformed JSON after record %d: %w", recordNum, err))
+					break chunks
+				}
+				log.Printf("Failed to decode event record %d: %v", recordNum, err)
+				i.addProgress(ImportProgress{FailedRecords: 1})
+				continue
+			}
+
+			batch = append(batch, record)
+			i.noteExtractDate(record.DateOfExtract)
+			recordNum++
+			i.setTotalRecords(recordNum)
+
+			// Process batch when full
+			if len(batch) >= i.config.BatchSize {
+				if err := i.insertWithRetry(func() error { return i.insertRemovalBatch(batch) }); err != nil {
+					log.Printf("Failed to insert removal reason batch: %v", err)
+				}
+				batch = batch[:0] // Reset batch
+			}
+
+			// Log progress
+			if recordNum%i.config.ProgressInterval == 0 {
+				i.logProgress()
+			}
+		}
+
+		// More also stops at the end of a file missing its closing bracket
+		if err := closeArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
+		}
+	}
+
+	// Process remaining records
+	if len(batch) > 0 {
+		if err := i.insertWithRetry(func() error { return i.insertRemovalBatch(batch) }); err != nil {
+			log.Printf("Failed to insert final removal reason batch: %v", err)
+		}
+	}
+
+	i.recordExtractDate("charity_event_history")
+	i.logFinalStats("Removal reason import")
+	return readErr
+}
+
+// insertRemovalBatch stores the removal events in a batch of event records. A
+// charity that was re-registered and removed again keeps its latest removal,
+// whatever order the events arrive in.
+func (i *Importer) insertRemovalBatch(records []EventRecord) error {
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO charity_removals
+		(charity_number, reason, date_removed, successor_charity_number, successor_name, last_updated)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (charity_number) DO UPDATE SET
+			reason = excluded.reason,
+			date_removed = excluded.date_removed,
+			successor_charity_number = excluded.successor_charity_number,
+			successor_name = excluded.successor_name,
+			last_updated = excluded.last_updated
+		WHERE charity_removals.date_removed IS NULL
+		   OR excluded.date_removed >= charity_removals.date_removed
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		// Only removals of main charities are kept
+		if record.RegisteredCharityNumber == 0 || record.LinkedCharityNumber != 0 || i.isExcluded(record.RegisteredCharityNumber) ||
+			!strings.EqualFold(strings.TrimSpace(record.CharityEventType), removedEventType) {
+			i.addProgress(ImportProgress{SkippedRecords: 1})
+			continue
+		}
+
+		var dateRemoved *time.Time
+		if record.DateOfEvent != nil {
+			if dr := dates.Parse(*record.DateOfEvent); !dr.IsZero() {
+				dateRemoved = &dr
+			}
+		}
+		var reason *string
+		if record.Reason != nil && strings.TrimSpace(*record.Reason) != "" {
+			r := strings.TrimSpace(*record.Reason)
+			reason = &r
+		}
+
+		_, err := stmt.Exec(
+			record.RegisteredCharityNumber,
+			reason,
+			dateRemoved,
+			record.AssocRegisteredCharityNumber,
+			record.AssocCharityName,
+			time.Now(),
+		)
+		if database.IsBusy(err) {
+			// Abandon the batch so insertWithRetry can run it again
+			return err
+		}
+		if err != nil {
+			if i.config.Verbose {
+				log.Printf("Failed to insert removal reason for charity %d: %v", record.RegisteredCharityNumber, err)
+			}
+			i.addProgress(ImportProgress{FailedRecords: 1})
+			continue
+		}
+
+		i.addProgress(ImportProgress{SuccessRecords: 1})
+	}
+
+	i.addProgress(ImportProgress{ProcessedRecords: len(records)})
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package importer
+
+import "strings"
+
+// filtering reports whether any charity filters are set in the ImportConfig
+func (i *Importer) filtering() bool {
+	return len(i.config.FilterPostcodePrefixes) > 0 || i.config.MinLatestIncome > 0
+}
+
+// matchesFilters reports whether a charity record passes the ImportConfig
+// filters. A charity without a postcode or latest income fails the corresponding
+// filter.
+func (i *Importer) matchesFilters(record CharityRecord) bool {
+	if i.config.MinLatestIncome > 0 &&
+		(record.LatestIncome == nil || *record.LatestIncome < i.config.MinLatestIncome) {
+		return false
+	}
+
+	if len(i.config.FilterPostcodePrefixes) > 0 {
+		if record.CharityContactPostcode == nil {
+			return false
+		}
+		postcode := normalisePostcode(*record.CharityContactPostcode)
+		for _, prefix := range i.config.FilterPostcodePrefixes {
+			if prefix = normalisePostcode(prefix); prefix != "" && strings.HasPrefix(postcode, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// excludeCharity notes a charity the filters rejected, so its records in the
+// other datasets are skipped as well
+func (i *Importer) excludeCharity(charityNum int) {
+	if i.excluded == nil {
+		i.excluded = make(map[int]bool)
+	}
+	i.excluded[charityNum] = true
+}
+
+// isExcluded reports whether a charity was filtered out of the charity import.
+// Datasets imported without the charity file are never filtered.
+func (i *Importer) isExcluded(charityNum int) bool {
+	return i.excluded[charityNum]
+}
+
+// normalisePostcode upper-cases a postcode or prefix and removes its spaces, so
+// "sw1a 1aa" matches the prefix "SW1A"
+func normalisePostcode(postcode string) string {
+	return strings.ToUpper(strings.ReplaceAll(postcode, " ", ""))
+}
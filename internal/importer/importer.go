@@ -8,10 +8,14 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"os"
+	"sync"
 	"time"
 
+	"charitylens/internal/database"
+	"charitylens/internal/dates"
+	"charitylens/internal/models"
 	"charitylens/internal/scoring"
+	"charitylens/internal/validation"
 )
 
 // CharityRecord represents a charity record from the JSON dump
@@ -102,6 +106,17 @@ type AnnualReturnHistoryRecord struct {
 	SuppressionType          *string  `json:"suppression_type"`
 }
 
+// ClassificationRecord represents a what/who/how classification from the JSON dump
+type ClassificationRecord struct {
+	DateOfExtract             string `json:"date_of_extract"`
+	OrganisationNumber        int    `json:"organisation_number"`
+	RegisteredCharityNumber   int    `json:"registered_charity_number"`
+	LinkedCharityNumber       int    `json:"linked_charity_number"`
+	ClassificationCode        string `json:"classification_code"`
+	ClassificationType        string `json:"classification_type"`
+	ClassificationDescription string `json:"classification_description"`
+}
+
 // ImportProgress tracks import progress
 type ImportProgress struct {
 	TotalRecords     int
@@ -113,15 +128,64 @@ type ImportProgress struct {
 	LastUpdate       time.Time
 }
 
-// ImportConfig holds configuration for the import process
+// PhaseStats summarises a completed import phase (e.g. "Charity import")
+type PhaseStats struct {
+	Label           string  `json:"label"`
+	TotalRecords    int     `json:"total_records"`
+	Processed       int     `json:"processed"`
+	Successful      int     `json:"successful"`
+	Failed          int     `json:"failed"`
+	Skipped         int     `json:"skipped"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Rate            float64 `json:"rate"`
+}
+
+// ImportConfig holds configuration for the import process. Each file path may
+// also be a directory or glob pattern matching a set of chunk files, which are
+// imported in order as one dataset.
 type ImportConfig struct {
 	CharityFile             string
 	TrusteeFile             string
 	FinancialFile           string // Annual return partb file
+	PartAFile               string // Annual return parta file
 	AnnualReturnHistoryFile string // Annual return history file
+	ClassificationFile      string // Charity classification file
+	EventHistoryFile        string // Charity event history file (removal reasons)
 	BatchSize               int
 	ProgressInterval        int // Log progress every N records
+	MaxRecords              int // Stop after decoding N records per dataset (0 = unlimited)
 	Verbose                 bool
+
+	// ScoreSince limits CalculateAllScores to charities updated at or after this
+	// time (zero = all charities)
+	ScoreSince time.Time
+
+	// ScoreDuringImport scores each charity as soon as its financials are imported,
+	// overlapping scoring with the rest of the financial import. Charities, trustees
+	// and annual return history must be imported first.
+	ScoreDuringImport bool
+
+	// FilterPostcodePrefixes and MinLatestIncome limit the import to matching
+	// charities, e.g. for a regional dataset: only charities whose postcode starts
+	// with one of the prefixes (case and spaces ignored) and whose latest income is
+	// at least MinLatestIncome. Other charities are skipped, along with their
+	// records in the datasets imported after them. Unset filters match everything.
+	FilterPostcodePrefixes []string
+	MinLatestIncome        float64
+
+	// ScoringJobID identifies scoring runs in the scoring_progress table, e.g. an
+	// admin rescore, so their progress can be looked up by job
+	ScoringJobID string
+
+	// MaxExpenditureRatio rejects a year's financial data when expenditure is more
+	// than this many times income (0 = defaultMaxExpenditureRatio, negative =
+	// never). Negative income or expenditure is always rejected.
+	MaxExpenditureRatio float64
+
+	// ImportAllPeriods imports every financial period in the annual return Part B
+	// file, keyed by charity and financial year end, rather than only each
+	// charity's latest, giving the financial history a multi-year trend
+	ImportAllPeriods bool
 }
 
 // Importer handles importing charity data from JSON files
@@ -129,6 +193,40 @@ type Importer struct {
 	db       *sql.DB
 	config   ImportConfig
 	progress ImportProgress
+	phases   []PhaseStats
+
+	// extractDate is the latest date_of_extract seen in the file being imported
+	extractDate time.Time
+
+	// scoreQueue receives charities whose financials have been imported, while a
+	// score worker is running
+	scoreQueue chan int
+
+	// scoringPublished is when a scoring run last published its progress
+	scoringPublished time.Time
+
+	// progressMu guards progress, which GetProgress may read from another
+	// goroutine while a phase is running
+	progressMu sync.Mutex
+
+	// excluded holds the main charities rejected by the import filters
+	excluded map[int]bool
+
+	// anomalies records the financial data rejected as implausible, guarded by
+	// progressMu
+	anomalies AnomalyReport
+
+	// mainCharities holds the main entry imported for each charity number, to
+	// catch an extract listing one twice
+	mainCharities map[int]mainCharity
+
+	// duplicates counts the main entries skipped by checkDuplicate, guarded by
+	// progressMu
+	duplicates int
+
+	// trustees counts the trustees imported that were new and those already
+	// stored, guarded by progressMu
+	trustees TrusteeCounts
 }
 
 // NewImporter creates a new importer
@@ -139,6 +237,9 @@ func NewImporter(db *sql.DB, config ImportConfig) *Importer {
 	if config.ProgressInterval == 0 {
 		config.ProgressInterval = 5000
 	}
+	if config.MaxExpenditureRatio == 0 {
+		config.MaxExpenditureRatio = defaultMaxExpenditureRatio
+	}
 	return &Importer{
 		db:     db,
 		config: config,
@@ -158,174 +259,207 @@ func stripBOM(r io.Reader) io.Reader {
 	return br
 }
 
-// ImportCharities imports charities from a JSON file
+// ImportCharities imports charities from a JSON file or set of chunk files
 func (i *Importer) ImportCharities() error {
 	log.Printf("Starting charity import from: %s", i.config.CharityFile)
-	i.progress = ImportProgress{
-		StartTime:  time.Now(),
-		LastUpdate: time.Now(),
-	}
+	i.resetProgress(0)
 
-	file, err := os.Open(i.config.CharityFile)
+	readers, closeFiles, err := openInputs(i.config.CharityFile)
 	if err != nil {
 		return fmt.Errorf("failed to open charity file: %w", err)
 	}
-	defer file.Close()
+	defer closeFiles()
 
-	// Strip BOM if present
-	reader := stripBOM(file)
-	return i.importCharitiesFromReader(reader)
+	return i.importCharitiesFromReader(readers...)
 }
 
 // ImportCharitiesFromReader imports charities from an io.Reader (for in-memory data)
 func (i *Importer) ImportCharitiesFromReader(r io.Reader) error {
 	log.Println("Starting charity import from in-memory data")
-	i.progress = ImportProgress{
-		StartTime:  time.Now(),
-		LastUpdate: time.Now(),
-	}
+	i.resetProgress(0)
 
 	reader := stripBOM(r)
 	return i.importCharitiesFromReader(reader)
 }
 
-// importCharitiesFromReader is the internal implementation that works with any reader
-func (i *Importer) importCharitiesFromReader(reader io.Reader) error {
-	decoder := json.NewDecoder(reader)
-
-	// Read opening bracket
-	token, err := decoder.Token()
-	if err != nil {
-		return fmt.Errorf("failed to read opening bracket: %w", err)
-	}
-	if delim, ok := token.(json.Delim); !ok || delim != '[' {
-		return fmt.Errorf("expected array opening bracket, got: %v", token)
-	}
-
+// importCharitiesFromReader is the internal implementation that works with any reader.
+// Several readers are imported in order as one dataset, sharing the batch,
+// progress and record limit.
+func (i *Importer) importCharitiesFromReader(readers ...io.Reader) error {
 	batch := make([]CharityRecord, 0, i.config.BatchSize)
 	recordNum := 0
+	var readErr error
 
-	// Process array elements
-	for decoder.More() {
-		var record CharityRecord
-		if err := decoder.Decode(&record); err != nil {
-			log.Printf("Failed to decode record %d: %v", recordNum, err)
-			i.progress.FailedRecords++
-			continue
+chunks:
+	for n, reader := range readers {
+		if len(readers) > 1 {
+			log.Printf("Reading file %d of %d", n+1, len(readers))
+		}
+		decoder := json.NewDecoder(reader)
+
+		// Stop at an empty or non-array file, keeping the records already read
+		if err := openArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
 		}
 
-		batch = append(batch, record)
-		recordNum++
-		i.progress.TotalRecords = recordNum
+		// Process array elements
+		for decoder.More() {
+			if i.reachedLimit(recordNum) {
+				break chunks
+			}
+
+			var record CharityRecord
+			if err := decoder.Decode(&record); err != nil {
+				if !recordError(err) {
+					readErr = inputError(n, len(readers), fmt.Errorf("malformed JSON after record %d: %w", recordNum, err))
+					break chunks
+				}
+				log.Printf("Failed to decode record %d: %v", recordNum, err)
+				i.addProgress(ImportProgress{FailedRecords: 1})
+				continue
+			}
+
+			batch = append(batch, record)
+			i.noteExtractDate(record.DateOfExtract)
+			recordNum++
+			i.setTotalRecords(recordNum)
+
+			// Process batch when full
+			if len(batch) >= i.config.BatchSize {
+				if err := i.insertWithRetry(func() error { return i.insertCharityBatch(batch) }); err != nil {
+					log.Printf("Failed to insert batch: %v", err)
+				}
+				batch = batch[:0] // Reset batch
+			}
 
-		// Process batch when full
-		if len(batch) >= i.config.BatchSize {
-			if err := i.insertCharityBatch(batch); err != nil {
-				log.Printf("Failed to insert batch: %v", err)
+			// Log progress
+			if recordNum%i.config.ProgressInterval == 0 {
+				i.logProgress()
 			}
-			batch = batch[:0] // Reset batch
 		}
 
-		// Log progress
-		if recordNum%i.config.ProgressInterval == 0 {
-			i.logProgress()
+		// More also stops at the end of a file missing its closing bracket
+		if err := closeArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
 		}
 	}
 
 	// Process remaining records
 	if len(batch) > 0 {
-		if err := i.insertCharityBatch(batch); err != nil {
+		if err := i.insertWithRetry(func() error { return i.insertCharityBatch(batch) }); err != nil {
 			log.Printf("Failed to insert final batch: %v", err)
 		}
 	}
 
+	i.recordExtractDate("charity")
 	i.logFinalStats("Charity import")
-	return nil
+	if duplicates := i.DuplicateCharities(); duplicates > 0 {
+		log.Printf("Skipped %d duplicate charity numbers listed under different names, recorded in data_anomalies for review", duplicates)
+	}
+	return readErr
 }
 
-// ImportTrustees imports trustees from a JSON file
+// ImportTrustees imports trustees from a JSON file or set of chunk files
 func (i *Importer) ImportTrustees() error {
 	log.Printf("Starting trustee import from: %s", i.config.TrusteeFile)
-	i.progress = ImportProgress{
-		StartTime:  time.Now(),
-		LastUpdate: time.Now(),
-	}
+	i.resetProgress(0)
 
-	file, err := os.Open(i.config.TrusteeFile)
+	readers, closeFiles, err := openInputs(i.config.TrusteeFile)
 	if err != nil {
 		return fmt.Errorf("failed to open trustee file: %w", err)
 	}
-	defer file.Close()
+	defer closeFiles()
 
-	// Strip BOM if present
-	reader := stripBOM(file)
-	return i.importTrusteesFromReader(reader)
+	return i.importTrusteesFromReader(readers...)
 }
 
 // ImportTrusteesFromReader imports trustees from an io.Reader (for in-memory data)
 func (i *Importer) ImportTrusteesFromReader(r io.Reader) error {
 	log.Println("Starting trustee import from in-memory data")
-	i.progress = ImportProgress{
-		StartTime:  time.Now(),
-		LastUpdate: time.Now(),
-	}
+	i.resetProgress(0)
 
 	reader := stripBOM(r)
 	return i.importTrusteesFromReader(reader)
 }
 
-// importTrusteesFromReader is the internal implementation that works with any reader
-func (i *Importer) importTrusteesFromReader(reader io.Reader) error {
-	decoder := json.NewDecoder(reader)
-
-	// Read opening bracket
-	token, err := decoder.Token()
-	if err != nil {
-		return fmt.Errorf("failed to read opening bracket: %w", err)
-	}
-	if delim, ok := token.(json.Delim); !ok || delim != '[' {
-		return fmt.Errorf("expected array opening bracket, got: %v", token)
-	}
-
+// importTrusteesFromReader is the internal implementation that works with any reader.
+// Several readers are imported in order as one dataset, sharing the batch,
+// progress and record limit.
+func (i *Importer) importTrusteesFromReader(readers ...io.Reader) error {
 	batch := make([]TrusteeRecord, 0, i.config.BatchSize)
 	recordNum := 0
+	var readErr error
 
-	// Process array elements
-	for decoder.More() {
-		var record TrusteeRecord
-		if err := decoder.Decode(&record); err != nil {
-			log.Printf("Failed to decode trustee record %d: %v", recordNum, err)
-			i.progress.FailedRecords++
-			continue
+chunks:
+	for n, reader := range readers {
+		if len(readers) > 1 {
+			log.Printf("Reading file %d of %d", n+1, len(readers))
+		}
+		decoder := json.NewDecoder(reader)
+
+		// Stop at an empty or non-array file, keeping the records already read
+		if err := openArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
 		}
 
-		batch = append(batch, record)
-		recordNum++
-		i.progress.TotalRecords = recordNum
+		// Process array elements
+		for decoder.More() {
+			if i.reachedLimit(recordNum) {
+				break chunks
+			}
+
+			var record TrusteeRecord
+			if err := decoder.Decode(&record); err != nil {
+				if !recordError(err) {
+					readErr = inputError(n, len(readers), fmt.Errorf("malformed JSON after record %d: %w", recordNum, err))
+					break chunks
+				}
+				log.Printf("Failed to decode trustee record %d: %v", recordNum, err)
+				i.addProgress(ImportProgress{FailedRecords: 1})
+				continue
+			}
+
+			batch = append(batch, record)
+			i.noteExtractDate(record.DateOfExtract)
+			recordNum++
+			i.setTotalRecords(recordNum)
+
+			// Process batch when full
+			if len(batch) >= i.config.BatchSize {
+				if err := i.insertWithRetry(func() error { return i.insertTrusteeBatch(batch) }); err != nil {
+					log.Printf("Failed to insert trustee batch: %v", err)
+				}
+				batch = batch[:0] // Reset batch
+			}
 
-		// Process batch when full
-		if len(batch) >= i.config.BatchSize {
-			if err := i.insertTrusteeBatch(batch); err != nil {
-				log.Printf("Failed to insert trustee batch: %v", err)
+			// Log progress
+			if recordNum%i.config.ProgressInterval == 0 {
+				i.logProgress()
 			}
-			batch = batch[:0] // Reset batch
 		}
 
-		// Log progress
-		if recordNum%i.config.ProgressInterval == 0 {
-			i.logProgress()
+		// More also stops at the end of a file missing its closing bracket
+		if err := closeArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
 		}
 	}
 
 	// Process remaining records
 	if len(batch) > 0 {
-		if err := i.insertTrusteeBatch(batch); err != nil {
+		if err := i.insertWithRetry(func() error { return i.insertTrusteeBatch(batch) }); err != nil {
 			log.Printf("Failed to insert final trustee batch: %v", err)
 		}
 	}
 
+	i.recordExtractDate("charity_trustee")
 	i.logFinalStats("Trustee import")
-	return nil
+	counts := i.TrusteeCounts()
+	log.Printf("Trustees: %d new, %d unchanged", counts.New, counts.Unchanged)
+	return readErr
 }
 
 // ImportFinancials imports financial data from annual return partb JSON file
@@ -336,92 +470,124 @@ func (i *Importer) ImportFinancials() error {
 	}
 
 	log.Printf("Starting financial data import from: %s", i.config.FinancialFile)
-	i.progress = ImportProgress{
-		StartTime:  time.Now(),
-		LastUpdate: time.Now(),
-	}
+	i.resetProgress(0)
 
-	file, err := os.Open(i.config.FinancialFile)
+	readers, closeFiles, err := openInputs(i.config.FinancialFile)
 	if err != nil {
 		return fmt.Errorf("failed to open financial file: %w", err)
 	}
-	defer file.Close()
+	defer closeFiles()
 
-	// Strip BOM if present
-	reader := stripBOM(file)
-	return i.importFinancialsFromReader(reader)
+	return i.importFinancialsFromReader(readers...)
 }
 
 // ImportFinancialsFromReader imports financial data from an io.Reader (for in-memory data)
 func (i *Importer) ImportFinancialsFromReader(r io.Reader) error {
 	log.Println("Starting financial data import from in-memory data")
-	i.progress = ImportProgress{
-		StartTime:  time.Now(),
-		LastUpdate: time.Now(),
-	}
+	i.resetProgress(0)
 
 	reader := stripBOM(r)
 	return i.importFinancialsFromReader(reader)
 }
 
-// importFinancialsFromReader is the internal implementation that works with any reader
-func (i *Importer) importFinancialsFromReader(reader io.Reader) error {
-	decoder := json.NewDecoder(reader)
-
-	// Read opening bracket
-	token, err := decoder.Token()
-	if err != nil {
-		return fmt.Errorf("failed to read opening bracket: %w", err)
-	}
-	if delim, ok := token.(json.Delim); !ok || delim != '[' {
-		return fmt.Errorf("expected array opening bracket, got: %v", token)
-	}
-
+// importFinancialsFromReader is the internal implementation that works with any reader.
+// Several readers are imported in order as one dataset, sharing the batch,
+// progress and record limit.
+func (i *Importer) importFinancialsFromReader(readers ...io.Reader) error {
 	batch := make([]AnnualReturnPartBRecord, 0, i.config.BatchSize)
 	recordNum := 0
+	var readErr error
 
-	// Process array elements
-	for decoder.More() {
-		var record AnnualReturnPartBRecord
-		if err := decoder.Decode(&record); err != nil {
-			log.Printf("Failed to decode financial record %d: %v", recordNum, err)
-			i.progress.FailedRecords++
-			continue
+	if i.config.ScoreDuringImport {
+		stop := i.startScoreWorker()
+		defer stop()
+	}
+
+chunks:
+	for n, reader := range readers {
+		if len(readers) > 1 {
+			log.Printf("Reading file %d of %d", n+1, len(readers))
 		}
+		decoder := json.NewDecoder(reader)
 
-		// Only process latest period for each charity to avoid duplicates
-		if record.LatestFinPeriodSubmittedInd {
-			batch = append(batch, record)
-		} else {
-			i.progress.SkippedRecords++
+		// Stop at an empty or non-array file, keeping the records already read
+		if err := openArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
 		}
 
-		recordNum++
-		i.progress.TotalRecords = recordNum
+		// Process array elements
+		for decoder.More() {
+			if i.reachedLimit(recordNum) {
+				break chunks
+			}
 
-		// Process batch when full
-		if len(batch) >= i.config.BatchSize {
-			if err := i.insertFinancialBatch(batch); err != nil {
-				log.Printf("Failed to insert financial batch: %v", err)
+			var record AnnualReturnPartBRecord
+			if err := decoder.Decode(&record); err != nil {
+				if !recordError(err) {
+					readErr = inputError(n, len(readers), fmt.Errorf("malformed JSON after record %d: %w", recordNum, err))
+					break chunks
+				}
+				log.Printf("Failed to decode financial record %d: %v", recordNum, err)
+				i.addProgress(ImportProgress{FailedRecords: 1})
+				continue
+			}
+
+			i.noteExtractDate(record.DateOfExtract)
+
+			// Only process the latest period for each charity, unless asked for all.
+			// Earlier periods are distinct years, so they don't overwrite the latest.
+			if record.LatestFinPeriodSubmittedInd || i.config.ImportAllPeriods {
+				batch = append(batch, record)
+			} else {
+				i.addProgress(ImportProgress{SkippedRecords: 1})
+			}
+
+			recordNum++
+			i.setTotalRecords(recordNum)
+
+			// Process batch when full
+			if len(batch) >= i.config.BatchSize {
+				if err := i.insertWithRetry(func() error { return i.insertFinancialBatch(batch) }); err != nil {
+					log.Printf("Failed to insert financial batch: %v", err)
+				}
+				batch = batch[:0] // Reset batch
+			}
+
+			// Log progress
+			if recordNum%i.config.ProgressInterval == 0 {
+				i.logProgress()
 			}
-			batch = batch[:0] // Reset batch
 		}
 
-		// Log progress
-		if recordNum%i.config.ProgressInterval == 0 {
-			i.logProgress()
+		// More also stops at the end of a file missing its closing bracket
+		if err := closeArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
 		}
 	}
 
 	// Process remaining records
 	if len(batch) > 0 {
-		if err := i.insertFinancialBatch(batch); err != nil {
+		if err := i.insertWithRetry(func() error { return i.insertFinancialBatch(batch) }); err != nil {
 			log.Printf("Failed to insert final financial batch: %v", err)
 		}
 	}
 
+	i.recordExtractDate("charity_annual_return_partb")
 	i.logFinalStats("Financial data import")
-	return nil
+	return readErr
+}
+
+// insertWithRetry runs a batch insert, retrying the whole batch while the database
+// is busy. Progress counters are restored before each attempt so records in a
+// rolled-back batch aren't counted twice.
+func (i *Importer) insertWithRetry(insert func() error) error {
+	saved := i.GetProgress()
+	return database.RetryBusy(func() error {
+		i.setProgress(saved)
+		return insert()
+	})
 }
 
 // insertCharityBatch inserts a batch of charity records
@@ -435,20 +601,50 @@ func (i *Importer) insertCharityBatch(records []CharityRecord) error {
 	stmt, err := tx.Prepare(`
 		INSERT OR REPLACE INTO charities
 		(organisation_number, registered_number, linked_charity_number, company_number, 
-		 name, status, date_registered, date_removed, 
-		 address, website, email, phone, what_the_charity_does, last_updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 name, name_folded, status, date_registered, date_removed, 
+		 address, website, email, phone, what_the_charity_does, last_updated,
+		 data_extract_date, details_source, financials_source, trustees_source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+		        (SELECT financials_source FROM charities WHERE organisation_number = ?),
+		        (SELECT trustees_source FROM charities WHERE organisation_number = ?))
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
+	financialsStored := make(map[int]bool)
+	duplicates := 0
 	for _, record := range records {
 		// Only import active or registered charities (optional filter)
 		// Skip if already registered charity number is 0 (invalid)
 		if record.RegisteredCharityNumber == 0 {
-			i.progress.SkippedRecords++
+			i.addProgress(ImportProgress{SkippedRecords: 1})
+			continue
+		}
+
+		// Skip charities that don't match the import filters, if any. Excluding a
+		// main charity also skips its records in the datasets imported later, and
+		// its linked charities decoded after it.
+		if i.filtering() {
+			matches := i.matchesFilters(record)
+			if !matches && record.LinkedCharityNumber == 0 {
+				i.excludeCharity(record.RegisteredCharityNumber)
+			}
+			if !matches || i.isExcluded(record.RegisteredCharityNumber) {
+				i.addProgress(ImportProgress{SkippedRecords: 1})
+				continue
+			}
+		}
+
+		// Keep the first of two main entries with the same number but different names
+		duplicate, err := i.checkDuplicate(tx, record)
+		if err != nil {
+			return err
+		}
+		if duplicate {
+			duplicates++
+			i.addProgress(ImportProgress{SkippedRecords: 1})
 			continue
 		}
 
@@ -463,56 +659,80 @@ func (i *Importer) insertCharityBatch(records []CharityRecord) error {
 		)
 
 		// Parse dates
-		dateRegistered := parseDate(record.DateOfRegistration)
+		dateRegistered := dates.Parse(record.DateOfRegistration)
 		var dateRemoved *time.Time
 		if record.DateOfRemoval != nil {
-			dr := parseDate(*record.DateOfRemoval)
+			dr := dates.Parse(*record.DateOfRemoval)
 			dateRemoved = &dr
 		}
+		var extractDate *time.Time
+		if ed := dates.Parse(record.DateOfExtract); !ed.IsZero() {
+			extractDate = &ed
+		}
 
 		// Execute insert
-		_, err := stmt.Exec(
+		_, err = stmt.Exec(
 			record.OrganisationNumber,
 			record.RegisteredCharityNumber,
 			record.LinkedCharityNumber,
 			record.CharityCompanyRegistrationNumber,
 			record.CharityName,
+			database.FoldName(record.CharityName),
 			record.CharityRegistrationStatus,
 			dateRegistered,
 			dateRemoved,
 			address,
 			record.CharityContactWeb,
 			record.CharityContactEmail,
-			record.CharityContactPhone,
+			normalizePhone(record.CharityContactPhone),
 			record.CharityActivities,
 			time.Now(),
+			extractDate,
+			models.SourceRegisterExtract,
+			// Replacing the row keeps the sources of the sections imported separately
+			record.OrganisationNumber,
+			record.OrganisationNumber,
 		)
+		if database.IsBusy(err) {
+			// Abandon the batch so insertWithRetry can run it again
+			return err
+		}
 		if err != nil {
 			if i.config.Verbose {
 				log.Printf("Failed to insert charity %d: %v", record.RegisteredCharityNumber, err)
 			}
-			i.progress.FailedRecords++
+			i.addProgress(ImportProgress{FailedRecords: 1})
 			continue
 		}
 
-		i.progress.SuccessRecords++
+		i.addProgress(ImportProgress{SuccessRecords: 1})
 
 		// Also insert financial data if available
-		if record.LatestIncome != nil && record.LatestExpenditure != nil {
-			i.insertFinancialData(tx, record)
+		if record.LatestIncome != nil && record.LatestExpenditure != nil && i.insertFinancialData(tx, record) {
+			financialsStored[record.RegisteredCharityNumber] = true
 		}
 	}
 
-	i.progress.ProcessedRecords += len(records)
+	if err := i.markDataSources(tx, database.FinancialsSection, models.SourceRegisterExtract, financialsStored); err != nil {
+		return err
+	}
+
+	i.addProgress(ImportProgress{ProcessedRecords: len(records)})
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	// Counted once committed, as a batch abandoned to a busy database runs again
+	i.progressMu.Lock()
+	i.duplicates += duplicates
+	i.progressMu.Unlock()
+
 	return nil
 }
 
-// insertTrusteeBatch inserts a batch of trustee records
+// insertTrusteeBatch inserts a batch of trustee records, leaving trustees that
+// are already stored untouched
 func (i *Importer) insertTrusteeBatch(records []TrusteeRecord) error {
 	tx, err := i.db.Begin()
 	if err != nil {
@@ -520,48 +740,82 @@ func (i *Importer) insertTrusteeBatch(records []TrusteeRecord) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO trustees
-		(charity_number, name, last_updated)
-		VALUES (?, ?, ?)
-	`)
+	stmt, err := tx.Prepare(database.InsertTrusteeSQL)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
+	stored := make(map[int]bool)
+	var counts TrusteeCounts
 	for _, record := range records {
 		// Skip invalid records
-		if record.RegisteredCharityNumber == 0 || record.TrusteeName == "" {
-			i.progress.SkippedRecords++
+		if record.RegisteredCharityNumber == 0 || record.TrusteeName == "" || i.isExcluded(record.RegisteredCharityNumber) {
+			i.addProgress(ImportProgress{SkippedRecords: 1})
 			continue
 		}
 
-		_, err := stmt.Exec(
+		result, err := stmt.Exec(
 			record.RegisteredCharityNumber,
 			record.TrusteeName,
+			database.TrusteeNameKey(record.TrusteeName),
 			time.Now(),
 		)
+		if database.IsBusy(err) {
+			// Abandon the batch so insertWithRetry can run it again
+			return err
+		}
 		if err != nil {
 			if i.config.Verbose {
 				log.Printf("Failed to insert trustee for charity %d: %v", record.RegisteredCharityNumber, err)
 			}
-			i.progress.FailedRecords++
+			i.addProgress(ImportProgress{FailedRecords: 1})
 			continue
 		}
 
-		i.progress.SuccessRecords++
+		if added, err := result.RowsAffected(); err == nil && added == 0 {
+			counts.Unchanged++
+		} else {
+			counts.New++
+		}
+		i.addProgress(ImportProgress{SuccessRecords: 1})
+		stored[record.RegisteredCharityNumber] = true
+	}
+
+	if err := i.markDataSources(tx, database.TrusteesSection, models.SourceRegisterExtract, stored); err != nil {
+		return err
 	}
 
-	i.progress.ProcessedRecords += len(records)
+	i.addProgress(ImportProgress{ProcessedRecords: len(records)})
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	// Counted once committed, as a batch abandoned to a busy database runs again
+	i.progressMu.Lock()
+	i.trustees.New += counts.New
+	i.trustees.Unchanged += counts.Unchanged
+	i.progressMu.Unlock()
+
 	return nil
 }
 
+// TrusteeCounts splits the trustees imported into those that were new and those
+// already stored, which a re-import leaves untouched
+type TrusteeCounts struct {
+	New       int `json:"new"`
+	Unchanged int `json:"unchanged"`
+}
+
+// TrusteeCounts returns how many of the trustees imported were new and how many
+// were already stored
+func (i *Importer) TrusteeCounts() TrusteeCounts {
+	i.progressMu.Lock()
+	defer i.progressMu.Unlock()
+	return i.trustees
+}
+
 // insertFinancialBatch inserts a batch of financial records from annual return partb
 func (i *Importer) insertFinancialBatch(records []AnnualReturnPartBRecord) error {
 	tx, err := i.db.Begin()
@@ -570,29 +824,34 @@ func (i *Importer) insertFinancialBatch(records []AnnualReturnPartBRecord) error
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO financials
-		(charity_number, financial_year_end, total_income, total_spending,
-		 charitable_activities_spend, raising_funds_spend, other_spend,
-		 reserves, assets, employees, last_updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`)
+	// Merge rather than replace so Part B doesn't zero out figures from other sources
+	stmt, err := tx.Prepare(database.UpsertFinancialSQL)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
+	var imported []int
+	stored := make(map[int]bool)
 	for _, record := range records {
-		// Skip invalid records
-		if record.RegisteredCharityNumber == 0 {
-			i.progress.SkippedRecords++
+		// Skip invalid records, and charities filtered out of the import
+		if record.RegisteredCharityNumber == 0 || i.isExcluded(record.RegisteredCharityNumber) {
+			i.addProgress(ImportProgress{SkippedRecords: 1})
 			continue
 		}
 
 		// Parse financial year end date
-		yearEnd := parseDate(record.FinPeriodEndDate)
+		yearEnd := dates.Parse(record.FinPeriodEndDate)
 		if yearEnd.IsZero() {
-			i.progress.SkippedRecords++
+			i.addProgress(ImportProgress{SkippedRecords: 1})
+			continue
+		}
+
+		// Skip implausible figures rather than let them skew the score
+		income := orDefaultPtr(record.IncomeTotalIncomeEndowments, 0)
+		expenditure := orDefaultPtr(record.ExpenditureTotal, 0)
+		if !i.checkFinancials(record.RegisteredCharityNumber, yearEnd, income, expenditure) {
+			i.addProgress(ImportProgress{SkippedRecords: 1})
 			continue
 		}
 
@@ -605,33 +864,54 @@ func (i *Importer) insertFinancialBatch(records []AnnualReturnPartBRecord) error
 		_, err := stmt.Exec(
 			record.RegisteredCharityNumber,
 			yearEnd,
-			orDefaultPtr(record.IncomeTotalIncomeEndowments, 0),
-			orDefaultPtr(record.ExpenditureTotal, 0),
+			income,
+			expenditure,
 			orDefaultPtr(record.ExpenditureCharitableExpend, 0),
 			orDefaultPtr(record.ExpenditureRaisingFunds, 0),
 			otherSpend,
 			orDefaultPtr(record.Reserves, 0),
 			orDefaultPtr(record.AssetsTotalAssetsLiabilities, 0),
 			orDefaultPtrInt(record.CountEmployees, 0),
+			0, // Trustee count is not in Part B
 			time.Now(),
 		)
+		if database.IsBusy(err) {
+			// Abandon the batch so insertWithRetry can run it again
+			return err
+		}
 		if err != nil {
 			if i.config.Verbose {
 				log.Printf("Failed to insert financial data for charity %d: %v", record.RegisteredCharityNumber, err)
 			}
-			i.progress.FailedRecords++
+			i.addProgress(ImportProgress{FailedRecords: 1})
 			continue
 		}
 
-		i.progress.SuccessRecords++
+		// Score once per charity, for the period its score is calculated from
+		i.addProgress(ImportProgress{SuccessRecords: 1})
+		if record.LatestFinPeriodSubmittedInd {
+			imported = append(imported, record.RegisteredCharityNumber)
+		}
+		stored[record.RegisteredCharityNumber] = true
+	}
+
+	if err := i.markDataSources(tx, database.FinancialsSection, models.SourceAnnualReturn, stored); err != nil {
+		return err
 	}
 
-	i.progress.ProcessedRecords += len(records)
+	i.addProgress(ImportProgress{ProcessedRecords: len(records)})
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	// Score only once the financials are committed and visible to the score worker
+	if i.scoreQueue != nil {
+		for _, charityNum := range imported {
+			i.scoreQueue <- charityNum
+		}
+	}
+
 	return nil
 }
 
@@ -643,85 +923,100 @@ func (i *Importer) ImportAnnualReturnHistory() error {
 	}
 
 	log.Printf("Starting annual return history import from: %s", i.config.AnnualReturnHistoryFile)
-	i.progress = ImportProgress{
-		StartTime:  time.Now(),
-		LastUpdate: time.Now(),
-	}
+	i.resetProgress(0)
 
-	file, err := os.Open(i.config.AnnualReturnHistoryFile)
+	readers, closeFiles, err := openInputs(i.config.AnnualReturnHistoryFile)
 	if err != nil {
 		return fmt.Errorf("failed to open annual return history file: %w", err)
 	}
-	defer file.Close()
+	defer closeFiles()
 
-	reader := stripBOM(file)
-	return i.importAnnualReturnHistoryFromReader(reader)
+	return i.importAnnualReturnHistoryFromReader(readers...)
 }
 
 // ImportAnnualReturnHistoryFromReader imports annual return history data from an io.Reader
 func (i *Importer) ImportAnnualReturnHistoryFromReader(r io.Reader) error {
 	log.Println("Starting annual return history import from in-memory data")
-	i.progress = ImportProgress{
-		StartTime:  time.Now(),
-		LastUpdate: time.Now(),
-	}
+	i.resetProgress(0)
 
 	reader := stripBOM(r)
 	return i.importAnnualReturnHistoryFromReader(reader)
 }
 
-// importAnnualReturnHistoryFromReader is the internal implementation that works with any reader
-func (i *Importer) importAnnualReturnHistoryFromReader(reader io.Reader) error {
-	decoder := json.NewDecoder(reader)
-
-	// Read opening bracket
-	token, err := decoder.Token()
-	if err != nil {
-		return fmt.Errorf("failed to read opening bracket: %w", err)
-	}
-	if delim, ok := token.(json.Delim); !ok || delim != '[' {
-		return fmt.Errorf("expected array opening bracket, got: %v", token)
-	}
-
+// importAnnualReturnHistoryFromReader is the internal implementation that works with any reader.
+// Several readers are imported in order as one dataset, sharing the batch,
+// progress and record limit.
+func (i *Importer) importAnnualReturnHistoryFromReader(readers ...io.Reader) error {
 	batch := make([]AnnualReturnHistoryRecord, 0, i.config.BatchSize)
 	recordNum := 0
+	var readErr error
 
-	// Process array elements
-	for decoder.More() {
-		var record AnnualReturnHistoryRecord
-		if err := decoder.Decode(&record); err != nil {
-			log.Printf("Failed to decode annual return history record %d: %v", recordNum, err)
-			i.progress.FailedRecords++
-			continue
+chunks:
+	for n, reader := range readers {
+		if len(readers) > 1 {
+			log.Printf("Reading file %d of %d", n+1, len(readers))
 		}
+		decoder := json.NewDecoder(reader)
 
-		batch = append(batch, record)
-		recordNum++
-		i.progress.TotalRecords = recordNum
+		// Stop at an empty or non-array file, keeping the records already read
+		if err := openArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
+		}
 
-		// Process batch when full
-		if len(batch) >= i.config.BatchSize {
-			if err := i.insertAnnualReturnHistoryBatch(batch); err != nil {
-				log.Printf("Failed to insert annual return history batch: %v", err)
+		// Process array elements
+		for decoder.More() {
+			if i.reachedLimit(recordNum) {
+				break chunks
+			}
+
+			var record AnnualReturnHistoryRecord
+			if err := decoder.Decode(&record); err != nil {
+				if !recordError(err) {
+					readErr = inputError(n, len(readers), fmt.Errorf("malformed JSON after record %d: %w", recordNum, err))
+					break chunks
+				}
+				log.Printf("Failed to decode annual return history record %d: %v", recordNum, err)
+				i.addProgress(ImportProgress{FailedRecords: 1})
+				continue
+			}
+
+			batch = append(batch, record)
+			i.noteExtractDate(record.DateOfExtract)
+			recordNum++
+			i.setTotalRecords(recordNum)
+
+			// Process batch when full
+			if len(batch) >= i.config.BatchSize {
+				if err := i.insertWithRetry(func() error { return i.insertAnnualReturnHistoryBatch(batch) }); err != nil {
+					log.Printf("Failed to insert annual return history batch: %v", err)
+				}
+				batch = batch[:0] // Reset batch
+			}
+
+			// Log progress
+			if recordNum%i.config.ProgressInterval == 0 {
+				i.logProgress()
 			}
-			batch = batch[:0] // Reset batch
 		}
 
-		// Log progress
-		if recordNum%i.config.ProgressInterval == 0 {
-			i.logProgress()
+		// More also stops at the end of a file missing its closing bracket
+		if err := closeArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
 		}
 	}
 
 	// Process remaining records
 	if len(batch) > 0 {
-		if err := i.insertAnnualReturnHistoryBatch(batch); err != nil {
+		if err := i.insertWithRetry(func() error { return i.insertAnnualReturnHistoryBatch(batch) }); err != nil {
 			log.Printf("Failed to insert final annual return history batch: %v", err)
 		}
 	}
 
+	i.recordExtractDate("charity_annual_return_history")
 	i.logFinalStats("Annual return history import")
-	return nil
+	return readErr
 }
 
 // insertAnnualReturnHistoryBatch inserts a batch of annual return history records
@@ -747,24 +1042,29 @@ func (i *Importer) insertAnnualReturnHistoryBatch(records []AnnualReturnHistoryR
 	defer stmt.Close()
 
 	for _, record := range records {
+		if i.isExcluded(record.RegisteredCharityNumber) {
+			i.addProgress(ImportProgress{SkippedRecords: 1})
+			continue
+		}
+
 		var finStartDate, finEndDate, dueDate, arReceivedDate, accountsReceivedDate, extractDate interface{}
 
 		if record.FinPeriodStartDate != nil {
-			finStartDate = parseDate(*record.FinPeriodStartDate)
+			finStartDate = dates.Parse(*record.FinPeriodStartDate)
 		}
 		if record.FinPeriodEndDate != nil {
-			finEndDate = parseDate(*record.FinPeriodEndDate)
+			finEndDate = dates.Parse(*record.FinPeriodEndDate)
 		}
 		if record.ReportingDueDate != nil {
-			dueDate = parseDate(*record.ReportingDueDate)
+			dueDate = dates.Parse(*record.ReportingDueDate)
 		}
 		if record.DateAnnualReturnReceived != nil {
-			arReceivedDate = parseDate(*record.DateAnnualReturnReceived)
+			arReceivedDate = dates.Parse(*record.DateAnnualReturnReceived)
 		}
 		if record.DateAccountsReceived != nil {
-			accountsReceivedDate = parseDate(*record.DateAccountsReceived)
+			accountsReceivedDate = dates.Parse(*record.DateAccountsReceived)
 		}
-		extractDate = parseDate(record.DateOfExtract)
+		extractDate = dates.Parse(record.DateOfExtract)
 
 		_, err := stmt.Exec(
 			record.OrganisationNumber,
@@ -783,19 +1083,23 @@ func (i *Importer) insertAnnualReturnHistoryBatch(records []AnnualReturnHistoryR
 			extractDate,
 		)
 
+		if database.IsBusy(err) {
+			// Abandon the batch so insertWithRetry can run it again
+			return err
+		}
 		if err != nil {
 			if i.config.Verbose {
 				log.Printf("Failed to insert annual return history for charity %d: %v",
 					record.RegisteredCharityNumber, err)
 			}
-			i.progress.FailedRecords++
+			i.addProgress(ImportProgress{FailedRecords: 1})
 			continue
 		}
 
-		i.progress.SuccessRecords++
+		i.addProgress(ImportProgress{SuccessRecords: 1})
 	}
 
-	i.progress.ProcessedRecords += len(records)
+	i.addProgress(ImportProgress{ProcessedRecords: len(records)})
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -804,21 +1108,189 @@ func (i *Importer) insertAnnualReturnHistoryBatch(records []AnnualReturnHistoryR
 	return nil
 }
 
-// insertFinancialData inserts financial data for a charity
-func (i *Importer) insertFinancialData(tx *sql.Tx, record CharityRecord) {
+// ImportClassifications imports charity classification data from a file
+func (i *Importer) ImportClassifications() error {
+	if i.config.ClassificationFile == "" {
+		log.Println("No classification file specified, skipping")
+		return nil
+	}
+
+	log.Printf("Starting classification import from: %s", i.config.ClassificationFile)
+	i.resetProgress(0)
+
+	readers, closeFiles, err := openInputs(i.config.ClassificationFile)
+	if err != nil {
+		return fmt.Errorf("failed to open classification file: %w", err)
+	}
+	defer closeFiles()
+
+	return i.importClassificationsFromReader(readers...)
+}
+
+// ImportClassificationsFromReader imports charity classification data from an io.Reader
+func (i *Importer) ImportClassificationsFromReader(r io.Reader) error {
+	log.Println("Starting classification import from in-memory data")
+	i.resetProgress(0)
+
+	reader := stripBOM(r)
+	return i.importClassificationsFromReader(reader)
+}
+
+// importClassificationsFromReader is the internal implementation that works with any reader.
+// Several readers are imported in order as one dataset, sharing the batch,
+// progress and record limit.
+func (i *Importer) importClassificationsFromReader(readers ...io.Reader) error {
+	batch := make([]ClassificationRecord, 0, i.config.BatchSize)
+	recordNum := 0
+	var readErr error
+
+chunks:
+	for n, reader := range readers {
+		if len(readers) > 1 {
+			log.Printf("Reading file %d of %d", n+1, len(readers))
+		}
+		decoder := json.NewDecoder(reader)
+
+		// Stop at an empty or non-array file, keeping the records already read
+		if err := openArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
+		}
+
+		// Process array elements
+		for decoder.More() {
+			if i.reachedLimit(recordNum) {
+				break chunks
+			}
+
+			var record ClassificationRecord
+			if err := decoder.Decode(&record); err != nil {
+				if !recordError(err) {
+					readErr = inputError(n, len(readers), fmt.Errorf("malformed JSON after record %d: %w", recordNum, err))
+					break chunks
+				}
+				log.Printf("Failed to decode classification record %d: %v", recordNum, err)
+				i.addProgress(ImportProgress{FailedRecords: 1})
+				continue
+			}
+
+			batch = append(batch, record)
+			i.noteExtractDate(record.DateOfExtract)
+			recordNum++
+			i.setTotalRecords(recordNum)
+
+			// Process batch when full
+			if len(batch) >= i.config.BatchSize {
+				if err := i.insertWithRetry(func() error { return i.insertClassificationBatch(batch) }); err != nil {
+					log.Printf("Failed to insert classification batch: %v", err)
+				}
+				batch = batch[:0] // Reset batch
+			}
+
+			// Log progress
+			if recordNum%i.config.ProgressInterval == 0 {
+				i.logProgress()
+			}
+		}
+
+		// More also stops at the end of a file missing its closing bracket
+		if err := closeArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
+		}
+	}
+
+	// Process remaining records
+	if len(batch) > 0 {
+		if err := i.insertWithRetry(func() error { return i.insertClassificationBatch(batch) }); err != nil {
+			log.Printf("Failed to insert final classification batch: %v", err)
+		}
+	}
+
+	i.recordExtractDate("charity_classification")
+	i.logFinalStats("Classification import")
+	return readErr
+}
+
+// insertClassificationBatch inserts a batch of classification records
+func (i *Importer) insertClassificationBatch(records []ClassificationRecord) error {
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO charity_classifications
+		(organisation_number, registered_charity_number, linked_charity_number,
+		 classification_code, classification_type, classification_description, last_updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		// Skip invalid records
+		if record.RegisteredCharityNumber == 0 || record.ClassificationCode == "" || i.isExcluded(record.RegisteredCharityNumber) {
+			i.addProgress(ImportProgress{SkippedRecords: 1})
+			continue
+		}
+
+		_, err := stmt.Exec(
+			record.OrganisationNumber,
+			record.RegisteredCharityNumber,
+			record.LinkedCharityNumber,
+			record.ClassificationCode,
+			record.ClassificationType,
+			record.ClassificationDescription,
+			time.Now(),
+		)
+		if database.IsBusy(err) {
+			// Abandon the batch so insertWithRetry can run it again
+			return err
+		}
+		if err != nil {
+			if i.config.Verbose {
+				log.Printf("Failed to insert classification for charity %d: %v", record.RegisteredCharityNumber, err)
+			}
+			i.addProgress(ImportProgress{FailedRecords: 1})
+			continue
+		}
+
+		i.addProgress(ImportProgress{SuccessRecords: 1})
+	}
+
+	i.addProgress(ImportProgress{ProcessedRecords: len(records)})
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertFinancialData inserts financial data for a charity, reporting whether it
+// was stored
+func (i *Importer) insertFinancialData(tx *sql.Tx, record CharityRecord) bool {
 	if record.LatestAccFinPeriodEndDate == nil {
-		return
+		return false
 	}
 
-	yearEnd := parseDate(*record.LatestAccFinPeriodEndDate)
+	yearEnd := dates.Parse(*record.LatestAccFinPeriodEndDate)
+	if yearEnd.IsZero() {
+		// An unparseable year end would sort below every real filing
+		return false
+	}
+
+	// The charity is still imported, just without implausible figures
+	if !i.checkFinancials(record.RegisteredCharityNumber, yearEnd, orDefault(record.LatestIncome, 0), orDefault(record.LatestExpenditure, 0)) {
+		return false
+	}
 
-	_, err := tx.Exec(`
-		INSERT OR REPLACE INTO financials
-		(charity_number, financial_year_end, total_income, total_spending, 
-		 charitable_activities_spend, raising_funds_spend, other_spend, 
-		 reserves, assets, trustees, last_updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`,
+	// Zeros here are "unknown" and leave any existing breakdown for the year intact
+	_, err := tx.Exec(database.UpsertFinancialSQL,
 		record.RegisteredCharityNumber,
 		yearEnd,
 		orDefault(record.LatestIncome, 0),
@@ -829,15 +1301,46 @@ func (i *Importer) insertFinancialData(tx *sql.Tx, record CharityRecord) {
 		0, // Not in the data dump
 		0, // Not in the data dump
 		0, // Not in the data dump
+		0, // Not in the data dump
 		time.Now(),
 	)
 	if err != nil && i.config.Verbose {
 		log.Printf("Failed to insert financial data for charity %d: %v", record.RegisteredCharityNumber, err)
 	}
+	return err == nil
+}
+
+// markDataSources records source as where a section of each charity in a batch
+// came from. Only a busy database is returned, so insertWithRetry can run the batch
+// again; other failures leave the source unknown.
+func (i *Importer) markDataSources(tx *sql.Tx, section database.DataSection, source string, charityNumbers map[int]bool) error {
+	for charityNum := range charityNumbers {
+		err := database.SetDataSource(tx, section, charityNum, source)
+		if database.IsBusy(err) {
+			return err
+		}
+		if err != nil && i.config.Verbose {
+			log.Printf("Failed to record %s for charity %d: %v", section, charityNum, err)
+		}
+	}
+	return nil
 }
 
 // Helper functions
 
+// normalizePhone returns a contact phone number without separators, or nil if
+// there's none or it isn't a phone number
+func normalizePhone(phone *string) *string {
+	if phone == nil {
+		return nil
+	}
+	normalized, err := validation.NormalizePhone(*phone)
+	if err != nil {
+		return nil
+	}
+	return &normalized
+}
+
 func buildAddress(parts ...*string) string {
 	var address string
 	for _, part := range parts {
@@ -851,27 +1354,6 @@ func buildAddress(parts ...*string) string {
 	return address
 }
 
-func parseDate(dateStr string) time.Time {
-	if dateStr == "" {
-		return time.Time{}
-	}
-
-	// Try multiple date formats
-	formats := []string{
-		"2006-01-02T15:04:05",
-		"2006-01-02",
-		time.RFC3339,
-	}
-
-	for _, format := range formats {
-		if t, err := time.Parse(format, dateStr); err == nil {
-			return t
-		}
-	}
-
-	return time.Time{}
-}
-
 func orDefault(val *float64, def float64) float64 {
 	if val == nil {
 		return def
@@ -893,33 +1375,80 @@ func orDefaultPtrInt(val *int, def int) int {
 	return *val
 }
 
+// noteExtractDate tracks the latest date_of_extract seen in the current file
+func (i *Importer) noteExtractDate(value string) {
+	if date := dates.Parse(value); date.After(i.extractDate) {
+		i.extractDate = date
+	}
+}
+
+// recordExtractDate stores the extract date of the file just imported, so the
+// server can report how fresh its data is, then resets it for the next file
+func (i *Importer) recordExtractDate(dataset string) {
+	if i.extractDate.IsZero() {
+		return
+	}
+	_, err := database.ExecRetry(i.db, `
+		INSERT OR REPLACE INTO data_extracts (dataset, date_of_extract, imported_at)
+		VALUES (?, ?, ?)
+	`, dataset, i.extractDate, time.Now())
+	if err != nil {
+		log.Printf("Failed to record extract date for %s: %v", dataset, err)
+	}
+	i.extractDate = time.Time{}
+}
+
+// reachedLimit reports whether the MaxRecords sampling limit has been hit
+func (i *Importer) reachedLimit(recordNum int) bool {
+	if i.config.MaxRecords > 0 && recordNum >= i.config.MaxRecords {
+		log.Printf("Reached record limit (%d), stopping import early", i.config.MaxRecords)
+		return true
+	}
+	return false
+}
+
 func (i *Importer) logProgress() {
-	elapsed := time.Since(i.progress.StartTime)
-	rate := float64(i.progress.ProcessedRecords) / elapsed.Seconds()
+	progress := i.GetProgress()
+	elapsed := time.Since(progress.StartTime)
+	rate := float64(progress.ProcessedRecords) / elapsed.Seconds()
 
 	log.Printf("Progress: %d processed (%d success, %d failed, %d skipped) | Rate: %.2f/sec",
-		i.progress.ProcessedRecords,
-		i.progress.SuccessRecords,
-		i.progress.FailedRecords,
-		i.progress.SkippedRecords,
+		progress.ProcessedRecords,
+		progress.SuccessRecords,
+		progress.FailedRecords,
+		progress.SkippedRecords,
 		rate,
 	)
 
+	i.progressMu.Lock()
 	i.progress.LastUpdate = time.Now()
+	i.progressMu.Unlock()
 }
 
 func (i *Importer) logFinalStats(label string) {
-	elapsed := time.Since(i.progress.StartTime)
-	rate := float64(i.progress.ProcessedRecords) / elapsed.Seconds()
+	progress := i.GetProgress()
+	elapsed := time.Since(progress.StartTime)
+	rate := float64(progress.ProcessedRecords) / elapsed.Seconds()
 
 	log.Printf("\n=== %s Complete ===", label)
-	log.Printf("Total Records: %d", i.progress.TotalRecords)
-	log.Printf("Processed: %d", i.progress.ProcessedRecords)
-	log.Printf("Successful: %d", i.progress.SuccessRecords)
-	log.Printf("Failed: %d", i.progress.FailedRecords)
-	log.Printf("Skipped: %d", i.progress.SkippedRecords)
+	log.Printf("Total Records: %d", progress.TotalRecords)
+	log.Printf("Processed: %d", progress.ProcessedRecords)
+	log.Printf("Successful: %d", progress.SuccessRecords)
+	log.Printf("Failed: %d", progress.FailedRecords)
+	log.Printf("Skipped: %d", progress.SkippedRecords)
 	log.Printf("Time Elapsed: %v", elapsed)
 	log.Printf("Average Rate: %.2f records/second\n", rate)
+
+	i.phases = append(i.phases, PhaseStats{
+		Label:           label,
+		TotalRecords:    progress.TotalRecords,
+		Processed:       progress.ProcessedRecords,
+		Successful:      progress.SuccessRecords,
+		Failed:          progress.FailedRecords,
+		Skipped:         progress.SkippedRecords,
+		DurationSeconds: elapsed.Seconds(),
+		Rate:            rate,
+	})
 }
 
 // StreamingImportCharities imports charities using a streaming approach for very large files
@@ -929,26 +1458,85 @@ func (i *Importer) StreamingImportCharities() error {
 	return i.ImportCharities()
 }
 
-// GetProgress returns the current import progress
+// GetProgress returns a consistent snapshot of the current import progress. It
+// is safe to call from another goroutine while an import is running.
 func (i *Importer) GetProgress() ImportProgress {
+	i.progressMu.Lock()
+	defer i.progressMu.Unlock()
 	return i.progress
 }
 
+// resetProgress starts tracking a new phase of total records (0 if not yet known)
+func (i *Importer) resetProgress(total int) {
+	i.setProgress(ImportProgress{
+		TotalRecords: total,
+		StartTime:    time.Now(),
+		LastUpdate:   time.Now(),
+	})
+}
+
+// setProgress replaces the progress, e.g. to roll back a batch that will be retried
+func (i *Importer) setProgress(progress ImportProgress) {
+	i.progressMu.Lock()
+	defer i.progressMu.Unlock()
+	i.progress = progress
+}
+
+// setTotalRecords updates the number of records in the current phase, which for
+// a file grows as it's decoded
+func (i *Importer) setTotalRecords(total int) {
+	i.progressMu.Lock()
+	defer i.progressMu.Unlock()
+	i.progress.TotalRecords = total
+}
+
+// addProgress adds delta's record counts to the progress
+func (i *Importer) addProgress(delta ImportProgress) {
+	i.progressMu.Lock()
+	defer i.progressMu.Unlock()
+	i.progress.ProcessedRecords += delta.ProcessedRecords
+	i.progress.SuccessRecords += delta.SuccessRecords
+	i.progress.SkippedRecords += delta.SkippedRecords
+	i.progress.FailedRecords += delta.FailedRecords
+}
+
+// GetPhaseStats returns a summary of each import phase completed so far
+func (i *Importer) GetPhaseStats() []PhaseStats {
+	return append([]PhaseStats(nil), i.phases...)
+}
+
 // CalculateAllScores calculates transparency scores for all charities in the database
 func (i *Importer) CalculateAllScores() error {
 	log.Println("Starting score calculation for all charities...")
 
-	// Get count of charities that need scores (main charities only, exclude removed)
-	var totalCharities int
-	err := i.db.QueryRow(`
-		SELECT COUNT(*) FROM charities c
+	// Charities that need scores (main charities only, exclude removed), including
+	// those last scored by an older version of the formula. With ScoreSince set,
+	// only charities updated since then are considered, and a score calculated
+	// before the charity's data last changed counts as out of date.
+	needsScore := `
 		WHERE c.linked_charity_number = 0
-		  AND c.status NOT IN ('Removed', 'RM')
+		  AND ` + database.NotRemovedSQL("c.status") + `
 		  AND NOT EXISTS (
 			SELECT 1 FROM charity_scores s 
 			WHERE s.charity_number = c.registered_number
+			  AND s.scoring_version >= ?`
+	args := []any{scoring.ScoringVersion}
+	if !i.config.ScoreSince.IsZero() {
+		log.Printf("Only scoring charities updated since %s", i.config.ScoreSince.Format(time.RFC3339))
+		needsScore += `
+			  AND datetime(s.last_calculated) >= datetime(c.last_updated)
 		  )
-	`).Scan(&totalCharities)
+		  AND datetime(c.last_updated) >= datetime(?)`
+		args = append(args, i.config.ScoreSince.UTC().Format("2006-01-02 15:04:05"))
+	} else {
+		needsScore += `
+		  )`
+	}
+
+	// Get count of charities that need scores
+	var totalCharities int
+	err := i.db.QueryRow(`
+		SELECT COUNT(*) FROM charities c`+needsScore, args...).Scan(&totalCharities)
 	if err != nil {
 		return fmt.Errorf("failed to count charities: %w", err)
 	}
@@ -956,30 +1544,23 @@ func (i *Importer) CalculateAllScores() error {
 	log.Printf("Found %d charities needing score calculation", totalCharities)
 
 	if totalCharities == 0 {
-		log.Println("All charities already have scores")
+		log.Println("All charities already have current scores")
 		return nil
 	}
 
 	// Reset progress tracker
-	i.progress = ImportProgress{
-		StartTime:  time.Now(),
-		LastUpdate: time.Now(),
-	}
+	i.resetProgress(totalCharities)
+	i.scoringPublished = time.Time{}
+	i.publishScoringProgress("Score calculation", false)
 
 	// Fetch ALL charity numbers upfront (before we start modifying the database)
 	// This prevents issues with OFFSET pagination as we add scores
 	log.Println("Fetching all charity numbers that need scoring...")
 	rows, err := i.db.Query(`
 		SELECT c.registered_number 
-		FROM charities c
-		WHERE c.linked_charity_number = 0
-		  AND c.status NOT IN ('Removed', 'RM')
-		  AND NOT EXISTS (
-			SELECT 1 FROM charity_scores s 
-			WHERE s.charity_number = c.registered_number
-		  )
+		FROM charities c`+needsScore+`
 		ORDER BY c.registered_number
-	`)
+	`, args...)
 	if err != nil {
 		return fmt.Errorf("failed to fetch charity numbers: %w", err)
 	}
@@ -1005,19 +1586,87 @@ func (i *Importer) CalculateAllScores() error {
 			if i.config.Verbose {
 				log.Printf("Failed to calculate score for charity %d: %v", charityNum, err)
 			}
-			i.progress.FailedRecords++
+			i.addProgress(ImportProgress{FailedRecords: 1})
 		} else {
-			i.progress.SuccessRecords++
+			i.addProgress(ImportProgress{SuccessRecords: 1})
 		}
 
-		i.progress.ProcessedRecords++
+		i.addProgress(ImportProgress{ProcessedRecords: 1})
+		i.publishScoringProgress("Score calculation", false)
 
 		// Log progress periodically
-		if i.progress.ProcessedRecords%i.config.ProgressInterval == 0 {
+		if i.GetProgress().ProcessedRecords%i.config.ProgressInterval == 0 {
 			i.logProgress()
 		}
 	}
 
+	i.publishScoringProgress("Score calculation", true)
 	i.logFinalStats("Score calculation")
 	return nil
 }
+
+// reindexTables are the tables whose indexes Reindex rebuilds
+var reindexTables = []string{
+	"charities", "financials", "trustees", "activities", "charity_scores",
+	"annual_return_history", "charity_classifications", "charity_removals", "search_cache",
+}
+
+// Reindex rebuilds the structures derived from the charities table, for use after
+// a bulk load that bypassed the normal write paths (e.g. a restored database).
+// It drops cached scores for charities that have been removed or no longer
+// exist, rebuilds each table's indexes and refreshes the query planner
+// statistics. Running it again on an up-to-date database changes nothing.
+func (i *Importer) Reindex() error {
+	log.Println("Removing scores for removed or missing charities...")
+
+	var stale int
+	err := i.db.QueryRow(`
+		SELECT COUNT(*) FROM charity_scores
+		WHERE charity_number NOT IN (
+			SELECT registered_number FROM charities
+			WHERE linked_charity_number = 0 AND ` + database.NotRemovedSQL("status") + `
+		)
+	`).Scan(&stale)
+	if err != nil {
+		return fmt.Errorf("failed to count stale scores: %w", err)
+	}
+	i.resetProgress(stale)
+
+	// Delete in batches so a large clean-up doesn't hold the write lock for long
+	for {
+		result, err := database.ExecRetry(i.db, `
+			DELETE FROM charity_scores WHERE charity_number IN (
+				SELECT charity_number FROM charity_scores
+				WHERE charity_number NOT IN (
+					SELECT registered_number FROM charities
+					WHERE linked_charity_number = 0 AND `+database.NotRemovedSQL("status")+`
+				)
+				LIMIT ?
+			)
+		`, i.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to remove stale scores: %w", err)
+		}
+		deleted, _ := result.RowsAffected()
+		if deleted == 0 {
+			break
+		}
+		i.addProgress(ImportProgress{ProcessedRecords: int(deleted), SuccessRecords: int(deleted)})
+		i.logProgress()
+	}
+	i.logFinalStats("Stale score removal")
+
+	for n, table := range reindexTables {
+		log.Printf("[%d/%d] Rebuilding indexes on %s...", n+1, len(reindexTables), table)
+		if _, err := i.db.Exec("REINDEX " + table); err != nil {
+			return fmt.Errorf("failed to reindex %s: %w", table, err)
+		}
+	}
+
+	log.Println("Updating query planner statistics...")
+	if _, err := i.db.Exec("ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze database: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// InputPaths expands an import path into the files to read, in order. path may be
+// a single file, a directory (every .json file in it) or a glob pattern such as
+// "charity_*.json", so extracts split into chunks can be imported without
+// concatenating them first.
+func InputPaths(path string) ([]string, error) {
+	var paths []string
+	if strings.ContainsAny(path, "*?[") {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+		paths = matches
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return []string{path}, nil
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		paths = matches
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no JSON files match %s", path)
+	}
+	sort.Slice(paths, func(a, b int) bool {
+		return naturalLess(paths[a], paths[b])
+	})
+	return paths, nil
+}
+
+// openInputs opens every file matched by path (see InputPaths) with any BOM
+// stripped. The returned function closes them all.
+func openInputs(path string) ([]io.Reader, func(), error) {
+	paths, err := InputPaths(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []*os.File
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+
+	readers := make([]io.Reader, 0, len(paths))
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		files = append(files, f)
+		readers = append(readers, stripBOM(f))
+	}
+	return readers, closeAll, nil
+}
+
+// naturalLess orders names with runs of digits compared by value, so numbered
+// chunks sort as "part2.json" before "part10.json"
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		aDigits, bDigits := leadingDigits(a), leadingDigits(b)
+		if aDigits != "" && bDigits != "" {
+			aNum, bNum := strings.TrimLeft(aDigits, "0"), strings.TrimLeft(bDigits, "0")
+			if len(aNum) != len(bNum) {
+				return len(aNum) < len(bNum)
+			}
+			if aNum != bNum {
+				return aNum < bNum
+			}
+			a, b = a[len(aDigits):], b[len(bDigits):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// leadingDigits returns the run of ASCII digits at the start of s
+func leadingDigits(s string) string {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return s[:n]
+}
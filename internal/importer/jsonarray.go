@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// openArray reads the opening bracket of an extract, which is a JSON array of
+// records, explaining what's wrong with an empty or non-array file
+func openArray(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if errors.Is(err, io.EOF) {
+		return errors.New("file is empty")
+	}
+	if err != nil {
+		return fmt.Errorf("file is not valid JSON: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected a JSON array of records, got: %v", token)
+	}
+	return nil
+}
+
+// closeArray reads the closing bracket once decoder.More reports no more records,
+// so a file truncated between records isn't mistaken for a complete one
+func closeArray(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("file is truncated: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != ']' {
+		return fmt.Errorf("expected the end of the JSON array, got: %v", token)
+	}
+	return nil
+}
+
+// recordError reports whether a decoding error is confined to one record, such
+// as a field of the wrong type, so decoding can carry on with the next. Syntax
+// errors and truncation leave the decoder unusable.
+func recordError(err error) bool {
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &typeErr)
+}
+
+// inputError adds which chunk file of a split extract an error came from
+func inputError(n, count int, err error) error {
+	if count > 1 {
+		return fmt.Errorf("file %d of %d: %w", n+1, count, err)
+	}
+	return err
+}
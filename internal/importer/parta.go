@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"charitylens/internal/database"
+	"charitylens/internal/dates"
+)
+
+// AnnualReturnPartARecord represents how a charity raised its income in a
+// financial year, from the charity_annual_return_parta JSON dump
+type AnnualReturnPartARecord struct {
+	DateOfExtract               string   `json:"date_of_extract"`
+	OrganisationNumber          int      `json:"organisation_number"`
+	RegisteredCharityNumber     int      `json:"registered_charity_number"`
+	LatestFinPeriodSubmittedInd bool     `json:"latest_fin_period_submitted_ind"`
+	FinPeriodOrderNumber        int      `json:"fin_period_order_number"`
+	FinPeriodStartDate          string   `json:"fin_period_start_date"`
+	FinPeriodEndDate            string   `json:"fin_period_end_date"`
+	TotalGrossIncome            *float64 `json:"total_gross_income"`
+	RaisesFundsFromPublic       *bool    `json:"charity_raises_funds_from_public"`
+	HasTradingSubsidiary        *bool    `json:"charity_has_trading_subsidiary"`
+}
+
+// ImportAnnualReturnPartA imports how charities raised their income from an annual
+// return Part A file. Like Part B, only each charity's latest period is kept
+// unless ImportAllPeriods is set.
+func (i *Importer) ImportAnnualReturnPartA() error {
+	if i.config.PartAFile == "" {
+		log.Println("No annual return Part A file specified, skipping")
+		return nil
+	}
+
+	log.Printf("Starting annual return Part A import from: %s", i.config.PartAFile)
+	i.resetProgress(0)
+
+	readers, closeFiles, err := openInputs(i.config.PartAFile)
+	if err != nil {
+		return fmt.Errorf("failed to open annual return Part A file: %w", err)
+	}
+	defer closeFiles()
+
+	return i.importAnnualReturnPartAFromReader(readers...)
+}
+
+// ImportAnnualReturnPartAFromReader imports annual return Part A data from an
+// io.Reader
+func (i *Importer) ImportAnnualReturnPartAFromReader(r io.Reader) error {
+	log.Println("Starting annual return Part A import from in-memory data")
+	i.resetProgress(0)
+
+	reader := stripBOM(r)
+	return i.importAnnualReturnPartAFromReader(reader)
+}
+
+// importAnnualReturnPartAFromReader is the internal implementation that works with
+// any reader. Several readers are imported in order as one dataset, sharing the
+// batch, progress and record limit.
+func (i *Importer) importAnnualReturnPartAFromReader(readers ...io.Reader) error {
+	batch := make([]AnnualReturnPartARecord, 0, i.config.BatchSize)
+	recordNum := 0
+	var readErr error
+
+chunks:
+	for n, reader := range readers {
+		if len(readers) > 1 {
+			log.Printf("Reading file %d of %d", n+1, len(readers))
+		}
+		decoder := json.NewDecoder(reader)
+
+		// Stop at an empty or non-array file, keeping the records already read
+		if err := openArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
+		}
+
+		// Process array elements
+		for decoder.More() {
+			if i.reachedLimit(recordNum) {
+				break chunks
+			}
+
+			var record AnnualReturnPartARecord
+			if err := decoder.Decode(&record); err != nil {
+				if !recordError(err) {
+					readErr = inputError(n, len(readers), fmt.Errorf("malformed JSON after record %d: %w", recordNum, err))
+					break chunks
+				}
+				log.Printf("Failed to decode annual return Part A record %d: %v", recordNum, err)
+				i.addProgress(ImportProgress{FailedRecords: 1})
+				continue
+			}
+
+			i.noteExtractDate(record.DateOfExtract)
+
+			// Keep the same periods as the Part B import, so each financial year
+			// can be matched with its Part A
+			if record.LatestFinPeriodSubmittedInd || i.config.ImportAllPeriods {
+				batch = append(batch, record)
+			} else {
+				i.addProgress(ImportProgress{SkippedRecords: 1})
+			}
+
+			recordNum++
+			i.setTotalRecords(recordNum)
+
+			// Process batch when full
+			if len(batch) >= i.config.BatchSize {
+				if err := i.insertWithRetry(func() error { return i.insertPartABatch(batch) }); err != nil {
+					log.Printf("Failed to insert annual return Part A batch: %v", err)
+				}
+				batch = batch[:0] // Reset batch
+			}
+
+			// Log progress
+			if recordNum%i.config.ProgressInterval == 0 {
+				i.logProgress()
+			}
+		}
+
+		// More also stops at the end of a file missing its closing bracket
+		if err := closeArray(decoder); err != nil {
+			readErr = inputError(n, len(readers), err)
+			break
+		}
+	}
+
+	// Process remaining records
+	if len(batch) > 0 {
+		if err := i.insertWithRetry(func() error { return i.insertPartABatch(batch) }); err != nil {
+			log.Printf("Failed to insert final annual return Part A batch: %v", err)
+		}
+	}
+
+	i.recordExtractDate("charity_annual_return_parta")
+	i.logFinalStats("Annual return Part A import")
+	return readErr
+}
+
+// insertPartABatch stores a batch of annual return Part A records, replacing what
+// was stored for the same charity and financial year
+func (i *Importer) insertPartABatch(records []AnnualReturnPartARecord) error {
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO annual_return_parta
+		(charity_number, financial_year_end, total_gross_income, raises_funds_from_public,
+		 has_trading_subsidiary, last_updated)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (charity_number, financial_year_end) DO UPDATE SET
+			total_gross_income = excluded.total_gross_income,
+			raises_funds_from_public = excluded.raises_funds_from_public,
+			has_trading_subsidiary = excluded.has_trading_subsidiary,
+			last_updated = excluded.last_updated
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, record := range records {
+		// Skip invalid records, and charities filtered out of the import
+		if record.RegisteredCharityNumber == 0 || i.isExcluded(record.RegisteredCharityNumber) {
+			i.addProgress(ImportProgress{SkippedRecords: 1})
+			continue
+		}
+
+		yearEnd := dates.Parse(record.FinPeriodEndDate)
+		if yearEnd.IsZero() {
+			i.addProgress(ImportProgress{SkippedRecords: 1})
+			continue
+		}
+
+		_, err := stmt.Exec(
+			record.RegisteredCharityNumber,
+			yearEnd,
+			record.TotalGrossIncome,
+			record.RaisesFundsFromPublic,
+			record.HasTradingSubsidiary,
+			time.Now(),
+		)
+		if database.IsBusy(err) {
+			// Abandon the batch so insertWithRetry can run it again
+			return err
+		}
+		if err != nil {
+			if i.config.Verbose {
+				log.Printf("Failed to insert annual return Part A for charity %d: %v", record.RegisteredCharityNumber, err)
+			}
+			i.addProgress(ImportProgress{FailedRecords: 1})
+			continue
+		}
+
+		i.addProgress(ImportProgress{SuccessRecords: 1})
+	}
+
+	i.addProgress(ImportProgress{ProcessedRecords: len(records)})
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package importer
+
+import (
+	"log"
+	"time"
+
+	"charitylens/internal/scoring"
+)
+
+// startScoreWorker starts scoring charities as their financials are imported, for
+// ImportConfig.ScoreDuringImport. insertFinancialBatch queues each charity once its
+// batch commits, so a score is never calculated before the charity's financials
+// land. The charity record, trustees and filing history are also read, so they must
+// be imported before financials. The returned function waits for the queue to
+// drain and records the scoring phase.
+//
+// Charities that fail to score here, or have no financials, are left for
+// CalculateAllScores to pick up after the import.
+func (i *Importer) startScoreWorker() (stop func()) {
+	queue := make(chan int, i.config.BatchSize)
+	done := make(chan PhaseStats)
+	i.scoreQueue = queue
+
+	go func() {
+		stats := PhaseStats{Label: "Score calculation during import"}
+		start := time.Now()
+		seen := make(map[int]bool)
+
+		for charityNum := range queue {
+			if seen[charityNum] {
+				continue
+			}
+			seen[charityNum] = true
+			stats.TotalRecords++
+
+			// Only main, registered charities are scored, as in CalculateAllScores
+			scorable, err := i.scorable(charityNum)
+			if err == nil && !scorable {
+				stats.Skipped++
+				continue
+			}
+			if err == nil {
+				_, err = scoring.CalculateScore(i.db, charityNum)
+			}
+
+			stats.Processed++
+			if err != nil {
+				if i.config.Verbose {
+					log.Printf("Failed to calculate score for charity %d during import: %v", charityNum, err)
+				}
+				stats.Failed++
+			} else {
+				stats.Successful++
+			}
+
+			if stats.Processed%i.config.ProgressInterval == 0 {
+				log.Printf("Scoring: %d charities scored during import (%d failed)", stats.Successful, stats.Failed)
+			}
+		}
+
+		stats.DurationSeconds = time.Since(start).Seconds()
+		if stats.DurationSeconds > 0 {
+			stats.Rate = float64(stats.Processed) / stats.DurationSeconds
+		}
+		done <- stats
+	}()
+
+	return func() {
+		close(queue)
+		i.scoreQueue = nil
+		stats := <-done
+
+		log.Printf("\n=== %s Complete ===", stats.Label)
+		log.Printf("Scored: %d", stats.Successful)
+		log.Printf("Failed: %d", stats.Failed)
+		log.Printf("Skipped: %d", stats.Skipped)
+		i.phases = append(i.phases, stats)
+	}
+}
@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"log"
+	"time"
+
+	"charitylens/internal/database"
+)
+
+// scoringStatusInterval is how often a scoring run publishes its progress
+const scoringStatusInterval = 2 * time.Second
+
+// publishScoringProgress records the progress of a batch scoring run in the
+// scoring_progress table, so the server can report it through its admin scoring
+// status endpoint. Updates are throttled to one per scoringStatusInterval, except
+// the final one when finished is true.
+func (i *Importer) publishScoringProgress(label string, finished bool) {
+	now := time.Now()
+	if !finished && now.Sub(i.scoringPublished) < scoringStatusInterval {
+		return
+	}
+	i.scoringPublished = now
+
+	progress := i.GetProgress()
+	var finishedAt any
+	if finished {
+		finishedAt = now
+	}
+	err := database.WithWriteLock(i.db, func() error {
+		_, err := database.ExecRetry(i.db, `
+			INSERT OR REPLACE INTO scoring_progress
+			(id, label, total_records, processed, successful, failed, skipped, started_at, updated_at, finished_at, job_id)
+			VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, label, progress.TotalRecords, progress.ProcessedRecords, progress.SuccessRecords,
+			progress.FailedRecords, progress.SkippedRecords, progress.StartTime, now, finishedAt,
+			nullIfEmpty(i.config.ScoringJobID))
+		return err
+	})
+	if err != nil && i.config.Verbose {
+		log.Printf("Failed to publish scoring progress: %v", err)
+	}
+}
+
+// nullIfEmpty stores an empty string as NULL
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"charitylens/internal/database"
+	"charitylens/internal/scoring"
+)
+
+// warmScoreWorkers is how many charities WarmScores scores at once
+const warmScoreWorkers = 4
+
+// WarmScores calculates scores for just the given charities, such as the most
+// viewed ones after a fresh import, so their first page view doesn't have to.
+// It's much lighter than CalculateAllScores.
+func (i *Importer) WarmScores(charityNumbers []int) error {
+	return i.ScoreCharities("Score warming", charityNumbers)
+}
+
+// ScoreCharities recalculates the scores of the given charities, warmScoreWorkers
+// at a time, publishing its progress under label. Duplicates are scored once, and
+// charities that aren't main, registered charities are skipped. Per-charity
+// failures are counted rather than returned; the totals are logged and recorded
+// as a phase.
+func (i *Importer) ScoreCharities(label string, charityNumbers []int) error {
+	seen := make(map[int]bool, len(charityNumbers))
+	var unique []int
+	for _, charityNum := range charityNumbers {
+		if !seen[charityNum] {
+			seen[charityNum] = true
+			unique = append(unique, charityNum)
+		}
+	}
+
+	log.Printf("%s: scoring %d charities...", label, len(unique))
+	i.resetProgress(len(unique))
+	i.scoringPublished = time.Time{}
+	i.publishScoringProgress(label, false)
+
+	type result struct {
+		charityNum int
+		scored     bool
+		err        error
+	}
+
+	work := make(chan int)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for range warmScoreWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for charityNum := range work {
+				scorable, err := i.scorable(charityNum)
+				if err == nil && scorable {
+					_, err = scoring.CalculateScore(i.db, charityNum)
+				}
+				results <- result{charityNum: charityNum, scored: scorable, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, charityNum := range unique {
+			work <- charityNum
+		}
+		close(work)
+		wg.Wait()
+		close(results)
+	}()
+
+	for res := range results {
+		i.addProgress(ImportProgress{ProcessedRecords: 1})
+		switch {
+		case res.err != nil:
+			if i.config.Verbose {
+				log.Printf("Failed to calculate score for charity %d: %v", res.charityNum, res.err)
+			}
+			i.addProgress(ImportProgress{FailedRecords: 1})
+		case !res.scored:
+			i.addProgress(ImportProgress{SkippedRecords: 1})
+		default:
+			i.addProgress(ImportProgress{SuccessRecords: 1})
+		}
+
+		i.publishScoringProgress(label, false)
+
+		if i.GetProgress().ProcessedRecords%i.config.ProgressInterval == 0 {
+			i.logProgress()
+		}
+	}
+
+	i.publishScoringProgress(label, true)
+	i.logFinalStats(label)
+	return nil
+}
+
+// scorable reports whether a charity is a main, registered charity, the only kind
+// that is scored
+func (i *Importer) scorable(charityNum int) (bool, error) {
+	var scorable bool
+	err := i.db.QueryRow(`
+		SELECT COUNT(*) > 0 FROM charities
+		WHERE registered_number = ?
+		  AND linked_charity_number = 0
+		  AND `+database.NotRemovedSQL("status")+`
+	`, charityNum).Scan(&scorable)
+	return scorable, err
+}
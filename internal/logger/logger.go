@@ -2,17 +2,79 @@ package logger
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+)
+
+// Output formats accepted by Configure
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
 var defaultLogger *slog.Logger
 
+// format is the configured output format, also used by WithDebug
+var format = FormatText
+
 func init() {
 	// Initialize default logger
-	defaultLogger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	defaultLogger = New(os.Stdout, format, slog.LevelInfo)
+}
+
+// New creates a logger writing to w in the given format ("json" or "text"; anything
+// else is treated as text) at the given minimum level
+func New(w io.Writer, logFormat string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if logFormat == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// ParseLevel parses a log level name: debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid log level %q (must be debug, info, warn or error)", name)
+}
+
+// Configure sets the global logger's format ("json" or "text") and level (see
+// ParseLevel). An invalid format or level falls back to text or info and is
+// reported in the returned error; the logger is configured either way.
+func Configure(logFormat, logLevel string) (*slog.Logger, error) {
+	var errs []error
+
+	level, err := ParseLevel(logLevel)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	switch logFormat = strings.ToLower(strings.TrimSpace(logFormat)); logFormat {
+	case FormatText, FormatJSON:
+		format = logFormat
+	case "":
+		format = FormatText
+	default:
+		errs = append(errs, fmt.Errorf("invalid log format %q (must be json or text)", logFormat))
+		format = FormatText
+	}
+
+	logger := New(os.Stdout, format, level)
+	SetLogger(logger)
+	return logger, errors.Join(errs...)
 }
 
 // SetLogger sets the global logger
@@ -20,18 +82,14 @@ func SetLogger(l *slog.Logger) {
 	defaultLogger = l
 }
 
-// WithDebug returns a logger configured for debug mode
+// WithDebug returns a logger configured for debug mode, in the configured format
 func WithDebug(debug bool) *slog.Logger {
 	level := slog.LevelInfo
 	if debug {
 		level = slog.LevelDebug
 	}
 
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	})
-
-	logger := slog.New(handler)
+	logger := New(os.Stdout, format, level)
 	SetLogger(logger)
 	return logger
 }
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		format string
+		json   bool
+	}{
+		{FormatJSON, true},
+		{FormatText, false},
+		{"", false},
+		{"yaml", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(&buf, tt.format, slog.LevelInfo)
+			l.Debug("hidden")
+			l.Info("Imported charities", "operation", "import", "count", 3)
+
+			line := strings.TrimSpace(buf.String())
+			if strings.Contains(line, "hidden") || strings.Count(line, "\n") != 0 {
+				t.Fatalf("want only the info line, got %q", line)
+			}
+
+			if tt.json {
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					t.Fatalf("output isn't JSON: %q", line)
+				}
+				if entry["level"] != "INFO" || entry["msg"] != "Imported charities" ||
+					entry["operation"] != "import" || entry["count"] != 3.0 {
+					t.Errorf("unexpected JSON entry %v", entry)
+				}
+				return
+			}
+
+			for _, want := range []string{"level=INFO", `msg="Imported charities"`, "operation=import", "count=3"} {
+				if !strings.Contains(line, want) {
+					t.Errorf("text output %q is missing %s", line, want)
+				}
+			}
+			if strings.HasPrefix(line, "{") {
+				t.Errorf("text output looks like JSON: %q", line)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"", slog.LevelInfo, false},
+		{" warn ", slog.LevelWarn, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() { Configure(FormatText, "info") })
+
+	tests := []struct {
+		format, level string
+		wantFormat    string
+		wantLevel     slog.Level
+		wantErr       bool
+	}{
+		{"json", "debug", FormatJSON, slog.LevelDebug, false},
+		{" JSON ", "warn", FormatJSON, slog.LevelWarn, false},
+		{"text", "error", FormatText, slog.LevelError, false},
+		{"", "", FormatText, slog.LevelInfo, false},
+		{"yaml", "info", FormatText, slog.LevelInfo, true},
+		{"json", "loud", FormatJSON, slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		l, err := Configure(tt.format, tt.level)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Configure(%q, %q) error = %v, want error %v", tt.format, tt.level, err, tt.wantErr)
+		}
+		if format != tt.wantFormat {
+			t.Errorf("Configure(%q, %q) set format %q, want %q", tt.format, tt.level, format, tt.wantFormat)
+		}
+		if defaultLogger != l {
+			t.Errorf("Configure(%q, %q) didn't set the global logger", tt.format, tt.level)
+		}
+
+		_, isJSON := l.Handler().(*slog.JSONHandler)
+		if isJSON != (tt.wantFormat == FormatJSON) {
+			t.Errorf("Configure(%q, %q) handler is %T, want %s", tt.format, tt.level, l.Handler(), tt.wantFormat)
+		}
+		ctx := t.Context()
+		if !l.Enabled(ctx, tt.wantLevel) || (tt.wantLevel > slog.LevelDebug && l.Enabled(ctx, tt.wantLevel-1)) {
+			t.Errorf("Configure(%q, %q) doesn't log from %v", tt.format, tt.level, tt.wantLevel)
+		}
+	}
+}
@@ -20,8 +20,13 @@ type Charity struct {
 	WhoTheCharityHelps  string     `json:"who_the_charity_helps" db:"who_the_charity_helps"`
 	HowTheCharityWorks  string     `json:"how_the_charity_works" db:"how_the_charity_works"`
 	LastUpdated         time.Time  `json:"last_updated" db:"last_updated"`
+	DataExtractDate     *time.Time `json:"data_extract_date" db:"data_extract_date"`
 	OverallScore        float64    `json:"overall_score,omitempty" db:"-"`    // Not stored in charities table, joined from scores
 	LinkedCharities     []Charity  `json:"linked_charities,omitempty" db:"-"` // Populated when querying with linked entities
+
+	// DataSources is where each section of the record came from, set only when
+	// loading a single charity
+	DataSources *DataSources `json:"data_sources,omitempty" db:"-"`
 }
 
 // Financial represents financial data for a charity
@@ -39,6 +44,33 @@ type Financial struct {
 	Volunteers                int       `json:"volunteers" db:"volunteers"`
 	Trustees                  int       `json:"trustees" db:"trustees"`
 	LastUpdated               time.Time `json:"last_updated" db:"last_updated"`
+
+	// PartA is how the charity raised its income in the same year, from annual
+	// return Part A, or nil if it isn't on record
+	PartA *AnnualReturnPartA `json:"-" db:"-"`
+}
+
+// AnnualReturnPartA is how a charity raised its income in a financial year, as
+// reported in Part A of its annual return
+type AnnualReturnPartA struct {
+	CharityNumber         int       `json:"charity_number" db:"charity_number"`
+	FinancialYearEnd      time.Time `json:"financial_year_end" db:"financial_year_end"`
+	TotalGrossIncome      float64   `json:"total_gross_income" db:"total_gross_income"`
+	RaisesFundsFromPublic bool      `json:"raises_funds_from_public" db:"raises_funds_from_public"`
+	HasTradingSubsidiary  bool      `json:"has_trading_subsidiary" db:"has_trading_subsidiary"`
+}
+
+// FinancialMetrics holds ratios derived from a charity's latest financial year.
+// Each field is nil when it can't be calculated from the available data.
+type FinancialMetrics struct {
+	CharitableSpendRatio *float64 `json:"charitable_spend_ratio"` // Charitable activities spend / total spending
+	FundraisingCostRatio *float64 `json:"fundraising_cost_ratio"` // Raising funds spend / total income
+	ReserveMonths        *float64 `json:"reserve_months"`         // Months of spending covered by reserves (or assets)
+	AdminOverheadPct     *float64 `json:"admin_overhead_pct"`     // Spending on neither activities nor fundraising, as a percentage
+
+	// TradingCostsExcluded is true when raising funds spend is left out of
+	// CharitableSpendRatio as the cost of a trading subsidiary
+	TradingCostsExcluded bool `json:"trading_costs_excluded"`
 }
 
 // Trustee represents a trustee of a charity
@@ -64,6 +96,8 @@ type CharityScore struct {
 	TransparencyScore    float64   `json:"transparency_score" db:"transparency_score"`
 	GovernanceScore      float64   `json:"governance_score" db:"governance_score"`
 	ConfidenceLevel      string    `json:"confidence_level" db:"confidence_level"`
+	CompletenessScore    float64   `json:"completeness_score" db:"completeness_score"`
+	ScoringVersion       int       `json:"scoring_version" db:"scoring_version"`
 	LastCalculated       time.Time `json:"last_calculated" db:"last_calculated"`
 }
 
@@ -86,3 +120,48 @@ type AnnualReturnHistory struct {
 	DateOfExtract            *time.Time `json:"date_of_extract" db:"date_of_extract"`
 	CreatedAt                time.Time  `json:"created_at" db:"created_at"`
 }
+
+// Classification represents a what/who/how classification code for a charity
+type Classification struct {
+	CharityNumber int       `json:"charity_number" db:"registered_charity_number"`
+	Code          string    `json:"code" db:"classification_code"`
+	Type          string    `json:"type" db:"classification_type"`
+	Description   string    `json:"description" db:"classification_description"`
+	LastUpdated   time.Time `json:"last_updated" db:"last_updated"`
+}
+
+// Category represents a classification code aggregated across all charities
+type Category struct {
+	Code         string `json:"code"`
+	Type         string `json:"type"`
+	Description  string `json:"description"`
+	CharityCount int    `json:"charity_count"`
+}
+
+// CharityRemoval records why a charity was removed from the register, from the
+// charity event history extract. The successor is the charity its funds or
+// activities passed to, if any (e.g. on an amalgamation).
+type CharityRemoval struct {
+	CharityNumber          int        `json:"charity_number" db:"charity_number"`
+	Reason                 string     `json:"reason" db:"reason"`
+	DateRemoved            *time.Time `json:"date_removed" db:"date_removed"`
+	SuccessorCharityNumber int        `json:"successor_charity_number,omitempty" db:"successor_charity_number"`
+	SuccessorName          string     `json:"successor_name,omitempty" db:"successor_name"`
+}
+
+// Sources a section of a charity record can come from
+const (
+	SourceRegisterExtract  = "register_extract"  // The bulk charity or trustee extract
+	SourceAnnualReturn     = "annual_return"     // The annual return Part B extract
+	SourceAPI              = "api"               // The live Charity Commission API
+	SourceFinancialHistory = "financial_history" // The live API, with the breakdown from its financial history
+)
+
+// DataSources records which source last populated each section of a charity
+// record. A section is empty when its source isn't known, e.g. data stored before
+// sources were tracked.
+type DataSources struct {
+	Charity    string `json:"charity,omitempty"`
+	Financials string `json:"financials,omitempty"`
+	Trustees   string `json:"trustees,omitempty"`
+}
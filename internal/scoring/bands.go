@@ -0,0 +1,49 @@
+package scoring
+
+import "charitylens/internal/models"
+
+// IncomeBandLimits are the upper bounds of the Charity Commission's register
+// income bands, used to group charities of a comparable size. The top band, over
+// the last limit, is open.
+var IncomeBandLimits = []float64{10_000, 100_000, 500_000, 1_000_000, 5_000_000, 10_000_000}
+
+// IncomeBand returns the index of the band containing income, from 0 for the
+// lowest band to len(IncomeBandLimits) for the open top band
+func IncomeBand(income float64) int {
+	for i, limit := range IncomeBandLimits {
+		if income < limit {
+			return i
+		}
+	}
+	return len(IncomeBandLimits)
+}
+
+// GovernanceTrusteesByBand is how many trustees earn the full governance score in
+// each income band, indexed as IncomeBand returns. A board should grow with the
+// money it oversees, but the legal minimum of three stays the bar up to £500k:
+//
+//	Under £10k        3
+//	£10k to £100k     3
+//	£100k to £500k    3
+//	£500k to £1m      4
+//	£1m to £5m        5
+//	£5m to £10m       6
+//	Over £10m         7
+var GovernanceTrusteesByBand = []int{
+	FullGovernanceTrustees,
+	FullGovernanceTrustees,
+	FullGovernanceTrustees,
+	4,
+	5,
+	6,
+	7,
+}
+
+// governanceTrustees returns how many trustees earn the full governance score for
+// a charity. fin is its latest financial year, or nil if there is none.
+func governanceTrustees(fin *models.Financial) int {
+	if fin == nil {
+		return FullGovernanceTrustees
+	}
+	return GovernanceTrusteesByBand[IncomeBand(fin.TotalIncome)]
+}
@@ -0,0 +1,84 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"charitylens/internal/models"
+)
+
+func TestIncomeBand(t *testing.T) {
+	tests := []struct {
+		income float64
+		want   int
+	}{
+		{0, 0},
+		{9_999, 0},
+		{10_000, 1},
+		{499_999, 2},
+		{500_000, 3},
+		{1_000_000, 4},
+		{9_999_999, 5},
+		{10_000_000, 6},
+		{250_000_000, 6},
+	}
+
+	for _, tt := range tests {
+		if got := IncomeBand(tt.income); got != tt.want {
+			t.Errorf("IncomeBand(%v) = %d, want %d", tt.income, got, tt.want)
+		}
+	}
+}
+
+func TestGovernanceTrustees(t *testing.T) {
+	tests := []struct {
+		name string
+		fin  *models.Financial
+		want int
+	}{
+		{"no financial data", nil, FullGovernanceTrustees},
+		{"under £10k", &models.Financial{TotalIncome: 5_000}, 3},
+		{"£100k to £500k", &models.Financial{TotalIncome: 250_000}, 3},
+		{"£500k to £1m", &models.Financial{TotalIncome: 750_000}, 4},
+		{"£1m to £5m", &models.Financial{TotalIncome: 2_000_000}, 5},
+		{"£5m to £10m", &models.Financial{TotalIncome: 7_500_000}, 6},
+		{"over £10m", &models.Financial{TotalIncome: 50_000_000}, 7},
+	}
+
+	for _, tt := range tests {
+		if got := governanceTrustees(tt.fin); got != tt.want {
+			t.Errorf("%s: governanceTrustees = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestGovernanceLargeCharityFewTrustees checks a large charity with the legal
+// minimum of three trustees no longer earns the full governance score, as it did
+// when three was the bar for every charity, while a small one still does
+func TestGovernanceLargeCharityFewTrustees(t *testing.T) {
+	useNeutrals(t, DefaultNeutrals())
+	charity := models.Charity{RegisteredNumber: 1000, Website: "https://alpha.example", LastUpdated: time.Now()}
+
+	// The governance score when three trustees earned it in full
+	const before = 100.0
+
+	small := ScoreFromInputs(charity, &models.Financial{TotalIncome: 50_000, TotalSpending: 40_000}, 3, fullFiling)
+	if small.GovernanceScore != before {
+		t.Errorf("small charity governance = %v, want %v", small.GovernanceScore, before)
+	}
+
+	large := ScoreFromInputs(charity, &models.Financial{TotalIncome: 50_000_000, TotalSpending: 40_000}, 3, fullFiling)
+	if want := 3.0 / 7 * 100; math.Abs(large.GovernanceScore-want) > 1e-9 {
+		t.Errorf("large charity governance = %v, want %v", large.GovernanceScore, want)
+	}
+	if large.GovernanceScore >= before || large.OverallScore >= small.OverallScore {
+		t.Errorf("large charity with 3 trustees scored governance %v, overall %v; want below %v and %v",
+			large.GovernanceScore, large.OverallScore, before, small.OverallScore)
+	}
+
+	full := ScoreFromInputs(charity, &models.Financial{TotalIncome: 50_000_000, TotalSpending: 40_000}, 7, fullFiling)
+	if full.GovernanceScore != before {
+		t.Errorf("large charity with 7 trustees governance = %v, want %v", full.GovernanceScore, before)
+	}
+}
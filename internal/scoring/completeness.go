@@ -0,0 +1,40 @@
+package scoring
+
+import "charitylens/internal/models"
+
+// CompletenessDimensions are the kinds of data the completeness score looks for,
+// each worth an equal share of it:
+//
+//	contact     an address, email or phone number
+//	financials  a financial year
+//	breakdown   that year's spending split into charitable activities and the rest
+//	trustees    at least one trustee
+//	filings     annual return filing history
+//	website     a website
+var CompletenessDimensions = []string{"contact", "financials", "breakdown", "trustees", "filings", "website"}
+
+// completenessScore returns how much of the data a charity is scored on is on
+// record, from 0 to 100. Unlike the subscores it says nothing about how well the
+// charity is run: a low score means a low overall score is down to missing data
+// rather than poor results. fin is the latest financial year, or nil if there is
+// none.
+func completenessScore(charity models.Charity, fin *models.Financial, trusteeCount int, filing FilingStats) float64 {
+	present := map[string]bool{
+		"contact":    charity.Address != "" || charity.Email != "" || charity.Phone != "",
+		"financials": fin != nil,
+		"trustees":   trusteeCount > 0,
+		"filings":    filing.Filings > 0,
+		"website":    charity.Website != "",
+	}
+	if fin != nil {
+		_, present["breakdown"] = charitableSpendRatio(*fin)
+	}
+
+	count := 0
+	for _, dimension := range CompletenessDimensions {
+		if present[dimension] {
+			count++
+		}
+	}
+	return float64(count) / float64(len(CompletenessDimensions)) * 100
+}
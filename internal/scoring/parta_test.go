@@ -0,0 +1,69 @@
+package scoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"charitylens/internal/models"
+)
+
+func TestLoadPartA(t *testing.T) {
+	db := openTestDB(t)
+	yearEnd := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	if _, err := db.Exec(`
+		INSERT INTO annual_return_parta (charity_number, financial_year_end, total_gross_income,
+		                                 raises_funds_from_public, has_trading_subsidiary)
+		VALUES (1000, ?, 120000, 0, 1), (2000, ?, NULL, NULL, NULL)
+	`, yearEnd, yearEnd); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		number  int
+		yearEnd time.Time
+		want    *models.AnnualReturnPartA
+	}{
+		{
+			name:    "answered",
+			number:  1000,
+			yearEnd: yearEnd,
+			want:    &models.AnnualReturnPartA{TotalGrossIncome: 120_000, HasTradingSubsidiary: true},
+		},
+		{
+			// Unanswered questions count against the trading adjustment
+			name:    "unanswered",
+			number:  2000,
+			yearEnd: yearEnd,
+			want:    &models.AnnualReturnPartA{RaisesFundsFromPublic: true},
+		},
+		{name: "other year", number: 1000, yearEnd: yearEnd.AddDate(-1, 0, 0)},
+		{name: "no part a", number: 3000, yearEnd: yearEnd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fin := models.Financial{CharityNumber: tt.number, FinancialYearEnd: tt.yearEnd}
+			if err := LoadPartA(context.Background(), db, &fin); err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil {
+				if fin.PartA != nil {
+					t.Errorf("PartA = %+v, want nil", *fin.PartA)
+				}
+				return
+			}
+			if fin.PartA == nil {
+				t.Fatal("PartA not set")
+			}
+			got := *fin.PartA
+			if got.TotalGrossIncome != tt.want.TotalGrossIncome ||
+				got.RaisesFundsFromPublic != tt.want.RaisesFundsFromPublic ||
+				got.HasTradingSubsidiary != tt.want.HasTradingSubsidiary {
+				t.Errorf("PartA = %+v, want %+v", got, *tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package scoring
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"charitylens/internal/database"
+	"charitylens/internal/models"
+)
+
+// openTestDB returns a migrated SQLite database in a temporary directory
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	t.Setenv("DATABASE_TYPE", "sqlite")
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.MigrateWithPath(db, "../../migrations"); err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+	return db
+}
+
+func TestResyncAsRemoved(t *testing.T) {
+	db := openTestDB(t)
+	charity := models.Charity{
+		RegisteredNumber: 1000,
+		Name:             "Alpha Trust",
+		Status:           "Registered",
+		DateRegistered:   time.Date(2001, 5, 1, 0, 0, 0, 0, time.UTC),
+		Website:          "https://alpha.example",
+		LastUpdated:      time.Now(),
+	}
+
+	// resync stores the charity as the sync does, then checks there's still only
+	// one row for it and returns its status and removal date
+	resync := func(charity models.Charity) (string, sql.NullTime) {
+		t.Helper()
+		if err := database.UpsertCharity(db, charity); err != nil {
+			t.Fatalf("upserting charity: %v", err)
+		}
+		var rows int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM charities WHERE registered_number = 1000`).Scan(&rows); err != nil {
+			t.Fatal(err)
+		}
+		if rows != 1 {
+			t.Fatalf("got %d rows for the charity, want 1", rows)
+		}
+		var status string
+		var dateRemoved sql.NullTime
+		if err := db.QueryRow(`
+			SELECT status, date_removed FROM charities WHERE registered_number = 1000
+		`).Scan(&status, &dateRemoved); err != nil {
+			t.Fatalf("loading charity: %v", err)
+		}
+		return status, dateRemoved
+	}
+	scoreRows := func() int {
+		t.Helper()
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM charity_scores WHERE charity_number = 1000`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// First sync inserts the charity, and it's scored
+	if status, dateRemoved := resync(charity); status != "Registered" || dateRemoved.Valid {
+		t.Fatalf("after first sync status = %q, date_removed = %v; want Registered and no date", status, dateRemoved)
+	}
+	if _, err := CalculateScore(db, 1000); err != nil {
+		t.Fatalf("scoring registered charity: %v", err)
+	}
+	if n := scoreRows(); n != 1 {
+		t.Fatalf("got %d cached scores, want 1", n)
+	}
+
+	// A re-sync finds it removed: the row is updated in place, and scoring drops
+	// the cached score
+	removed := charity
+	removedOn := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	removed.Status = "Removed"
+	removed.DateRemoved = &removedOn
+	status, dateRemoved := resync(removed)
+	if status != "Removed" || !dateRemoved.Valid || !dateRemoved.Time.Equal(removedOn) {
+		t.Fatalf("after removal status = %q, date_removed = %v; want Removed on %v", status, dateRemoved, removedOn)
+	}
+	if _, err := CalculateScore(db, 1000); !errors.Is(err, ErrCharityRemoved) {
+		t.Fatalf("scoring removed charity: got %v, want %v", err, ErrCharityRemoved)
+	}
+	if n := scoreRows(); n != 0 {
+		t.Errorf("got %d cached scores after removal, want 0", n)
+	}
+
+	// Reinstatement clears the removal date, and the charity is scored again
+	if status, dateRemoved := resync(charity); status != "Registered" || dateRemoved.Valid {
+		t.Fatalf("after reinstatement status = %q, date_removed = %v; want Registered and no date", status, dateRemoved)
+	}
+	if _, err := CalculateScore(db, 1000); err != nil {
+		t.Fatalf("scoring reinstated charity: %v", err)
+	}
+	if n := scoreRows(); n != 1 {
+		t.Errorf("got %d cached scores after reinstatement, want 1", n)
+	}
+}
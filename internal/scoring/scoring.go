@@ -1,15 +1,140 @@
 package scoring
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"math"
 	"time"
 
+	"charitylens/internal/database"
 	"charitylens/internal/models"
 )
 
+// ErrCharityRemoved is returned by CalculateScore for charities that have been
+// removed from the register, which are not scored.
+var ErrCharityRemoved = errors.New("charity has been removed from the register")
+
+// ScoringVersion identifies the scoring formula. Bump it whenever the calculation
+// changes so scores cached by an older version are recalculated.
+const ScoringVersion = 5
+
+// Default neutral scores (0-100), used for a component that can't be calculated
+// because the data behind it wasn't reported, so a charity isn't penalised for
+// what's missing. They're policy choices rather than measurements, and can be
+// overridden with SetNeutrals.
+const (
+	// NeutralEfficiency is the efficiency score when spending is reported without
+	// a breakdown of charitable activities spend, or no spending is reported
+	NeutralEfficiency = 60
+
+	// NeutralFinancialHealth is the financial health score when spending is
+	// reported without reserves or assets, or no spending is reported
+	NeutralFinancialHealth = 50
+
+	// NeutralFiling is the filing timeliness and consistency score when there's
+	// no annual return history, e.g. for a newly registered charity
+	NeutralFiling = 50
+
+	// NeutralAccountsQuality is the accounts quality score when there's no record
+	// of whether recent accounts were qualified, giving the benefit of the doubt
+	NeutralAccountsQuality = 100
+
+	// NeutralWebsite is the web presence score of a charity without a website
+	// whose latest income is below the website income limit, since tiny local
+	// charities often legitimately operate without one. It only applies once a
+	// limit is set; by default a missing website scores 0 whatever the income.
+	NeutralWebsite = 50
+)
+
+// Neutrals holds the neutral scores in use
+type Neutrals struct {
+	Efficiency      float64
+	FinancialHealth float64
+	Filing          float64
+	AccountsQuality float64
+
+	// Website is the web presence score of a charity without a website whose
+	// latest income is below WebsiteIncomeLimit (0 = no limit, so no charity)
+	Website            float64
+	WebsiteIncomeLimit float64
+}
+
+// DefaultNeutrals returns the Neutral* constants
+func DefaultNeutrals() Neutrals {
+	return Neutrals{
+		Efficiency:      NeutralEfficiency,
+		FinancialHealth: NeutralFinancialHealth,
+		Filing:          NeutralFiling,
+		AccountsQuality: NeutralAccountsQuality,
+		Website:         NeutralWebsite,
+	}
+}
+
+var neutrals = DefaultNeutrals()
+
+// SetNeutrals replaces the neutral scores, clamping each to 0-100. Call it at
+// startup, before any scores are calculated. Scores already stored were calculated
+// with the old values and stay as they are until recalculated.
+func SetNeutrals(n Neutrals) {
+	clamp := func(v float64) float64 { return math.Max(0, math.Min(100, v)) }
+	neutrals = Neutrals{
+		Efficiency:      clamp(n.Efficiency),
+		FinancialHealth: clamp(n.FinancialHealth),
+		Filing:          clamp(n.Filing),
+		AccountsQuality: clamp(n.AccountsQuality),
+
+		Website:            clamp(n.Website),
+		WebsiteIncomeLimit: math.Max(0, n.WebsiteIncomeLimit),
+	}
+}
+
+// CurrentNeutrals returns the neutral scores in use
+func CurrentNeutrals() Neutrals {
+	return neutrals
+}
+
+// Weights of each subscore in the overall score, summing to 1
+const (
+	WeightEfficiency      = 0.4
+	WeightFinancialHealth = 0.3
+	WeightTransparency    = 0.2
+	WeightGovernance      = 0.1
+)
+
+// Points each component contributes to the transparency score, out of 100. The
+// filing components are 0-100 scores scaled down to their points.
+const (
+	TransparencyWebsitePoints           = 30
+	TransparencyFinancialsPoints        = 20
+	TransparencyTrusteesPoints          = 10
+	TransparencyFilingTimelinessPoints  = 25
+	TransparencyFilingConsistencyPoints = 10
+	TransparencyAccountsQualityPoints   = 5
+)
+
+// Thresholds behind the financial health and governance scores
+const (
+	// MinReserveMonths and MaxReserveMonths bound the optimal reserves, which
+	// score 100. Fewer months scale down to 0; more lose up to 30 points.
+	MinReserveMonths = 3
+	MaxReserveMonths = 12
+
+	// FullGovernanceTrustees is how many trustees earn the full governance score
+	// in the smallest income bands, or without financial data. Larger charities
+	// are expected to have more; see GovernanceTrusteesByBand.
+	FullGovernanceTrustees = 3
+)
+
 func CalculateScore(db *sql.DB, charityNumber int, cacheScore ...bool) (models.CharityScore, error) {
+	return CalculateScoreContext(context.Background(), db, charityNumber, cacheScore...)
+}
+
+// CalculateScoreContext is CalculateScore bounded by ctx. If ctx ends part way
+// through, ctx.Err() is returned and nothing is stored, rather than a score
+// calculated from partly loaded data.
+func CalculateScoreContext(ctx context.Context, db *sql.DB, charityNumber int, cacheScore ...bool) (models.CharityScore, error) {
 	// cacheScore is optional - defaults to true for backwards compatibility
 	shouldCache := true
 	if len(cacheScore) > 0 {
@@ -17,82 +142,168 @@ func CalculateScore(db *sql.DB, charityNumber int, cacheScore ...bool) (models.C
 	}
 	score := models.CharityScore{
 		CharityNumber:  charityNumber,
+		ScoringVersion: ScoringVersion,
 		LastCalculated: time.Now(),
 	}
 
 	// Get charity info (main charity only)
 	var charity models.Charity
-	var website sql.NullString
+	var website, status, address, email, phone sql.NullString
 	var lastUpdated sql.NullTime
-	err := db.QueryRow(`
-		SELECT registered_number, name, website, last_updated
+	err := db.QueryRowContext(ctx, `
+		SELECT registered_number, name, website, status, address, email, phone, last_updated
 		FROM charities WHERE registered_number = ? AND linked_charity_number = 0
-	`, charityNumber).Scan(&charity.RegisteredNumber, &charity.Name, &website, &lastUpdated)
+	`, charityNumber).Scan(&charity.RegisteredNumber, &charity.Name, &website, &status,
+		&address, &email, &phone, &lastUpdated)
 	if err != nil {
 		return score, err
 	}
 
-	// Convert NullString to string
-	if website.Valid {
-		charity.Website = website.String
+	// Removed charities aren't scored; drop any score cached before the removal so
+	// it doesn't linger in rankings and stats
+	if database.IsRemoved(status.String) {
+		if shouldCache {
+			database.WithWriteLock(db, func() error {
+				_, err := database.ExecRetryContext(ctx, db, `DELETE FROM charity_scores WHERE charity_number = ?`, charityNumber)
+				return err
+			})
+		}
+		return score, ErrCharityRemoved
 	}
+
+	// Convert NullString to string
+	charity.Website = website.String
+	charity.Address = address.String
+	charity.Email = email.String
+	charity.Phone = phone.String
 	if lastUpdated.Valid {
 		charity.LastUpdated = lastUpdated.Time
 	}
 
 	// Get latest financial data
 	var fin models.Financial
-	err = db.QueryRow(`
-		SELECT total_income, total_spending, charitable_activities_spend, reserves, assets, COALESCE(trustees, 0)
+	err = db.QueryRowContext(ctx, `
+		SELECT financial_year_end, total_income, total_spending, charitable_activities_spend,
+		       COALESCE(raising_funds_spend, 0), reserves, assets
 		FROM financials WHERE charity_number = ?
 		ORDER BY financial_year_end DESC LIMIT 1
-	`, charityNumber).Scan(&fin.TotalIncome, &fin.TotalSpending, &fin.CharitableActivitiesSpend, &fin.Reserves, &fin.Assets, &fin.Trustees)
+	`, charityNumber).Scan(&fin.FinancialYearEnd, &fin.TotalIncome, &fin.TotalSpending, &fin.CharitableActivitiesSpend,
+		&fin.RaisingFundsSpend, &fin.Reserves, &fin.Assets)
 	hasFinancial := err == nil
+	if hasFinancial {
+		fin.CharityNumber = charityNumber
+		if err := LoadPartA(ctx, db, &fin); err != nil {
+			log.Printf("Failed to load annual return Part A for charity %d: %v", charityNumber, err)
+		}
+	}
 
-	// Get trustee count
+	// Get trustee count. The trustees table is the only source for it: the
+	// financials.trustees column isn't kept in step with it, so it's never read,
+	// and API-synced and file-imported charities are scored alike.
 	var trusteeCount int
-	db.QueryRow(`
+	db.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM trustees WHERE charity_number = ?
 	`, charityNumber).Scan(&trusteeCount)
 
-	// Calculate Efficiency Score (40%)
+	filing := FilingStats{
+		Timeliness:      calculateFilingTimeliness(ctx, db, charityNumber),
+		Consistency:     calculateFilingConsistency(ctx, db, charityNumber),
+		AccountsQuality: calculateAccountsQuality(ctx, db, charityNumber),
+	}
+	db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM annual_return_history WHERE registered_charity_number = ?
+	`, charityNumber).Scan(&filing.Filings)
+
+	// The lookups above fall back to neutral values on error, so a cancelled or
+	// timed-out calculation has to be caught here before it's stored
+	if err := ctx.Err(); err != nil {
+		return score, err
+	}
+
+	var financial *models.Financial
+	if hasFinancial {
+		financial = &fin
+	}
+	score = ScoreFromInputs(charity, financial, trusteeCount, filing)
+
+	// Store the score in the database (unless caching is disabled). Writes are
+	// serialized on SQLite so concurrent score calculations don't fight over its
+	// single write lock.
+	if shouldCache {
+		err = database.WithWriteLock(db, func() error {
+			_, err := database.ExecRetryContext(ctx, db, `
+				INSERT OR REPLACE INTO charity_scores
+				(charity_number, overall_score, efficiency_score, financial_health_score, transparency_score, governance_score, confidence_level, completeness_score, scoring_version, last_calculated)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				score.CharityNumber, score.OverallScore, score.EfficiencyScore, score.FinancialHealthScore,
+				score.TransparencyScore, score.GovernanceScore, score.ConfidenceLevel, score.CompletenessScore,
+				score.ScoringVersion, score.LastCalculated)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to store score for charity %d: %v", charityNumber, err)
+			return score, err
+		}
+	}
+
+	return score, nil
+}
+
+// FilingStats holds the filing history scores (each 0-100) that feed the
+// transparency score, and how much history they were calculated from.
+type FilingStats struct {
+	Timeliness      float64 // Annual returns filed on time in the last 3 years
+	Consistency     float64 // No gaps in filing over the last 5 years
+	AccountsQuality float64 // No qualified accounts in recent years
+	Filings         int     // Annual returns on record; the scores are neutral without any
+}
+
+// ScoreFromInputs calculates a charity's score from already-loaded data, without
+// touching the database. fin is the latest financial year, or nil if there is
+// none.
+func ScoreFromInputs(charity models.Charity, fin *models.Financial, trusteeCount int, filing FilingStats) models.CharityScore {
+	score := models.CharityScore{
+		CharityNumber:  charity.RegisteredNumber,
+		ScoringVersion: ScoringVersion,
+		LastCalculated: time.Now(),
+	}
+
+	// A year with income but no spending, or neither, is common for new and
+	// dormant charities. Efficiency and financial health are both measured
+	// against spending, so there's too little data to score them rather than a
+	// failing result. Spending without income is scored as usual, since neither
+	// component looks at income.
+	hasSpending := fin != nil && fin.TotalSpending > 0
+
+	// Calculate Efficiency Score (WeightEfficiency)
 	var efficiencyScore float64
-	hasSpendingBreakdown := hasFinancial && fin.CharitableActivitiesSpend > 0
-	if hasSpendingBreakdown && fin.TotalSpending > 0 {
-		ratio := fin.CharitableActivitiesSpend / fin.TotalSpending
-		efficiencyScore = math.Min(100, ratio*100)
-	} else if hasFinancial && fin.TotalSpending > 0 {
-		// No spending breakdown available - use neutral score
-		// Don't penalize charities for missing data
-		efficiencyScore = 60 // Neutral/average score when data unavailable
+	if fin != nil {
+		if ratio, ok := charitableSpendRatio(*fin); ok {
+			efficiencyScore = math.Min(100, ratio*100)
+		} else {
+			// No spending breakdown, or no spending - use neutral score
+			// Don't penalize charities for missing data
+			efficiencyScore = neutrals.Efficiency
+		}
 	}
 	score.EfficiencyScore = efficiencyScore
 
-	// Calculate Financial Health Score (30%)
+	// Calculate Financial Health Score (WeightFinancialHealth)
 	var financialHealthScore float64
-	if hasFinancial && fin.TotalSpending > 0 {
-		monthlySpending := fin.TotalSpending / 12
-
+	if hasSpending {
 		// Check if we have valid reserves data
-		if fin.Reserves > 0 || fin.Assets > 0 {
-			// Use reserves if available, otherwise use assets as proxy
-			reserves := fin.Reserves
-			if reserves == 0 && fin.Assets > 0 {
-				reserves = fin.Assets
-			}
-
-			reserveMonths := reserves / monthlySpending
-			if reserveMonths >= 3 && reserveMonths <= 12 {
+		if reserveMonths, ok := reserveMonths(*fin); ok {
+			if reserveMonths >= MinReserveMonths && reserveMonths <= MaxReserveMonths {
 				// Optimal range: 3-12 months of reserves
 				financialHealthScore = 100
-			} else if reserveMonths < 3 {
+			} else if reserveMonths < MinReserveMonths {
 				// Too few reserves: scale from 0-100
-				financialHealthScore = (reserveMonths / 3) * 100
+				financialHealthScore = (reserveMonths / MinReserveMonths) * 100
 			} else {
 				// More than 12 months: still good, just cap the penalty
 				// Having extra reserves isn't as bad as having too few
 				// Gentle penalty: 100 at 12mo, 90 at 24mo, 80 at 36mo, floor at 70
-				excessMonths := reserveMonths - 12
+				excessMonths := reserveMonths - MaxReserveMonths
 				penalty := math.Min(30, (excessMonths/12)*5) // Max 30 point penalty
 				financialHealthScore = math.Max(70, 100-penalty)
 			}
@@ -100,60 +311,66 @@ func CalculateScore(db *sql.DB, charityNumber int, cacheScore ...bool) (models.C
 			// No reserves/assets data available - use neutral score
 			// Don't penalize charities for missing financial data
 			// New or small charities may not have detailed reserves reporting
-			financialHealthScore = 50 // Neutral score when reserves data unavailable
+			financialHealthScore = neutrals.FinancialHealth
 		}
+	} else if fin != nil {
+		// No spending to measure reserves against - use neutral score
+		financialHealthScore = neutrals.FinancialHealth
 	}
 	score.FinancialHealthScore = financialHealthScore
 
-	// Calculate Transparency Score (20%) - Enhanced with filing history
+	// Calculate Transparency Score (WeightTransparency) - Enhanced with filing history
 	transparencyScore := 0.0
 
-	// Website presence (30 points)
+	// Website presence, with a neutral score for a small charity without one
 	if charity.Website != "" {
-		transparencyScore += 30
+		transparencyScore += TransparencyWebsitePoints
+	} else if fin != nil && fin.TotalIncome < neutrals.WebsiteIncomeLimit {
+		transparencyScore += neutrals.Website * (TransparencyWebsitePoints / 100.0)
 	}
 
-	// Has current financial data (20 points)
-	if hasFinancial {
-		transparencyScore += 20
+	// Has current financial data
+	if fin != nil {
+		transparencyScore += TransparencyFinancialsPoints
 	}
 
-	// Has trustees listed (10 points)
+	// Has trustees listed
 	if trusteeCount > 0 {
-		transparencyScore += 10
+		transparencyScore += TransparencyTrusteesPoints
 	}
 
-	// Filing timeliness - last 3 years (25 points)
+	// Filing timeliness - last 3 years
 	// Check if annual returns were filed on time
-	filingScore := calculateFilingTimeliness(db, charityNumber)
-	transparencyScore += filingScore * 0.25 // Scale 0-100 to 0-25
+	transparencyScore += filing.Timeliness * (TransparencyFilingTimelinessPoints / 100.0)
 
-	// Filing consistency - no gaps in last 5 years (10 points)
-	consistencyScore := calculateFilingConsistency(db, charityNumber)
-	transparencyScore += consistencyScore * 0.10 // Scale 0-100 to 0-10
+	// Filing consistency - no gaps in last 5 years
+	transparencyScore += filing.Consistency * (TransparencyFilingConsistencyPoints / 100.0)
 
-	// Accounts quality - no qualified accounts (5 points)
-	qualityScore := calculateAccountsQuality(db, charityNumber)
-	transparencyScore += qualityScore * 0.05 // Scale 0-100 to 0-5
+	// Accounts quality - no qualified accounts
+	transparencyScore += filing.AccountsQuality * (TransparencyAccountsQualityPoints / 100.0)
 
 	score.TransparencyScore = transparencyScore
 
-	// Calculate Governance Score (10%)
+	// Calculate Governance Score (WeightGovernance), expecting a larger board the
+	// larger the charity's income
 	governanceScore := 0.0
-	if trusteeCount >= 3 {
+	expectedTrustees := governanceTrustees(fin)
+	if trusteeCount >= expectedTrustees {
 		governanceScore = 100
 	} else if trusteeCount > 0 {
-		governanceScore = float64(trusteeCount) / 3 * 100
+		governanceScore = float64(trusteeCount) / float64(expectedTrustees) * 100
 	}
 	score.GovernanceScore = governanceScore
 
 	// Overall Score
-	score.OverallScore = (efficiencyScore*0.4 + financialHealthScore*0.3 + transparencyScore*0.2 + governanceScore*0.1)
+	score.OverallScore = (efficiencyScore*WeightEfficiency + financialHealthScore*WeightFinancialHealth +
+		transparencyScore*WeightTransparency + governanceScore*WeightGovernance)
 
 	// Confidence Level
 	confidence := "high"
 	dataCompleteness := 0
-	if hasFinancial {
+	// A year without spending only scored neutrally, so it adds no confidence
+	if hasSpending {
 		dataCompleteness += 1
 	}
 	if charity.Website != "" {
@@ -162,7 +379,7 @@ func CalculateScore(db *sql.DB, charityNumber int, cacheScore ...bool) (models.C
 	if trusteeCount > 0 {
 		dataCompleteness += 1
 	}
-	if time.Since(charity.LastUpdated) > 365*24*time.Hour {
+	if score.LastCalculated.Sub(charity.LastUpdated) > 365*24*time.Hour {
 		dataCompleteness -= 1
 	}
 	if dataCompleteness >= 2 {
@@ -174,28 +391,116 @@ func CalculateScore(db *sql.DB, charityNumber int, cacheScore ...bool) (models.C
 	}
 	score.ConfidenceLevel = confidence
 
-	// Store the score in the database (unless caching is disabled)
-	if shouldCache {
-		_, err = db.Exec(`
-			INSERT OR REPLACE INTO charity_scores
-			(charity_number, overall_score, efficiency_score, financial_health_score, transparency_score, governance_score, confidence_level, last_calculated)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-			score.CharityNumber, score.OverallScore, score.EfficiencyScore, score.FinancialHealthScore,
-			score.TransparencyScore, score.GovernanceScore, score.ConfidenceLevel, score.LastCalculated)
-		if err != nil {
-			log.Printf("Failed to store score for charity %d: %v", charityNumber, err)
-			return score, err
-		}
+	// Data completeness, independent of the quality of the data
+	score.CompletenessScore = completenessScore(charity, fin, trusteeCount, filing)
+
+	return score
+}
+
+// FinancialMetrics derives the raw financial ratios behind the efficiency and
+// financial health scores, using the same calculations as CalculateScore.
+// Ratios that can't be calculated (e.g. zero spending) are left nil.
+func FinancialMetrics(fin models.Financial) models.FinancialMetrics {
+	var metrics models.FinancialMetrics
+
+	if ratio, ok := charitableSpendRatio(fin); ok {
+		metrics.CharitableSpendRatio = &ratio
+		metrics.TradingCostsExcluded = tradingCostsExcluded(fin)
 	}
 
-	return score, nil
+	// Fundraising cost per pound of income
+	if fin.RaisingFundsSpend > 0 && fin.TotalIncome > 0 {
+		ratio := fin.RaisingFundsSpend / fin.TotalIncome
+		metrics.FundraisingCostRatio = &ratio
+	}
+
+	if months, ok := reserveMonths(fin); ok {
+		metrics.ReserveMonths = &months
+	}
+
+	// Admin overhead is whatever isn't spent on charitable activities or fundraising,
+	// so it needs the spending breakdown
+	if fin.CharitableActivitiesSpend > 0 && fin.TotalSpending > 0 {
+		overhead := fin.TotalSpending - fin.CharitableActivitiesSpend - fin.RaisingFundsSpend
+		pct := math.Max(0, overhead/fin.TotalSpending*100)
+		metrics.AdminOverheadPct = &pct
+	}
+
+	return metrics
+}
+
+// charitableSpendRatio returns the share of spending that went on charitable
+// activities, leaving out trading costs where tradingCostsExcluded says so. ok is
+// false when there is no spending breakdown.
+func charitableSpendRatio(fin models.Financial) (float64, bool) {
+	if fin.CharitableActivitiesSpend <= 0 || fin.TotalSpending <= 0 {
+		return 0, false
+	}
+	spending := fin.TotalSpending
+	if tradingCostsExcluded(fin) {
+		spending -= fin.RaisingFundsSpend
+	}
+	return fin.CharitableActivitiesSpend / spending, true
+}
+
+// tradingCostsExcluded reports whether a year's raising funds spend is left out
+// of its charitable spend ratio as the cost of trading. That's when annual return
+// Part A says the charity has a trading subsidiary and doesn't raise funds from
+// the public: with no donations to raise, its raising funds spend is what it
+// costs to earn its trading income, such as a shop's stock, rather than overhead
+// paid out of donations. Without Part A, or when the charity also raises funds
+// from the public, the two can't be told apart and nothing is left out.
+func tradingCostsExcluded(fin models.Financial) bool {
+	return fin.PartA != nil && fin.PartA.HasTradingSubsidiary && !fin.PartA.RaisesFundsFromPublic &&
+		fin.RaisingFundsSpend > 0 && fin.RaisingFundsSpend < fin.TotalSpending
+}
+
+// LoadPartA sets fin.PartA from annual return Part A for the same charity and
+// financial year, leaving it nil if there's none. The year end is matched
+// exactly: Part A and Part B parse the extracts' period end dates the same way.
+// A question the charity didn't answer counts against the trading adjustment:
+// it's taken to raise funds from the public and have no trading subsidiary.
+func LoadPartA(ctx context.Context, db *sql.DB, fin *models.Financial) error {
+	partA := models.AnnualReturnPartA{CharityNumber: fin.CharityNumber, FinancialYearEnd: fin.FinancialYearEnd}
+	err := db.QueryRowContext(ctx, `
+		SELECT COALESCE(total_gross_income, 0), COALESCE(raises_funds_from_public, 1),
+		       COALESCE(has_trading_subsidiary, 0)
+		FROM annual_return_parta
+		WHERE charity_number = ? AND financial_year_end = ?
+	`, fin.CharityNumber, fin.FinancialYearEnd.UTC()).Scan(
+		&partA.TotalGrossIncome, &partA.RaisesFundsFromPublic, &partA.HasTradingSubsidiary,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fin.PartA = &partA
+	return nil
+}
+
+// reserveMonths returns how many months of spending the reserves would cover,
+// using assets as a proxy when reserves aren't reported. ok is false when there
+// is no spending or no reserves/assets data.
+func reserveMonths(fin models.Financial) (float64, bool) {
+	if fin.TotalSpending <= 0 || !(fin.Reserves > 0 || fin.Assets > 0) {
+		return 0, false
+	}
+
+	// Use reserves if available, otherwise use assets as proxy
+	reserves := fin.Reserves
+	if reserves == 0 {
+		reserves = fin.Assets
+	}
+	return reserves / (fin.TotalSpending / 12), true
 }
 
 // calculateFilingTimeliness checks if annual returns were filed on time in the last 3 years
 // Returns a score from 0-100
-func calculateFilingTimeliness(db *sql.DB, charityNumber int) float64 {
+func calculateFilingTimeliness(ctx context.Context, db *sql.DB, charityNumber int) float64 {
 	// Get the last 3 filing records
-	rows, err := db.Query(`
+	rows, err := db.QueryContext(ctx, `
 		SELECT reporting_due_date, date_annual_return_received, date_accounts_received
 		FROM annual_return_history
 		WHERE registered_charity_number = ?
@@ -204,7 +509,7 @@ func calculateFilingTimeliness(db *sql.DB, charityNumber int) float64 {
 		LIMIT 3
 	`, charityNumber)
 	if err != nil {
-		return 50 // Neutral score if no data
+		return neutrals.Filing
 	}
 	defer rows.Close()
 
@@ -234,7 +539,7 @@ func calculateFilingTimeliness(db *sql.DB, charityNumber int) float64 {
 	}
 
 	if totalCount == 0 {
-		return 50 // Neutral score if no filing data
+		return neutrals.Filing
 	}
 
 	// Calculate percentage and scale to 0-100
@@ -244,9 +549,9 @@ func calculateFilingTimeliness(db *sql.DB, charityNumber int) float64 {
 
 // calculateFilingConsistency checks for gaps in filing history over the last 5 years
 // Returns a score from 0-100
-func calculateFilingConsistency(db *sql.DB, charityNumber int) float64 {
+func calculateFilingConsistency(ctx context.Context, db *sql.DB, charityNumber int) float64 {
 	// Get filing records from the last 5 years
-	rows, err := db.Query(`
+	rows, err := db.QueryContext(ctx, `
 		SELECT ar_cycle_reference, date_annual_return_received
 		FROM annual_return_history
 		WHERE registered_charity_number = ?
@@ -254,7 +559,7 @@ func calculateFilingConsistency(db *sql.DB, charityNumber int) float64 {
 		ORDER BY fin_period_end_date DESC
 	`, charityNumber)
 	if err != nil {
-		return 50 // Neutral score if no data
+		return neutrals.Filing
 	}
 	defer rows.Close()
 
@@ -276,7 +581,7 @@ func calculateFilingConsistency(db *sql.DB, charityNumber int) float64 {
 	}
 
 	if expectedFilings == 0 {
-		return 50 // Neutral score if charity is too new or no data
+		return neutrals.Filing // Charity is too new or there's no data
 	}
 
 	// Calculate consistency percentage
@@ -286,12 +591,12 @@ func calculateFilingConsistency(db *sql.DB, charityNumber int) float64 {
 
 // calculateAccountsQuality checks for qualified accounts (audit issues) in recent years
 // Returns a score from 0-100
-func calculateAccountsQuality(db *sql.DB, charityNumber int) float64 {
+func calculateAccountsQuality(ctx context.Context, db *sql.DB, charityNumber int) float64 {
 	// Check last 3 years for qualified accounts
 	var qualifiedCount int
 	var totalCount int
 
-	err := db.QueryRow(`
+	err := db.QueryRowContext(ctx, `
 		SELECT 
 			COUNT(*) as total,
 			SUM(CASE WHEN accounts_qualified = 1 THEN 1 ELSE 0 END) as qualified
@@ -302,7 +607,7 @@ func calculateAccountsQuality(db *sql.DB, charityNumber int) float64 {
 	`, charityNumber).Scan(&totalCount, &qualifiedCount)
 
 	if err != nil || totalCount == 0 {
-		return 100 // Assume good quality if no data (benefit of doubt)
+		return neutrals.AccountsQuality
 	}
 
 	// If any accounts were qualified, reduce the score
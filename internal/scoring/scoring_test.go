@@ -0,0 +1,309 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"charitylens/internal/models"
+)
+
+// fullFiling is a filing history with every return on time and clean accounts
+var fullFiling = FilingStats{Timeliness: 100, Consistency: 100, AccountsQuality: 100, Filings: 5}
+
+// wantScore holds the parts of a score a test checks
+type wantScore struct {
+	efficiency, financialHealth, transparency, governance, overall float64
+	confidence                                                     string
+}
+
+// checkScore compares score with want, allowing for rounding in the weighting
+func checkScore(t *testing.T, score models.CharityScore, want wantScore) {
+	t.Helper()
+	got := wantScore{
+		efficiency:      score.EfficiencyScore,
+		financialHealth: score.FinancialHealthScore,
+		transparency:    score.TransparencyScore,
+		governance:      score.GovernanceScore,
+		overall:         score.OverallScore,
+		confidence:      score.ConfidenceLevel,
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if !near(got.efficiency, want.efficiency) || !near(got.financialHealth, want.financialHealth) ||
+		!near(got.transparency, want.transparency) || !near(got.governance, want.governance) ||
+		!near(got.overall, want.overall) || got.confidence != want.confidence {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// useNeutrals sets n for the rest of the test
+func useNeutrals(t *testing.T, n Neutrals) {
+	t.Helper()
+	previous := CurrentNeutrals()
+	SetNeutrals(n)
+	t.Cleanup(func() { SetNeutrals(previous) })
+}
+
+func TestScoreFromInputs(t *testing.T) {
+	useNeutrals(t, DefaultNeutrals())
+
+	charity := models.Charity{
+		RegisteredNumber: 1000,
+		Name:             "Alpha Trust",
+		Website:          "https://alpha.example",
+		LastUpdated:      time.Now(),
+	}
+	stale := charity
+	stale.Website = ""
+	stale.LastUpdated = time.Now().AddDate(-2, 0, 0)
+
+	tests := []struct {
+		name     string
+		charity  models.Charity
+		fin      *models.Financial
+		trustees int
+		filing   FilingStats
+		want     wantScore
+	}{
+		{
+			name:     "no financial data",
+			charity:  charity,
+			trustees: 3,
+			filing:   fullFiling,
+			want:     wantScore{0, 0, 80, 100, 26, "high"},
+		},
+		{
+			name:    "full data in the optimal reserves range",
+			charity: charity,
+			fin: &models.Financial{
+				TotalIncome: 200_000, TotalSpending: 100_000,
+				CharitableActivitiesSpend: 90_000, Reserves: 50_000,
+			},
+			trustees: 3,
+			filing:   fullFiling,
+			want:     wantScore{90, 100, 100, 100, 96, "high"},
+		},
+		{
+			name:    "no spending breakdown or reserves",
+			charity: charity,
+			fin: &models.Financial{
+				TotalIncome: 200_000, TotalSpending: 100_000,
+			},
+			trustees: 3,
+			filing:   fullFiling,
+			want:     wantScore{NeutralEfficiency, NeutralFinancialHealth, 100, 100, 24 + 15 + 20 + 10, "high"},
+		},
+		{
+			name:    "too few reserves",
+			charity: charity,
+			fin: &models.Financial{
+				TotalIncome: 200_000, TotalSpending: 100_000,
+				CharitableActivitiesSpend: 80_000, Reserves: 12_500,
+			},
+			trustees: 3,
+			filing:   fullFiling,
+			want:     wantScore{80, 50, 100, 100, 32 + 15 + 20 + 10, "high"},
+		},
+		{
+			name:    "excess reserves, using assets",
+			charity: charity,
+			fin: &models.Financial{
+				TotalIncome: 200_000, TotalSpending: 100_000,
+				CharitableActivitiesSpend: 80_000, Assets: 200_000,
+			},
+			trustees: 3,
+			filing:   fullFiling,
+			want:     wantScore{80, 95, 100, 100, 32 + 28.5 + 20 + 10, "high"},
+		},
+		{
+			name:    "charitable spend above total is capped",
+			charity: charity,
+			fin: &models.Financial{
+				TotalIncome: 200_000, TotalSpending: 100_000,
+				CharitableActivitiesSpend: 120_000, Reserves: 50_000,
+			},
+			trustees: 3,
+			filing:   fullFiling,
+			want:     wantScore{100, 100, 100, 100, 100, "high"},
+		},
+		{
+			name:    "stale, no website, trustees or filings",
+			charity: stale,
+			fin: &models.Financial{
+				TotalIncome: 200_000, TotalSpending: 100_000,
+				CharitableActivitiesSpend: 90_000, Reserves: 50_000,
+			},
+			want: wantScore{90, 100, 20, 0, 36 + 30 + 4, "low"},
+		},
+		{
+			name:    "one piece of data",
+			charity: charity,
+			filing:  fullFiling,
+			want:    wantScore{0, 0, 70, 0, 14, "medium"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := ScoreFromInputs(tt.charity, tt.fin, tt.trustees, tt.filing)
+			if score.CharityNumber != tt.charity.RegisteredNumber || score.ScoringVersion != ScoringVersion {
+				t.Errorf("score is for charity %d version %d, want %d version %d",
+					score.CharityNumber, score.ScoringVersion, tt.charity.RegisteredNumber, ScoringVersion)
+			}
+			checkScore(t, score, tt.want)
+		})
+	}
+}
+
+func TestWebsiteNeutral(t *testing.T) {
+	noWebsite := models.Charity{RegisteredNumber: 1000, LastUpdated: time.Now()}
+	withWebsite := noWebsite
+	withWebsite.Website = "https://alpha.example"
+
+	tiny := &models.Financial{TotalIncome: 5_000, TotalSpending: 4_000}
+	large := &models.Financial{TotalIncome: 1_000_000, TotalSpending: 900_000}
+
+	limited := DefaultNeutrals()
+	limited.WebsiteIncomeLimit = 25_000
+
+	// Transparency without any website points: financials, trustees and a full
+	// filing history
+	const base = TransparencyFinancialsPoints + TransparencyTrusteesPoints + 40
+
+	tests := []struct {
+		name     string
+		neutrals Neutrals
+		charity  models.Charity
+		fin      *models.Financial
+		want     float64
+	}{
+		{"tiny charity without a website, no limit set", DefaultNeutrals(), noWebsite, tiny, base},
+		{"large charity without a website, no limit set", DefaultNeutrals(), noWebsite, large, base},
+		{"tiny charity without a website", limited, noWebsite, tiny, base + NeutralWebsite*TransparencyWebsitePoints/100.0},
+		{"large charity without a website", limited, noWebsite, large, base},
+		{"income at the limit", limited, noWebsite, &models.Financial{TotalIncome: 25_000, TotalSpending: 20_000}, base},
+		{"no financial data", limited, noWebsite, nil, base - TransparencyFinancialsPoints},
+		{"tiny charity with a website", limited, withWebsite, tiny, base + TransparencyWebsitePoints},
+		{"large charity with a website", limited, withWebsite, large, base + TransparencyWebsitePoints},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useNeutrals(t, tt.neutrals)
+			score := ScoreFromInputs(tt.charity, tt.fin, 7, fullFiling)
+			if math.Abs(score.TransparencyScore-tt.want) > 1e-9 {
+				t.Errorf("transparency = %v, want %v", score.TransparencyScore, tt.want)
+			}
+		})
+	}
+
+	// A tiny charity without a website still scores below one with a website,
+	// but above a large charity without one
+	useNeutrals(t, limited)
+	tinyScore := ScoreFromInputs(noWebsite, tiny, 7, fullFiling).OverallScore
+	largeScore := ScoreFromInputs(noWebsite, &models.Financial{TotalIncome: 1_000_000, TotalSpending: 4_000}, 7, fullFiling).OverallScore
+	withScore := ScoreFromInputs(withWebsite, tiny, 7, fullFiling).OverallScore
+	if !(largeScore < tinyScore && tinyScore < withScore) {
+		t.Errorf("overall scores: large without website %v, tiny without %v, tiny with %v; want increasing",
+			largeScore, tinyScore, withScore)
+	}
+}
+
+func TestScoreWithoutSpending(t *testing.T) {
+	useNeutrals(t, DefaultNeutrals())
+
+	charity := models.Charity{RegisteredNumber: 1000, Website: "https://alpha.example", LastUpdated: time.Now()}
+	noWebsite := charity
+	noWebsite.Website = ""
+
+	tests := []struct {
+		name    string
+		charity models.Charity
+		fin     *models.Financial
+		want    wantScore
+	}{
+		{
+			name:    "income only",
+			charity: charity,
+			fin:     &models.Financial{TotalIncome: 50_000, Reserves: 20_000},
+			want:    wantScore{NeutralEfficiency, NeutralFinancialHealth, 100, 100, 24 + 15 + 20 + 10, "high"},
+		},
+		{
+			name:    "spending only",
+			charity: charity,
+			fin:     &models.Financial{TotalSpending: 40_000, CharitableActivitiesSpend: 30_000, Reserves: 20_000},
+			want:    wantScore{75, 100, 100, 100, 30 + 30 + 20 + 10, "high"},
+		},
+		{
+			name:    "both zero",
+			charity: charity,
+			fin:     &models.Financial{},
+			want:    wantScore{NeutralEfficiency, NeutralFinancialHealth, 100, 100, 24 + 15 + 20 + 10, "high"},
+		},
+		{
+			// A year without spending adds no confidence
+			name:    "income only without a website",
+			charity: noWebsite,
+			fin:     &models.Financial{TotalIncome: 50_000},
+			want:    wantScore{NeutralEfficiency, NeutralFinancialHealth, 70, 100, 24 + 15 + 14 + 10, "medium"},
+		},
+		{
+			name:    "spending only without a website",
+			charity: noWebsite,
+			fin:     &models.Financial{TotalSpending: 40_000, CharitableActivitiesSpend: 30_000, Reserves: 20_000},
+			want:    wantScore{75, 100, 70, 100, 30 + 30 + 14 + 10, "high"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkScore(t, ScoreFromInputs(tt.charity, tt.fin, 3, fullFiling), tt.want)
+		})
+	}
+}
+
+func TestTradingCostsExcluded(t *testing.T) {
+	// Half of spending is charitable; a quarter raises funds
+	fin := func(partA *models.AnnualReturnPartA) models.Financial {
+		return models.Financial{
+			TotalIncome:               100_000,
+			TotalSpending:             80_000,
+			CharitableActivitiesSpend: 40_000,
+			RaisingFundsSpend:         20_000,
+			PartA:                     partA,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		fin       models.Financial
+		wantRatio float64
+		excluded  bool
+	}{
+		{"no part a", fin(nil), 0.5, false},
+		{"trading without public fundraising", fin(&models.AnnualReturnPartA{HasTradingSubsidiary: true}), 40.0 / 60, true},
+		{"trading with public fundraising", fin(&models.AnnualReturnPartA{HasTradingSubsidiary: true, RaisesFundsFromPublic: true}), 0.5, false},
+		{"no trading subsidiary", fin(&models.AnnualReturnPartA{}), 0.5, false},
+		{
+			name: "raising funds is all spending",
+			fin: models.Financial{TotalSpending: 20_000, CharitableActivitiesSpend: 10_000, RaisingFundsSpend: 20_000,
+				PartA: &models.AnnualReturnPartA{HasTradingSubsidiary: true}},
+			wantRatio: 0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := FinancialMetrics(tt.fin)
+			if metrics.CharitableSpendRatio == nil {
+				t.Fatal("charitable spend ratio not set")
+			}
+			if got := *metrics.CharitableSpendRatio; math.Abs(got-tt.wantRatio) > 1e-9 {
+				t.Errorf("charitable spend ratio = %v, want %v", got, tt.wantRatio)
+			}
+			if metrics.TradingCostsExcluded != tt.excluded {
+				t.Errorf("trading costs excluded = %v, want %v", metrics.TradingCostsExcluded, tt.excluded)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"charitylens/internal/database"
+	"charitylens/internal/models"
+)
+
+// TestTrusteeCountFromTrusteesTable checks the governance score counts the
+// trustees table, not the financials trustees column, when the two disagree
+func TestTrusteeCountFromTrusteesTable(t *testing.T) {
+	useNeutrals(t, DefaultNeutrals())
+	db := openTestDB(t)
+	now := time.Now()
+
+	if err := database.UpsertCharity(db, models.Charity{
+		RegisteredNumber: 1000, Name: "Alpha Trust", Status: "Registered", LastUpdated: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The financials column claims ten trustees, in the lowest income band where
+	// three earn the full governance score
+	if _, err := db.Exec(database.UpsertFinancialSQL,
+		1000, time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC),
+		5_000.0, 4_000.0, 3_000.0, 500.0, 500.0, 2_000.0, 2_000.0, 0, 10, now,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	// but only two are on record
+	for _, name := range []string{"Jane Smith", "John Doe"} {
+		if _, err := db.Exec(database.InsertTrusteeSQL, 1000, name, database.TrusteeNameKey(name), now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	score, err := CalculateScore(db, 1000, false)
+	if err != nil {
+		t.Fatalf("scoring charity: %v", err)
+	}
+	if want := 2.0 / FullGovernanceTrustees * 100; math.Abs(score.GovernanceScore-want) > 1e-9 {
+		t.Errorf("governance score = %v, want %v from 2 of %d trustees", score.GovernanceScore, want, FullGovernanceTrustees)
+	}
+}
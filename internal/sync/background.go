@@ -0,0 +1,211 @@
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	stdsync "sync"
+	"time"
+
+	"charitylens/internal/api"
+	"charitylens/internal/background"
+	"charitylens/internal/config"
+	"charitylens/internal/logger"
+)
+
+// Background sync retry limits. The API client already retries within a request,
+// so these only come into play once a whole fetch has failed, typically while the
+// API is down or the circuit breaker is open.
+const (
+	backgroundMaxAttempts   = 4
+	backgroundRetryDelay    = 30 * time.Second // Doubled after each failed attempt
+	backgroundMaxRetryDelay = 5 * time.Minute
+
+	// backgroundFailureTTL is how long a terminal failure is remembered. Until it
+	// expires, further requests for the charity report the failure rather than
+	// starting another round of attempts.
+	backgroundFailureTTL = time.Hour
+)
+
+// BackgroundStatus is the state of a charity's background sync
+type BackgroundStatus struct {
+	Attempts int       // Fetches made so far
+	Err      error     // Terminal failure, nil while the sync is still in progress
+	FailedAt time.Time // When the sync gave up
+}
+
+// Failed reports whether the sync has given up
+func (s BackgroundStatus) Failed() bool {
+	return s.Err != nil
+}
+
+// NotFound reports whether the sync gave up because the API doesn't know the charity
+func (s BackgroundStatus) NotFound() bool {
+	return errors.Is(s.Err, api.ErrNotFound)
+}
+
+var (
+	backgroundMu    stdsync.Mutex
+	backgroundSyncs = make(map[int]*BackgroundStatus)
+)
+
+// backgroundCtx is the parent of background syncs, cancelled by
+// StopBackgroundSyncs
+var backgroundCtx, stopBackground = context.WithCancel(context.Background())
+
+// StopBackgroundSyncs cancels background syncs in progress or waiting to retry,
+// so they don't hold up shutdown. Syncs started afterwards fail immediately.
+func StopBackgroundSyncs() {
+	stopBackground()
+}
+
+// SyncInBackground fetches and stores a charity without blocking the caller,
+// retrying failed fetches with exponential backoff up to backgroundMaxAttempts
+// times. onSuccess, if not nil, runs after the charity has been stored.
+//
+// The sync counts towards the background work limit, and is dropped if it's
+// been reached, as it is while the API budget is exhausted. A charity is only
+// synced once at a time: calls while a sync is in progress, or within
+// backgroundFailureTTL of it failing, are ignored. Use BackgroundSyncStatus to
+// find out how it went. Failures are also recorded in sync_failures for
+// RetryFailedSyncs.
+func SyncInBackground(cfg *config.Config, db *sql.DB, charityNum int, onSuccess func()) {
+	if APIBudgetExhausted() {
+		logger.Warn("API budget exhausted, background sync skipped", "operation", "sync", "charity_number", charityNum)
+		return
+	}
+
+	backgroundMu.Lock()
+	if status, ok := backgroundSyncs[charityNum]; ok {
+		if !status.Failed() || time.Since(status.FailedAt) < backgroundFailureTTL {
+			backgroundMu.Unlock()
+			return
+		}
+	}
+	status := &BackgroundStatus{}
+	backgroundSyncs[charityNum] = status
+	backgroundMu.Unlock()
+
+	ctx, cancel := context.WithCancel(backgroundCtx)
+	s := &backgroundSync{
+		ctx:        ctx,
+		cancel:     cancel,
+		cfg:        cfg,
+		db:         db,
+		charityNum: charityNum,
+		status:     status,
+		onSuccess:  onSuccess,
+		delay:      backgroundRetryDelay,
+	}
+
+	// Forget the sync if there's no room for it, so the next call tries again
+	if !background.Go("sync", func() { runBackgroundSync(s) }) {
+		s.forget()
+	}
+}
+
+// BackgroundSyncStatus returns the state of a charity's background sync, or false
+// if none is in progress or recently failed. Successful syncs aren't kept, since
+// the charity is then in the database.
+func BackgroundSyncStatus(charityNum int) (BackgroundStatus, bool) {
+	backgroundMu.Lock()
+	defer backgroundMu.Unlock()
+
+	status, ok := backgroundSyncs[charityNum]
+	if !ok {
+		return BackgroundStatus{}, false
+	}
+	if status.Failed() && time.Since(status.FailedAt) >= backgroundFailureTTL {
+		delete(backgroundSyncs, charityNum)
+		return BackgroundStatus{}, false
+	}
+	return *status, true
+}
+
+// backgroundSync is one charity's background sync, carried from attempt to
+// attempt
+type backgroundSync struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	cfg        *config.Config
+	db         *sql.DB
+	charityNum int
+	status     *BackgroundStatus
+	onSuccess  func()
+	attempt    int
+	delay      time.Duration // Before the next attempt
+}
+
+// runBackgroundSync makes one attempt at the sync in the calling background task.
+// A failed attempt that's worth repeating is rescheduled with a timer rather than
+// waited for here, so the sync doesn't hold a background slot between attempts;
+// the retry takes a new slot when it's due. The sync gives up for good after
+// backgroundMaxAttempts, when the API doesn't have the charity, or when the API
+// budget is exhausted or the circuit breaker is open, since those won't clear
+// within the retries. It's abandoned without recording a failure if ctx is
+// cancelled for shutdown.
+func runBackgroundSync(s *backgroundSync) {
+	log := logger.With("operation", "sync", "charity_number", s.charityNum)
+	s.attempt++
+
+	log.Info("Starting background sync", "attempt", s.attempt)
+	err := FetchAndStoreCharityContext(s.ctx, s.cfg, s.db, strconv.Itoa(s.charityNum))
+
+	backgroundMu.Lock()
+	s.status.Attempts = s.attempt
+	backgroundMu.Unlock()
+
+	switch {
+	case err == nil:
+		log.Info("Background sync completed", "attempt", s.attempt)
+		s.forget()
+		clearSyncFailure(s.db, s.charityNum)
+		if s.onSuccess != nil {
+			s.onSuccess()
+		}
+		return
+
+	case s.ctx.Err() != nil:
+		log.Info("Background sync cancelled for shutdown", "attempt", s.attempt)
+		s.forget()
+		return
+
+	// Retrying won't find a charity the API doesn't have, or get past a spent
+	// budget or an open circuit in time
+	case errors.Is(err, api.ErrNotFound), errors.Is(err, api.ErrBudgetExhausted),
+		errors.Is(err, api.ErrCircuitOpen), s.attempt >= backgroundMaxAttempts:
+		log.Error("Background sync failed", "attempts", s.attempt, "error", err)
+		backgroundMu.Lock()
+		s.status.Err = err
+		s.status.FailedAt = time.Now()
+		backgroundMu.Unlock()
+		s.cancel()
+		recordSyncFailure(s.db, s.charityNum, s.attempt, err)
+		return
+	}
+
+	log.Warn("Background sync attempt failed, retrying", "attempt", s.attempt, "retry_in", s.delay, "error", err)
+	delay := s.delay
+	s.delay = min(s.delay*2, backgroundMaxRetryDelay)
+	timer := time.AfterFunc(delay, func() {
+		if s.ctx.Err() != nil {
+			s.forget()
+			return
+		}
+		// Forget the sync if there's no room for the retry, so the next call tries again
+		if !background.Go("sync", func() { runBackgroundSync(s) }) {
+			s.forget()
+		}
+	})
+	// Stop waiting on shutdown
+	context.AfterFunc(s.ctx, func() { timer.Stop() })
+}
+
+// forget removes the sync from backgroundSyncs and releases its context
+func (s *backgroundSync) forget() {
+	backgroundMu.Lock()
+	delete(backgroundSyncs, s.charityNum)
+	backgroundMu.Unlock()
+	s.cancel()
+}
@@ -0,0 +1,204 @@
+package sync
+
+import (
+	"database/sql"
+	stdsync "sync"
+	"time"
+
+	"charitylens/internal/database"
+	"charitylens/internal/logger"
+)
+
+// BudgetWindow is the API budget for one period, a clock hour or a UTC day
+type BudgetWindow struct {
+	Period    string    `json:"period"` // "hour" or "day"
+	Limit     int       `json:"limit"`  // 0 = unlimited
+	Used      int       `json:"used"`
+	Remaining int       `json:"remaining"` // -1 when unlimited
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// BudgetStats is a snapshot of the API budget
+type BudgetStats struct {
+	Exhausted bool           `json:"exhausted"`
+	Refused   uint64         `json:"refused"` // Requests refused since the server started
+	Windows   []BudgetWindow `json:"windows"`
+}
+
+// budgetWindow counts the requests made in the current window of a period
+type budgetWindow struct {
+	period string
+	limit  int
+	start  time.Time
+	used   int
+}
+
+// windowStart returns the start of the window of period containing t
+func windowStart(period string, t time.Time) time.Time {
+	t = t.UTC()
+	if period == "hour" {
+		return t.Truncate(time.Hour)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// windowLength returns how long a window of period lasts
+func windowLength(period string) time.Duration {
+	if period == "hour" {
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// apiBudget caps the requests the shared client makes to the Charity Commission
+// API in each clock hour and UTC day, so a spike in traffic can't use up the
+// key's quota and get it throttled. It's unlimited until InitAPIBudget sets the
+// limits. Counts are stored in api_budget so a restart doesn't reset them.
+var apiBudget = &budget{
+	windows: []*budgetWindow{{period: "hour"}, {period: "day"}},
+}
+
+// budget implements api.CallBudget for the shared client
+type budget struct {
+	mu      stdsync.Mutex
+	db      *sql.DB
+	windows []*budgetWindow
+	refused uint64
+}
+
+// InitAPIBudget sets the most API requests the server may make per hour and per
+// day (0 = unlimited), and restores the counts for the current windows from db.
+// It's meant to be called once at startup, before the API is used.
+func InitAPIBudget(db *sql.DB, hourly, daily int) error {
+	apiBudget.mu.Lock()
+	defer apiBudget.mu.Unlock()
+
+	apiBudget.db = db
+	apiBudget.windows[0].limit = max(hourly, 0)
+	apiBudget.windows[1].limit = max(daily, 0)
+
+	now := time.Now()
+	for _, w := range apiBudget.windows {
+		w.roll(now)
+	}
+
+	rows, err := db.Query(`SELECT period, period_start, calls FROM api_budget`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var period string
+		var start int64
+		var calls int
+		if err := rows.Scan(&period, &start, &calls); err != nil {
+			return err
+		}
+		for _, w := range apiBudget.windows {
+			if w.period == period && w.start.Unix() == start {
+				w.used = calls
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// roll starts a new window once the current one has ended
+func (w *budgetWindow) roll(now time.Time) {
+	if start := windowStart(w.period, now); !start.Equal(w.start) {
+		w.start = start
+		w.used = 0
+	}
+}
+
+// exhausted reports whether the window's limit has been reached
+func (w *budgetWindow) exhausted() bool {
+	return w.limit > 0 && w.used >= w.limit
+}
+
+// Spend counts a request against every window, or refuses it if any has reached
+// its limit
+func (b *budget) Spend() bool {
+	b.mu.Lock()
+	now := time.Now()
+	for _, w := range b.windows {
+		w.roll(now)
+		if w.exhausted() {
+			b.refused++
+			b.mu.Unlock()
+			return false
+		}
+	}
+
+	var limited []budgetWindow
+	for _, w := range b.windows {
+		w.used++
+		if w.limit > 0 {
+			limited = append(limited, *w)
+		}
+	}
+	db := b.db
+	b.mu.Unlock()
+
+	// Only limited windows need to survive a restart. A write that lands after a
+	// later one keeps the higher count.
+	if db != nil {
+		for _, w := range limited {
+			_, err := database.ExecRetry(db, `
+				INSERT INTO api_budget (period, period_start, calls) VALUES (?, ?, ?)
+				ON CONFLICT(period) DO UPDATE SET
+					calls = CASE WHEN period_start = excluded.period_start
+						THEN MAX(calls, excluded.calls) ELSE excluded.calls END,
+					period_start = excluded.period_start
+			`, w.period, w.start.Unix(), w.used)
+			if err != nil {
+				logger.Warn("Failed to store API budget", "operation", "sync", "period", w.period, "error", err)
+			}
+		}
+	}
+	return true
+}
+
+// APIBudgetExhausted reports whether the API budget for the current hour or day
+// has been used up. Until the window resets, API requests fail with
+// api.ErrBudgetExhausted, so callers should serve what's in the database instead.
+func APIBudgetExhausted() bool {
+	apiBudget.mu.Lock()
+	defer apiBudget.mu.Unlock()
+
+	now := time.Now()
+	for _, w := range apiBudget.windows {
+		w.roll(now)
+		if w.exhausted() {
+			return true
+		}
+	}
+	return false
+}
+
+// APIBudgetStats returns how much of the API budget has been used in the current
+// hour and day
+func APIBudgetStats() BudgetStats {
+	apiBudget.mu.Lock()
+	defer apiBudget.mu.Unlock()
+
+	stats := BudgetStats{Refused: apiBudget.refused, Windows: []BudgetWindow{}}
+	now := time.Now()
+	for _, w := range apiBudget.windows {
+		w.roll(now)
+		window := BudgetWindow{
+			Period:    w.period,
+			Limit:     w.limit,
+			Used:      w.used,
+			Remaining: -1,
+			ResetsAt:  w.start.Add(windowLength(w.period)),
+		}
+		if w.limit > 0 {
+			window.Remaining = max(w.limit-w.used, 0)
+		}
+		stats.Exhausted = stats.Exhausted || w.exhausted()
+		stats.Windows = append(stats.Windows, window)
+	}
+	return stats
+}
@@ -0,0 +1,38 @@
+package sync
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"charitylens/internal/database"
+	"charitylens/internal/logger"
+)
+
+// unredistributableFields are removed from raw API responses before they're
+// stored. Trustee names and IDs are personal data, which the Open Government
+// Licence the register is published under doesn't cover.
+var unredistributableFields = []string{"trustee_names"}
+
+// storeRawResponse stores the raw charity details fetched from the API, for the
+// raw endpoint. A failure is only logged, since the parsed data has been stored.
+func storeRawResponse(db *sql.DB, charityNum int, data map[string]any) {
+	redistributable := make(map[string]any, len(data))
+	for key, value := range data {
+		redistributable[key] = value
+	}
+	for _, key := range unredistributableFields {
+		delete(redistributable, key)
+	}
+
+	response, err := json.Marshal(redistributable)
+	if err == nil {
+		_, err = database.ExecRetry(db, `
+			INSERT OR REPLACE INTO charity_raw_responses (charity_number, response, fetched_at)
+			VALUES (?, ?, ?)
+		`, charityNum, string(response), time.Now())
+	}
+	if err != nil {
+		logger.Error("Failed to store raw response", "operation", "sync", "charity_number", charityNum, "error", err)
+	}
+}
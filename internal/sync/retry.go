@@ -0,0 +1,158 @@
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	stdsync "sync"
+	"time"
+
+	"charitylens/internal/api"
+	"charitylens/internal/config"
+	"charitylens/internal/database"
+	"charitylens/internal/logger"
+)
+
+// retrySyncWorkers is how many failed syncs RetryFailedSyncs retries at once. The
+// shared client's rate limiter still applies across them.
+const retrySyncWorkers = 4
+
+// RetryOutcome is the result of retrying one charity's failed sync
+type RetryOutcome struct {
+	CharityNumber int    `json:"charity_number"`
+	Succeeded     bool   `json:"succeeded"`
+	Error         string `json:"error,omitempty"`
+}
+
+// RetryReport summarises a RetryFailedSyncs run
+type RetryReport struct {
+	Retried   int            `json:"retried"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Remaining int            `json:"remaining"` // Failed syncs still to retry, including any not reached
+	Outcomes  []RetryOutcome `json:"outcomes"`
+}
+
+// RetryFailedSyncs retries up to limit failed background syncs (0 = all), oldest
+// failure first, fetching retrySyncWorkers charities at once. Charities the API
+// doesn't know aren't retried. A successful retry clears the failure, including
+// the in-memory one that the charity page reports; a failed one is recorded
+// again. Retries not started before ctx ends are left for the next run.
+func RetryFailedSyncs(ctx context.Context, cfg *config.Config, db *sql.DB, limit int) (RetryReport, error) {
+	report := RetryReport{Outcomes: []RetryOutcome{}}
+
+	query := `SELECT charity_number FROM sync_failures WHERE not_found = 0 ORDER BY failed_at`
+	var args []any
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return report, err
+	}
+	var charityNumbers []int
+	for rows.Next() {
+		var charityNum int
+		if err := rows.Scan(&charityNum); err != nil {
+			rows.Close()
+			return report, err
+		}
+		charityNumbers = append(charityNumbers, charityNum)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	work := make(chan int)
+	results := make(chan RetryOutcome)
+	var wg stdsync.WaitGroup
+	for range retrySyncWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for charityNum := range work {
+				err := FetchAndStoreCharityContext(ctx, cfg, db, strconv.Itoa(charityNum))
+				if ctx.Err() != nil {
+					continue // Cut short, so it's still waiting for a retry
+				}
+				outcome := RetryOutcome{CharityNumber: charityNum, Succeeded: err == nil}
+				if err != nil {
+					outcome.Error = err.Error()
+					recordSyncFailure(db, charityNum, 1, err)
+				} else {
+					clearSyncFailure(db, charityNum)
+				}
+				results <- outcome
+			}
+		}()
+	}
+
+	go func() {
+	send:
+		for _, charityNum := range charityNumbers {
+			select {
+			case work <- charityNum:
+			case <-ctx.Done():
+				break send
+			}
+		}
+		close(work)
+		wg.Wait()
+		close(results)
+	}()
+
+	for outcome := range results {
+		report.Retried++
+		if outcome.Succeeded {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+		report.Outcomes = append(report.Outcomes, outcome)
+	}
+
+	// Counted afresh, since ctx may have ended; this is only informational
+	err = db.QueryRow(`SELECT COUNT(*) FROM sync_failures WHERE not_found = 0`).Scan(&report.Remaining)
+	if err != nil {
+		return report, err
+	}
+
+	logger.Info("Retried failed syncs", "operation", "sync", "retried", report.Retried,
+		"succeeded", report.Succeeded, "failed", report.Failed, "remaining", report.Remaining)
+	return report, nil
+}
+
+// recordSyncFailure records that a charity's sync failed after attempts more
+// fetches, for RetryFailedSyncs. Errors are logged rather than returned, since
+// the sync has already failed.
+func recordSyncFailure(db *sql.DB, charityNum, attempts int, syncErr error) {
+	_, err := database.ExecRetry(db, `
+		INSERT INTO sync_failures (charity_number, attempts, last_error, not_found, failed_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (charity_number) DO UPDATE SET
+			attempts = sync_failures.attempts + excluded.attempts,
+			last_error = excluded.last_error,
+			not_found = excluded.not_found,
+			failed_at = excluded.failed_at
+	`, charityNum, attempts, syncErr.Error(), errors.Is(syncErr, api.ErrNotFound), time.Now())
+	if err != nil {
+		logger.Error("Failed to record sync failure", "operation", "sync", "charity_number", charityNum, "error", err)
+	}
+}
+
+// clearSyncFailure removes a charity's recorded sync failure after it has synced,
+// in the database and in memory
+func clearSyncFailure(db *sql.DB, charityNum int) {
+	if _, err := database.ExecRetry(db, `DELETE FROM sync_failures WHERE charity_number = ?`, charityNum); err != nil {
+		logger.Error("Failed to clear sync failure", "operation", "sync", "charity_number", charityNum, "error", err)
+	}
+
+	backgroundMu.Lock()
+	if status, ok := backgroundSyncs[charityNum]; ok && status.Failed() {
+		delete(backgroundSyncs, charityNum)
+	}
+	backgroundMu.Unlock()
+}
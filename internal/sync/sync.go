@@ -4,20 +4,51 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"sort"
 	"strconv"
+	stdsync "sync"
 	"time"
 
 	"charitylens/internal/api"
 	"charitylens/internal/config"
+	"charitylens/internal/database"
+	"charitylens/internal/logger"
+	"charitylens/internal/models"
+	"charitylens/internal/webhook"
 )
 
-// debugLog logs a message only if debug mode is enabled
-func debugLog(cfg *config.Config, format string, args ...any) {
-	if cfg.Debug {
-		log.Printf(format, args...)
-	}
+var (
+	sharedClient     *api.Client
+	sharedClientOnce stdsync.Once
+)
+
+// getClient returns the shared API client, creating it from config on first use.
+// Sharing one client gives real rate limiting across all sync calls and lets
+// the underlying HTTP client reuse connections.
+func getClient(cfg *config.Config) *api.Client {
+	sharedClientOnce.Do(func() {
+		rateLimiter := api.NewRateLimiterFloat(cfg.SyncRateLimit)
+		sharedClient = api.NewClient(api.ClientConfig{
+			APIKeys:     cfg.CharityAPIKeys,
+			RateLimiter: rateLimiter,
+			Verbose:     cfg.Debug,
+			CallBudget:  apiBudget,
+		})
+	})
+	return sharedClient
+}
+
+// Client returns the long-lived API client shared by all sync calls, for
+// reporting its statistics
+func Client(cfg *config.Config) *api.Client {
+	return getClient(cfg)
+}
+
+// APIAvailable reports whether the Charity Commission API is believed reachable.
+// It returns false while the client's circuit breaker is open after repeated
+// failures, and recovers automatically once requests succeed again.
+func APIAvailable(cfg *config.Config) bool {
+	return getClient(cfg).Available()
 }
 
 func getMapKeys(m map[string]any) []string {
@@ -33,12 +64,40 @@ func StartSyncWorker(cfg *config.Config, db *sql.DB) {
 	ticker := time.NewTicker(time.Duration(cfg.SyncIntervalHours) * time.Hour)
 	defer ticker.Stop()
 
-	log.Println("Starting sync worker...")
+	logger.Info("Starting sync worker", "operation", "sync", "interval_hours", cfg.SyncIntervalHours)
 
+	notifier := webhook.New(cfg.WebhookURL, cfg.WebhookSecret)
 	for range ticker.C {
-		if err := SyncCharities(cfg, db); err != nil {
-			log.Printf("Sync failed: %v", err)
+		started := time.Now()
+		err := SyncCharities(cfg, db)
+		if err != nil {
+			logger.Error("Sync failed", "operation", "sync", "error", err)
 		}
+		notifySyncCycle(notifier, started, err)
+	}
+}
+
+// notifySyncCycle sends a sync worker cycle's outcome to the webhook, if one is
+// configured. A failed webhook is only logged.
+func notifySyncCycle(notifier *webhook.Notifier, started time.Time, syncErr error) {
+	if notifier == nil {
+		return
+	}
+
+	finished := time.Now()
+	event := webhook.Event{
+		Event:           "sync",
+		Mode:            "worker",
+		Success:         syncErr == nil,
+		StartedAt:       started,
+		FinishedAt:      finished,
+		DurationSeconds: finished.Sub(started).Seconds(),
+	}
+	if syncErr != nil {
+		event.Error = syncErr.Error()
+	}
+	if err := notifier.Notify(context.Background(), event); err != nil {
+		logger.Warn("Failed to send sync webhook", "operation", "sync", "error", err)
 	}
 }
 
@@ -46,21 +105,21 @@ func SyncCharities(cfg *config.Config, db *sql.DB) error {
 	// This function is called by the sync worker and admin endpoint
 	// Since we use sync-on-demand when users access charities,
 	// this is now a no-op to avoid unnecessary API calls
-	debugLog(cfg, "SyncCharities called - no action taken (sync-on-demand is enabled)")
+	logger.Debug("SyncCharities called - no action taken (sync-on-demand is enabled)", "operation", "sync")
 	return nil
 }
 
 func FetchAndStoreCharity(cfg *config.Config, db *sql.DB, charityNum string) error {
-	debugLog(cfg, "Fetching charity %s from Charity Commission API", charityNum)
-
-	// Create API client with rate limiter
-	rateLimiter := api.NewRateLimiter(10.0) // 10 req/s rate limit
-	client := api.NewClient(api.ClientConfig{
-		APIKey:      cfg.CharityAPIKey,
-		RateLimiter: rateLimiter,
-		Verbose:     cfg.Debug,
-	})
-	ctx := context.Background()
+	return FetchAndStoreCharityContext(context.Background(), cfg, db, charityNum)
+}
+
+// FetchAndStoreCharityContext is FetchAndStoreCharity bounded by ctx, so callers
+// serving a page can cap how long they wait for the API, including retries.
+func FetchAndStoreCharityContext(ctx context.Context, cfg *config.Config, db *sql.DB, charityNum string) error {
+	log := logger.With("operation", "sync", "charity_number", charityNum)
+	log.Debug("Fetching charity from Charity Commission API")
+
+	client := getClient(cfg)
 
 	// Convert charity number to int
 	charityNumInt, err := strconv.Atoi(charityNum)
@@ -71,38 +130,38 @@ func FetchAndStoreCharity(cfg *config.Config, db *sql.DB, charityNum string) err
 	// Fetch charity details
 	data, err := client.FetchCharityDetails(ctx, charityNumInt)
 	if err != nil {
-		log.Printf("Failed to fetch charity %s: %v", charityNum, err)
+		log.Error("Failed to fetch charity", "error", err)
 		return err
 	}
 
-	debugLog(cfg, "Successfully received and parsed API data for charity %s", charityNum)
+	log.Debug("Successfully received and parsed API data")
 
 	// Parse and store charity data
-	debugLog(cfg, "Parsing charity data for %s", charityNum)
+	log.Debug("Parsing charity data")
 	charity, err := api.ParseCharityData(data, charityNum)
 	if err != nil {
-		log.Printf("Failed to parse charity data for %s: %v", charityNum, err)
+		log.Error("Failed to parse charity data", "error", err)
 		return err
 	}
-	debugLog(cfg, "Parsed charity: registered_number=%d, name=%s", charity.RegisteredNumber, charity.Name)
+	log.Debug("Parsed charity", "registered_number", charity.RegisteredNumber, "name", charity.Name)
 
-	// Insert charity
-	debugLog(cfg, "Storing charity data for %s in database", charityNum)
-	_, err = db.Exec(`
-		INSERT OR REPLACE INTO charities
-		(registered_number, company_number, name, status, date_registered, address, website, email, what_the_charity_does)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		charity.RegisteredNumber, charity.CompanyNumber, charity.Name, charity.Status, charity.DateRegistered,
-		charity.Address, charity.Website, charity.Email, charity.WhatTheCharityDoes)
-	if err != nil {
-		log.Printf("Failed to store charity data for %s: %v", charityNum, err)
+	// Insert or update charity, capturing status changes such as removal
+	log.Debug("Storing charity data in database")
+	if err := database.RetryBusy(func() error { return database.UpsertCharity(db, charity) }); err != nil {
+		log.Error("Failed to store charity data", "error", err)
 		return err
 	}
-	debugLog(cfg, "Successfully stored charity data for %s", charityNum)
+	log.Debug("Successfully stored charity data")
+	if charity.DateRemoved != nil {
+		log.Debug("Charity has been removed from the register",
+			"status", charity.Status, "date_removed", charity.DateRemoved.Format("2006-01-02"))
+	}
 
 	// Parse and store financial data
-	debugLog(cfg, "Processing financial data for charity %s", charityNum)
+	log.Debug("Processing financial data")
 	if fin, err := api.ParseFinancialData(data, charity.RegisteredNumber); err == nil {
+		source := models.SourceAPI
+
 		// Fetch detailed financial breakdown from financial history endpoint
 		if detailedFin, err := client.FetchFinancialHistory(ctx, charityNumInt); err == nil && len(detailedFin) > 0 {
 			// Parse detailed financials from the history
@@ -117,61 +176,65 @@ func FetchAndStoreCharity(cfg *config.Config, db *sql.DB, charityNum string) err
 				if parsed.OtherSpend > 0 {
 					fin.OtherSpend = parsed.OtherSpend
 				}
-				debugLog(cfg, "Using detailed financials: charitable=%.2f, fundraising=%.2f", fin.CharitableActivitiesSpend, fin.RaisingFundsSpend)
+				source = models.SourceFinancialHistory
+				log.Debug("Using detailed financials", "charitable", fin.CharitableActivitiesSpend, "fundraising", fin.RaisingFundsSpend)
 			}
 		}
 
-		_, err := db.Exec(`
-			INSERT OR REPLACE INTO financials
-			(charity_number, financial_year_end, total_income, total_spending, charitable_activities_spend, raising_funds_spend, other_spend, reserves, assets, trustees, last_updated)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		_, err := database.ExecRetry(db, database.UpsertFinancialSQL,
 			fin.CharityNumber, fin.FinancialYearEnd, fin.TotalIncome, fin.TotalSpending,
 			fin.CharitableActivitiesSpend, fin.RaisingFundsSpend, fin.OtherSpend,
-			fin.Reserves, fin.Assets, fin.Trustees, fin.LastUpdated)
+			fin.Reserves, fin.Assets, fin.Employees, fin.Trustees, fin.LastUpdated)
 		if err != nil {
-			log.Printf("Failed to store financial data for charity %s: %v", charityNum, err)
+			log.Error("Failed to store financial data", "error", err)
 		} else {
-			debugLog(cfg, "Stored financial data for charity %s (income: %.2f, spending: %.2f, charitable: %.2f)", charityNum, fin.TotalIncome, fin.TotalSpending, fin.CharitableActivitiesSpend)
+			log.Debug("Stored financial data", "income", fin.TotalIncome, "spending", fin.TotalSpending, "charitable", fin.CharitableActivitiesSpend)
+			storeDataSource(db, database.FinancialsSection, charity.RegisteredNumber, source)
 		}
 	} else {
-		debugLog(cfg, "Failed to parse financial data for charity %s: %v", charityNum, err)
+		log.Debug("Failed to parse financial data", "error", err)
 	}
 
 	// Parse and store trustees
 	trustees := api.ParseTrusteesData(data, charity.RegisteredNumber)
 	if len(trustees) > 0 {
-		debugLog(cfg, "Processing %d trustee records for charity %s", len(trustees), charityNum)
+		log.Debug("Processing trustee records", "trustees", len(trustees))
 		for i, trustee := range trustees {
-			debugLog(cfg, "Processing trustee record %d for charity %s: %s", i+1, charityNum, trustee.Name)
-			_, err := db.Exec(`
-				INSERT OR REPLACE INTO trustees
-				(charity_number, name, last_updated)
-				VALUES (?, ?, ?)`,
-				trustee.CharityNumber, trustee.Name, trustee.LastUpdated)
+			log.Debug("Processing trustee record", "index", i+1, "trustee", trustee.Name)
+			_, err := database.ExecRetry(db, database.InsertTrusteeSQL,
+				trustee.CharityNumber, trustee.Name, database.TrusteeNameKey(trustee.Name), trustee.LastUpdated)
 			if err != nil {
-				log.Printf("Failed to store trustee data for charity %s: %v", charityNum, err)
+				log.Error("Failed to store trustee data", "trustee", trustee.Name, "error", err)
 			} else {
-				debugLog(cfg, "Stored trustee data for charity %s: %s", charityNum, trustee.Name)
+				log.Debug("Stored trustee data", "trustee", trustee.Name)
 			}
 		}
+		storeDataSource(db, database.TrusteesSection, charity.RegisteredNumber, models.SourceAPI)
 	} else {
-		debugLog(cfg, "No trustee data available for charity %s", charityNum)
+		log.Debug("No trustee data available")
 	}
 
-	debugLog(cfg, "Completed data storage for charity %s", charityNum)
+	// Keep the response itself for clients that want the unparsed data
+	storeRawResponse(db, charity.RegisteredNumber, data)
+
+	log.Debug("Completed data storage")
 	return nil
 }
 
+// storeDataSource records where a section of a synced charity came from. A failure
+// is only logged, since the data itself has been stored.
+func storeDataSource(db *sql.DB, section database.DataSection, charityNum int, source string) {
+	err := database.RetryBusy(func() error { return database.SetDataSource(db, section, charityNum, source) })
+	if err != nil {
+		logger.Error("Failed to record data source", "operation", "sync", "charity_number", charityNum,
+			"section", string(section), "error", err)
+	}
+}
+
 func SearchCharitiesByName(cfg *config.Config, query string) ([]map[string]any, error) {
-	debugLog(cfg, "Searching charities by name: %s", query)
-
-	// Create API client with rate limiter
-	rateLimiter := api.NewRateLimiter(10.0) // 10 req/s rate limit
-	client := api.NewClient(api.ClientConfig{
-		APIKey:      cfg.CharityAPIKey,
-		RateLimiter: rateLimiter,
-		Verbose:     cfg.Debug,
-	})
+	logger.Debug("Searching charities by name", "operation", "search", "query", query)
+
+	client := getClient(cfg)
 	ctx := context.Background()
 
 	// Search using the client
@@ -180,26 +243,19 @@ func SearchCharitiesByName(cfg *config.Config, query string) ([]map[string]any,
 		return nil, fmt.Errorf("failed to search charities by name: %w", err)
 	}
 
-	debugLog(cfg, "Search returned %d results", len(results))
+	logger.Debug("Search returned results", "operation", "search", "query", query, "results", len(results))
 	if cfg.Debug && len(results) > 0 {
-		debugLog(cfg, "First result keys: %v", getMapKeys(results[0]))
-		debugLog(cfg, "First result sample: charity_name=%v, reg_status=%v",
-			results[0]["charity_name"], results[0]["reg_status"])
+		logger.Debug("First search result", "operation", "search", "keys", getMapKeys(results[0]),
+			"charity_name", results[0]["charity_name"], "reg_status", results[0]["reg_status"])
 	}
 
 	return results, nil
 }
 
 func SearchCharitiesByNumber(cfg *config.Config, charityNum string) ([]map[string]any, error) {
-	debugLog(cfg, "Searching charity by number: %s", charityNum)
-
-	// Create API client with rate limiter
-	rateLimiter := api.NewRateLimiter(10.0) // 10 req/s rate limit
-	client := api.NewClient(api.ClientConfig{
-		APIKey:      cfg.CharityAPIKey,
-		RateLimiter: rateLimiter,
-		Verbose:     cfg.Debug,
-	})
+	logger.Debug("Searching charity by number", "operation", "search", "charity_number", charityNum)
+
+	client := getClient(cfg)
 	ctx := context.Background()
 
 	// Search using the client - returns []map[string]any
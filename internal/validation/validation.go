@@ -0,0 +1,82 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	apperrors "charitylens/internal/errors"
+)
+
+const (
+	// MinCharityNumber is the lowest registered charity number accepted
+	MinCharityNumber = 1
+
+	// MaxCharityNumber is the highest registered charity number accepted.
+	// Charity Commission numbers have at most 7 significant digits.
+	MaxCharityNumber = 9999999
+)
+
+// ValidateCharityNumber rejects numbers outside the Charity Commission's
+// registration number range. The returned error matches ErrInvalidInput.
+func ValidateCharityNumber(number int) error {
+	if number < MinCharityNumber || number > MaxCharityNumber {
+		return apperrors.ValidationError{
+			Field:   "charity_number",
+			Message: fmt.Sprintf("must be between %d and %d", MinCharityNumber, MaxCharityNumber),
+		}
+	}
+	return nil
+}
+
+// companyNumberPattern matches a Companies House number: 8 digits, or a two-letter
+// prefix (e.g. SC for Scotland) and 6 digits, once padded with leading zeros
+var companyNumberPattern = regexp.MustCompile(`^([A-Z]{2}[0-9]{1,6}|[0-9]{1,8})$`)
+
+// NormalizeCompanyNumber validates a Companies House registration number and
+// returns it in canonical form: upper case, without spaces, and zero-padded to
+// 8 characters (e.g. "sc 1234" becomes "SC001234"). The returned error matches
+// ErrInvalidInput.
+func NormalizeCompanyNumber(number string) (string, error) {
+	number = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(number), " ", ""))
+	if !companyNumberPattern.MatchString(number) {
+		return "", apperrors.ValidationError{
+			Field:   "company_number",
+			Message: "must be 8 digits, or a two-letter prefix and 6 digits",
+		}
+	}
+
+	prefix, digits := "", number
+	if number[0] >= 'A' && number[0] <= 'Z' {
+		prefix, digits = number[:2], number[2:]
+	}
+	return prefix + strings.Repeat("0", 8-len(prefix)-len(digits)) + digits, nil
+}
+
+// phoneSeparators are the characters written between a phone number's digits,
+// which are dropped when it's normalised
+var phoneSeparators = strings.NewReplacer(" ", "", "\t", "", "\u00a0", "", "-", "", ".", "", "(", "", ")", "")
+
+// phonePattern matches a normalised phone number: 7 to 15 digits (the most
+// E.164 allows), optionally starting with +
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+
+// NormalizePhone validates a charity's contact phone number and returns it
+// without spaces or other separators (e.g. "0117 496 0000" becomes
+// "01174960000"). An international number's "(0)", as in "+44 (0)117", is
+// dropped too. Values that don't look like a phone number, such as "N/A" or an
+// email address, are rejected with an error matching ErrInvalidInput.
+func NormalizePhone(phone string) (string, error) {
+	phone = strings.TrimSpace(phone)
+	if strings.HasPrefix(phone, "+") {
+		phone = strings.ReplaceAll(phone, "(0)", "")
+	}
+	phone = phoneSeparators.Replace(phone)
+	if !phonePattern.MatchString(phone) {
+		return "", apperrors.ValidationError{
+			Field:   "phone",
+			Message: "must be 7 to 15 digits, optionally starting with +",
+		}
+	}
+	return phone, nil
+}
@@ -0,0 +1,139 @@
+// Package webhook notifies an external URL when a seeder run or sync cycle
+// finishes, for automation that needs to know when new data has landed.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"charitylens/internal/version"
+)
+
+const (
+	// attempts is how many times an event is sent before giving up
+	attempts = 3
+
+	// retryBackoff is the delay before the first retry; it doubles each attempt
+	retryBackoff = time.Second
+
+	// requestTimeout bounds each attempt, so a slow receiver can't hold up the
+	// run it's reporting on
+	requestTimeout = 10 * time.Second
+
+	// SignatureHeader carries the hex HMAC-SHA256 of the body, keyed with the
+	// webhook secret and prefixed "sha256=", when a secret is set
+	SignatureHeader = "X-CharityLens-Signature"
+
+	// EventHeader carries the event's name, e.g. "seed"
+	EventHeader = "X-CharityLens-Event"
+)
+
+// Event is the JSON summary POSTed when a seeder run or sync cycle finishes
+type Event struct {
+	Event           string    `json:"event"` // "seed" or "sync"
+	Mode            string    `json:"mode"`  // The seeder's -mode, or "worker" for the sync worker
+	Success         bool      `json:"success"`
+	Error           string    `json:"error,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+
+	// Stats holds the run's counts, as reported by -stats-json for the seeder
+	Stats any `json:"stats,omitempty"`
+}
+
+// Notifier POSTs events to a webhook URL
+type Notifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// New returns a Notifier for url, signing each body with secret if it isn't
+// empty. It returns nil if url is empty; a nil Notifier sends nothing.
+func New(url, secret string) *Notifier {
+	if url == "" {
+		return nil
+	}
+	return &Notifier{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Sign returns the signature header value for body: "sha256=" and the hex
+// HMAC-SHA256 of body keyed with secret. Receivers should compute the same and
+// compare it with hmac.Equal.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify sends event, retrying with backoff on a network error, a 429 or a 5xx
+// response. Callers should log a returned error rather than fail: a webhook is
+// only a notification.
+func (n *Notifier) Notify(ctx context.Context, event Event) error {
+	if n == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	delay := retryBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := n.send(ctx, event.Event, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == attempts {
+			return fmt.Errorf("webhook failed after %d attempt(s): %w", attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook failed after %d attempt(s): %w", attempt, err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// send POSTs body once, reporting whether a failure is worth retrying
+func (n *Notifier) send(ctx context.Context, eventName string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", version.UserAgent())
+	req.Header.Set(EventHeader, eventName)
+	if n.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook returned %s", resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
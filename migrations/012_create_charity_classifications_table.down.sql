@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_classifications_charity_number;
+DROP INDEX IF EXISTS idx_classifications_code;
+DROP TABLE IF EXISTS charity_classifications;
@@ -0,0 +1,15 @@
+-- Charity classification codes (what/who/how the charity operates)
+-- Sourced from the publicextract.charity_classification extract
+CREATE TABLE IF NOT EXISTS charity_classifications (
+    organisation_number INTEGER NOT NULL,
+    registered_charity_number INTEGER NOT NULL,
+    linked_charity_number INTEGER DEFAULT 0,
+    classification_code TEXT NOT NULL,
+    classification_type TEXT,
+    classification_description TEXT,
+    last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organisation_number, classification_code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_classifications_charity_number ON charity_classifications(registered_charity_number);
+CREATE INDEX IF NOT EXISTS idx_classifications_code ON charity_classifications(classification_code);
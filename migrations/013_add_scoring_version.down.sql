@@ -0,0 +1,2 @@
+-- Remove scoring_version from charity_scores
+ALTER TABLE charity_scores DROP COLUMN scoring_version;
@@ -0,0 +1,3 @@
+-- Add scoring_version to charity_scores so scores from an older algorithm can be recalculated
+-- Existing rows default to 0, older than any released version
+ALTER TABLE charity_scores ADD COLUMN scoring_version INTEGER NOT NULL DEFAULT 0;
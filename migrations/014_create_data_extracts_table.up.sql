@@ -0,0 +1,6 @@
+-- Extract date of each imported bulk data file, used to report data freshness
+CREATE TABLE IF NOT EXISTS data_extracts (
+    dataset TEXT PRIMARY KEY,
+    date_of_extract DATETIME NOT NULL,
+    imported_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
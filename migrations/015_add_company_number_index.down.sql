@@ -0,0 +1,2 @@
+-- Remove the company_number index
+DROP INDEX IF EXISTS idx_charities_company_number;
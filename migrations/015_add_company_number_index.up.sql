@@ -0,0 +1,2 @@
+-- Index company_number for lookups by Companies House registration number
+CREATE INDEX IF NOT EXISTS idx_charities_company_number ON charities(company_number);
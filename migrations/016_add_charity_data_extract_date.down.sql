@@ -0,0 +1,2 @@
+-- Remove data_extract_date from charities
+ALTER TABLE charities DROP COLUMN data_extract_date;
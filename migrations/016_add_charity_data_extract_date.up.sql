@@ -0,0 +1,3 @@
+-- Add data_extract_date to charities: the date_of_extract of the bulk extract the
+-- record was last imported from. NULL for charities only ever fetched from the API.
+ALTER TABLE charities ADD COLUMN data_extract_date DATETIME;
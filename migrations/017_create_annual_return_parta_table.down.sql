@@ -0,0 +1,2 @@
+-- Drop the annual return Part A table
+DROP TABLE IF EXISTS annual_return_parta;
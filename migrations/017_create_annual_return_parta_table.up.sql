@@ -0,0 +1,13 @@
+-- How each charity raised its income in a financial year, keyed like financials
+-- by registered number and financial year end. Sourced from the
+-- publicextract.charity_annual_return_parta extract; a NULL flag means the
+-- charity didn't answer the question.
+CREATE TABLE IF NOT EXISTS annual_return_parta (
+    charity_number INTEGER NOT NULL,
+    financial_year_end DATETIME NOT NULL,
+    total_gross_income REAL,
+    raises_funds_from_public BOOLEAN,
+    has_trading_subsidiary BOOLEAN,
+    last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (charity_number, financial_year_end)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS scoring_progress;
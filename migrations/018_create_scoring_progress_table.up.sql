@@ -0,0 +1,14 @@
+-- Progress of the current or most recent batch scoring run, written by the seeder
+-- and read by the admin scoring status endpoint. Holds a single row.
+CREATE TABLE IF NOT EXISTS scoring_progress (
+    id INTEGER PRIMARY KEY,
+    label TEXT NOT NULL,
+    total_records INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    successful INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    started_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    finished_at DATETIME
+);
@@ -0,0 +1,12 @@
+-- Why each charity was removed from the register (e.g. "Ceased to exist",
+-- "Amalgamated"), keyed by registered number. Sourced from the "Removed" events
+-- of the publicextract.charity_event_history extract; only main charities
+-- (linked_charity_number = 0) are kept, and the latest removal wins.
+CREATE TABLE IF NOT EXISTS charity_removals (
+    charity_number INTEGER PRIMARY KEY,
+    reason TEXT,
+    date_removed DATETIME,
+    successor_charity_number INTEGER,
+    successor_name TEXT,
+    last_updated DATETIME DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+-- Charities whose background sync gave up, so they can be retried in bulk once
+-- the API recovers. A row is removed when the charity next syncs successfully.
+-- not_found marks charities the API doesn't know, which aren't retried.
+CREATE TABLE IF NOT EXISTS sync_failures (
+    charity_number INTEGER PRIMARY KEY,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    not_found BOOLEAN NOT NULL DEFAULT 0,
+    failed_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_failures_failed_at ON sync_failures(failed_at);
@@ -0,0 +1,4 @@
+-- Remove the data source columns from charities
+ALTER TABLE charities DROP COLUMN trustees_source;
+ALTER TABLE charities DROP COLUMN financials_source;
+ALTER TABLE charities DROP COLUMN details_source;
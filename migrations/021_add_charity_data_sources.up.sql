@@ -0,0 +1,6 @@
+-- Record which source last populated each section of a charity: register_extract
+-- (the bulk charity and trustee extracts), annual_return (annual return Part B),
+-- api or financial_history (the live API). NULL when not yet known.
+ALTER TABLE charities ADD COLUMN details_source TEXT;
+ALTER TABLE charities ADD COLUMN financials_source TEXT;
+ALTER TABLE charities ADD COLUMN trustees_source TEXT;
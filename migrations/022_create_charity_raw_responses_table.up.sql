@@ -0,0 +1,8 @@
+-- The raw allcharitydetailsV2 response from the last live API fetch of each
+-- charity, as JSON, with fields that mustn't be redistributed removed. Served
+-- as-is by /api/charities/{number}/raw.
+CREATE TABLE IF NOT EXISTS charity_raw_responses (
+    charity_number INTEGER PRIMARY KEY,
+    response TEXT NOT NULL,
+    fetched_at DATETIME NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Remove job_id from scoring_progress
+ALTER TABLE scoring_progress DROP COLUMN job_id;
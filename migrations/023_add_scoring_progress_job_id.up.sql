@@ -0,0 +1,3 @@
+-- Identify scoring runs started as jobs, e.g. by the admin rescore endpoint, so
+-- their progress can be looked up by job. NULL for seeder runs.
+ALTER TABLE scoring_progress ADD COLUMN job_id TEXT;
@@ -0,0 +1,12 @@
+-- Problems found in the register extracts during import, kept for review rather
+-- than silently imported. anomaly_type says what was wrong (e.g.
+-- duplicate_charity_number) and details describes the records involved.
+CREATE TABLE IF NOT EXISTS data_anomalies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    anomaly_type TEXT NOT NULL,
+    charity_number INTEGER NOT NULL,
+    details TEXT,
+    detected_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_anomalies_charity ON data_anomalies(charity_number);
@@ -0,0 +1,3 @@
+-- Remove the normalised trustee name
+DROP INDEX IF EXISTS idx_trustees_name_key;
+ALTER TABLE trustees DROP COLUMN name_key;
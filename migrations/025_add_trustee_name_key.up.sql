@@ -0,0 +1,19 @@
+-- Identify trustees by charity and normalised name: lowercased, with whitespace
+-- runs collapsed to a single space and trimmed. Re-imports then match the
+-- existing row when a name only differs in case or spacing, rather than adding
+-- another. The nested REPLACEs collapse runs of up to eight spaces, matching
+-- database.TrusteeNameKey for all but pathological names.
+ALTER TABLE trustees ADD COLUMN name_key TEXT;
+
+UPDATE trustees SET name_key = LOWER(TRIM(
+    REPLACE(REPLACE(REPLACE(
+        REPLACE(REPLACE(REPLACE(name, char(9), ' '), char(10), ' '), char(13), ' '),
+    '  ', ' '), '  ', ' '), '  ', ' ')
+));
+
+-- Keep one row per trustee where earlier imports stored several spellings
+DELETE FROM trustees WHERE rowid NOT IN (
+    SELECT MIN(rowid) FROM trustees GROUP BY charity_number, name_key
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trustees_name_key ON trustees(charity_number, name_key);
@@ -0,0 +1,2 @@
+-- Remove the folded charity name
+ALTER TABLE charities DROP COLUMN name_folded;
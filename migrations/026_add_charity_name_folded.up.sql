@@ -0,0 +1,94 @@
+-- Store each charity's name folded for search: accented Latin letters replaced
+-- with ASCII and then lowercased, so "societe" finds "Société" and the other way
+-- round. The display name is unchanged. These replacements mirror
+-- database.FoldName, which folds names written from now on and search queries.
+ALTER TABLE charities ADD COLUMN name_folded TEXT;
+
+UPDATE charities SET name_folded = name;
+
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'à', 'a'), 'á', 'a'), 'â', 'a'), 'ã', 'a'), 'ä', 'a'), 'å', 'a'), 'ā', 'a'), 'ă', 'a'), 'ą', 'a')
+    WHERE name_folded GLOB '*[àáâãäåāăą]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'À', 'A'), 'Á', 'A'), 'Â', 'A'), 'Ã', 'A'), 'Ä', 'A'), 'Å', 'A'), 'Ā', 'A'), 'Ă', 'A'), 'Ą', 'A')
+    WHERE name_folded GLOB '*[ÀÁÂÃÄÅĀĂĄ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'ç', 'c'), 'ć', 'c'), 'ĉ', 'c'), 'ċ', 'c'), 'č', 'c')
+    WHERE name_folded GLOB '*[çćĉċč]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'Ç', 'C'), 'Ć', 'C'), 'Ĉ', 'C'), 'Ċ', 'C'), 'Č', 'C')
+    WHERE name_folded GLOB '*[ÇĆĈĊČ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'ď', 'd'), 'đ', 'd'), 'ð', 'd')
+    WHERE name_folded GLOB '*[ďđð]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'Ď', 'D'), 'Đ', 'D'), 'Ð', 'D')
+    WHERE name_folded GLOB '*[ĎĐÐ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'è', 'e'), 'é', 'e'), 'ê', 'e'), 'ë', 'e'), 'ē', 'e'), 'ĕ', 'e'), 'ė', 'e'), 'ę', 'e'), 'ě', 'e')
+    WHERE name_folded GLOB '*[èéêëēĕėęě]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'È', 'E'), 'É', 'E'), 'Ê', 'E'), 'Ë', 'E'), 'Ē', 'E'), 'Ĕ', 'E'), 'Ė', 'E'), 'Ę', 'E'), 'Ě', 'E')
+    WHERE name_folded GLOB '*[ÈÉÊËĒĔĖĘĚ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'ĝ', 'g'), 'ğ', 'g'), 'ġ', 'g'), 'ģ', 'g')
+    WHERE name_folded GLOB '*[ĝğġģ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'Ĝ', 'G'), 'Ğ', 'G'), 'Ġ', 'G'), 'Ģ', 'G')
+    WHERE name_folded GLOB '*[ĜĞĠĢ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(name_folded, 'ĥ', 'h'), 'ħ', 'h')
+    WHERE name_folded GLOB '*[ĥħ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(name_folded, 'Ĥ', 'H'), 'Ħ', 'H')
+    WHERE name_folded GLOB '*[ĤĦ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'ì', 'i'), 'í', 'i'), 'î', 'i'), 'ï', 'i'), 'ĩ', 'i'), 'ī', 'i'), 'ĭ', 'i'), 'į', 'i'), 'ı', 'i')
+    WHERE name_folded GLOB '*[ìíîïĩīĭįı]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'Ì', 'I'), 'Í', 'I'), 'Î', 'I'), 'Ï', 'I'), 'Ĩ', 'I'), 'Ī', 'I'), 'Ĭ', 'I'), 'Į', 'I'), 'İ', 'I')
+    WHERE name_folded GLOB '*[ÌÍÎÏĨĪĬĮİ]*';
+UPDATE charities SET name_folded = REPLACE(name_folded, 'ĵ', 'j')
+    WHERE name_folded GLOB '*[ĵ]*';
+UPDATE charities SET name_folded = REPLACE(name_folded, 'Ĵ', 'J')
+    WHERE name_folded GLOB '*[Ĵ]*';
+UPDATE charities SET name_folded = REPLACE(name_folded, 'ķ', 'k')
+    WHERE name_folded GLOB '*[ķ]*';
+UPDATE charities SET name_folded = REPLACE(name_folded, 'Ķ', 'K')
+    WHERE name_folded GLOB '*[Ķ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'ĺ', 'l'), 'ļ', 'l'), 'ľ', 'l'), 'ŀ', 'l'), 'ł', 'l')
+    WHERE name_folded GLOB '*[ĺļľŀł]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'Ĺ', 'L'), 'Ļ', 'L'), 'Ľ', 'L'), 'Ŀ', 'L'), 'Ł', 'L')
+    WHERE name_folded GLOB '*[ĹĻĽĿŁ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'ñ', 'n'), 'ń', 'n'), 'ņ', 'n'), 'ň', 'n')
+    WHERE name_folded GLOB '*[ñńņň]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'Ñ', 'N'), 'Ń', 'N'), 'Ņ', 'N'), 'Ň', 'N')
+    WHERE name_folded GLOB '*[ÑŃŅŇ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'ò', 'o'), 'ó', 'o'), 'ô', 'o'), 'õ', 'o'), 'ö', 'o'), 'ø', 'o'), 'ō', 'o'), 'ŏ', 'o'), 'ő', 'o')
+    WHERE name_folded GLOB '*[òóôõöøōŏő]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'Ò', 'O'), 'Ó', 'O'), 'Ô', 'O'), 'Õ', 'O'), 'Ö', 'O'), 'Ø', 'O'), 'Ō', 'O'), 'Ŏ', 'O'), 'Ő', 'O')
+    WHERE name_folded GLOB '*[ÒÓÔÕÖØŌŎŐ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'ŕ', 'r'), 'ŗ', 'r'), 'ř', 'r')
+    WHERE name_folded GLOB '*[ŕŗř]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'Ŕ', 'R'), 'Ŗ', 'R'), 'Ř', 'R')
+    WHERE name_folded GLOB '*[ŔŖŘ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'ś', 's'), 'ŝ', 's'), 'ş', 's'), 'š', 's')
+    WHERE name_folded GLOB '*[śŝşš]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'Ś', 'S'), 'Ŝ', 'S'), 'Ş', 'S'), 'Š', 'S')
+    WHERE name_folded GLOB '*[ŚŜŞŠ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'ţ', 't'), 'ť', 't'), 'ŧ', 't')
+    WHERE name_folded GLOB '*[ţťŧ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'Ţ', 'T'), 'Ť', 'T'), 'Ŧ', 'T')
+    WHERE name_folded GLOB '*[ŢŤŦ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'ù', 'u'), 'ú', 'u'), 'û', 'u'), 'ü', 'u'), 'ũ', 'u'), 'ū', 'u'), 'ŭ', 'u'), 'ů', 'u'), 'ű', 'u'), 'ų', 'u')
+    WHERE name_folded GLOB '*[ùúûüũūŭůűų]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(name_folded, 'Ù', 'U'), 'Ú', 'U'), 'Û', 'U'), 'Ü', 'U'), 'Ũ', 'U'), 'Ū', 'U'), 'Ŭ', 'U'), 'Ů', 'U'), 'Ű', 'U'), 'Ų', 'U')
+    WHERE name_folded GLOB '*[ÙÚÛÜŨŪŬŮŰŲ]*';
+UPDATE charities SET name_folded = REPLACE(name_folded, 'ŵ', 'w')
+    WHERE name_folded GLOB '*[ŵ]*';
+UPDATE charities SET name_folded = REPLACE(name_folded, 'Ŵ', 'W')
+    WHERE name_folded GLOB '*[Ŵ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'ý', 'y'), 'ÿ', 'y'), 'ŷ', 'y')
+    WHERE name_folded GLOB '*[ýÿŷ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'Ý', 'Y'), 'Ÿ', 'Y'), 'Ŷ', 'Y')
+    WHERE name_folded GLOB '*[ÝŸŶ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'ź', 'z'), 'ż', 'z'), 'ž', 'z')
+    WHERE name_folded GLOB '*[źżž]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(REPLACE(name_folded, 'Ź', 'Z'), 'Ż', 'Z'), 'Ž', 'Z')
+    WHERE name_folded GLOB '*[ŹŻŽ]*';
+UPDATE charities SET name_folded = REPLACE(name_folded, 'ß', 'ss')
+    WHERE name_folded GLOB '*[ß]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(name_folded, 'æ', 'ae'), 'Æ', 'ae')
+    WHERE name_folded GLOB '*[æÆ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(name_folded, 'œ', 'oe'), 'Œ', 'oe')
+    WHERE name_folded GLOB '*[œŒ]*';
+UPDATE charities SET name_folded = REPLACE(REPLACE(name_folded, 'þ', 'th'), 'Þ', 'th')
+    WHERE name_folded GLOB '*[þÞ]*';
+
+UPDATE charities SET name_folded = LOWER(name_folded);
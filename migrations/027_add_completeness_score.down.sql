@@ -0,0 +1,2 @@
+-- Remove the data completeness score
+ALTER TABLE charity_scores DROP COLUMN completeness_score;
@@ -0,0 +1,4 @@
+-- Store how complete the data behind each score is. Existing scores default to 0
+-- until they're recalculated, which the scoring version bump that came with this
+-- column triggers.
+ALTER TABLE charity_scores ADD COLUMN completeness_score REAL NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+-- Requests made to the Charity Commission API in the current hour and day, so
+-- the server's API budget survives restarts. period is 'hour' or 'day', and
+-- period_start the Unix time its window began; calls from an earlier window are
+-- discarded on load.
+CREATE TABLE IF NOT EXISTS api_budget (
+    period TEXT PRIMARY KEY,
+    period_start INTEGER NOT NULL,
+    calls INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,3 @@
+-- Remove the recent feed indexes
+DROP INDEX IF EXISTS idx_charities_last_updated;
+DROP INDEX IF EXISTS idx_charities_date_registered;
@@ -0,0 +1,3 @@
+-- Index the dates the recently added and recently updated feeds are ordered by
+CREATE INDEX IF NOT EXISTS idx_charities_date_registered ON charities(date_registered);
+CREATE INDEX IF NOT EXISTS idx_charities_last_updated ON charities(last_updated);
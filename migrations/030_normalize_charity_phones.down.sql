@@ -0,0 +1,3 @@
+-- The original formatting of normalised phone numbers isn't kept, so there's
+-- nothing to restore
+SELECT 1;
@@ -0,0 +1,17 @@
+-- Normalise stored contact phone numbers as validation.NormalizePhone does for
+-- new imports and syncs: drop an international number's "(0)" and the
+-- separators between digits, then clear anything that isn't 7 to 15 digits,
+-- optionally starting with +
+UPDATE charities SET phone = REPLACE(phone, '(0)', '')
+WHERE phone LIKE '+%';
+
+UPDATE charities SET phone =
+    REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(TRIM(phone),
+        ' ', ''), CHAR(9), ''), CHAR(160), ''), '-', ''), '.', ''), '(', ''), ')', '')
+WHERE phone IS NOT NULL;
+
+UPDATE charities SET phone = NULL
+WHERE phone IS NOT NULL
+  AND (REPLACE(SUBSTR(phone, 1, 1), '+', '') || SUBSTR(phone, 2) GLOB '*[^0-9]*'
+       OR LENGTH(LTRIM(phone, '+')) NOT BETWEEN 7 AND 15
+       OR LENGTH(phone) - LENGTH(LTRIM(phone, '+')) > 1);
@@ -1,9 +1,13 @@
 package static
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"io/fs"
+	"net/http"
 	"os"
+	"strings"
 )
 
 //go:embed css/*.css js/*.js
@@ -11,8 +15,12 @@ var staticFS embed.FS
 
 var isDevelopment bool
 
+// hashes maps each embedded file path to a short hash of its contents
+var hashes map[string]string
+
 func init() {
 	isDevelopment = os.Getenv("GO_ENV") == "development"
+	hashes = hashFiles(staticFS)
 }
 
 // FS returns the static filesystem - either embedded or on-disk based on GO_ENV
@@ -26,3 +34,59 @@ func FS() fs.FS {
 	// In production, serve from embedded filesystem
 	return staticFS
 }
+
+// URL returns the public URL for a static file. In production the URL carries a
+// hash of the file's contents so it can be cached forever and still change when
+// the file does.
+func URL(name string) string {
+	if hash, ok := hashes[name]; ok && !isDevelopment {
+		return "/static/" + name + "?v=" + hash
+	}
+	return "/static/" + name
+}
+
+// Handler serves the static files (mount behind http.StripPrefix("/static/", ...)).
+// Requests carrying the current content hash are cached as immutable, other
+// embedded files get a content-hash ETag and a short max-age so returning
+// visitors revalidate cheaply. Nothing is cached in development mode.
+func Handler() http.Handler {
+	fileServer := http.FileServer(http.FS(FS()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isDevelopment {
+			// Always revalidate so edits show up on reload
+			w.Header().Set("Cache-Control", "no-cache")
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+
+		if hash, ok := hashes[strings.TrimPrefix(r.URL.Path, "/")]; ok {
+			// http.FileServer answers If-None-Match from the ETag header
+			w.Header().Set("ETag", `"`+hash+`"`)
+			if r.URL.Query().Get("v") == hash {
+				w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			} else {
+				w.Header().Set("Cache-Control", "public, max-age=3600")
+			}
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// hashFiles returns a short content hash for every file in fsys
+func hashFiles(fsys fs.FS) map[string]string {
+	result := make(map[string]string)
+	fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		result[path] = hex.EncodeToString(sum[:])[:12]
+		return nil
+	})
+	return result
+}
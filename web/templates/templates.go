@@ -6,6 +6,8 @@ import (
 	"io/fs"
 	"strconv"
 	"strings"
+
+	"charitylens/web/static"
 )
 
 //go:embed *.html
@@ -64,9 +66,9 @@ func ensureAbsoluteURL(url string) string {
 	return "https://" + url
 }
 
-// titleCase converts a name to proper title case
+// TitleCase converts a name to proper title case
 // Handles all caps names and preserves certain uppercase elements like initials
-func titleCase(s string) string {
+func TitleCase(s string) string {
 	if s == "" {
 		return s
 	}
@@ -156,8 +158,9 @@ func init() {
 	// Create function map with custom functions
 	funcMap := template.FuncMap{
 		"formatCurrency":    formatCurrency,
-		"titleCase":         titleCase,
+		"titleCase":         TitleCase,
 		"ensureAbsoluteURL": ensureAbsoluteURL,
+		"asset":             static.URL,
 	}
 
 	// Parse templates with custom functions